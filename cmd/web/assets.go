@@ -0,0 +1,93 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// assetManifest maps static files to fingerprinted names containing a hash of
+// their content. The fingerprinted URLs change whenever the content does, so
+// they can be cached by browsers indefinitely.
+type assetManifest struct {
+	// fingerprinted maps a path relative to the static directory, such as
+	// css/site.css, to its fingerprinted path, such as css/site.0a1b2c3d4e.css.
+	fingerprinted map[string]string
+	// original is the reverse of fingerprinted.
+	original map[string]string
+}
+
+func newAssetManifest(files fs.FS) (*assetManifest, error) {
+	m := &assetManifest{
+		fingerprinted: map[string]string{},
+		original:      map[string]string{},
+	}
+
+	err := fs.WalkDir(files, "static", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		content, err := fs.ReadFile(files, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(content)
+
+		rel := strings.TrimPrefix(name, "static/")
+		ext := path.Ext(rel)
+		hashed := strings.TrimSuffix(rel, ext) + "." + hex.EncodeToString(sum[:5]) + ext
+
+		m.fingerprinted[rel] = hashed
+		m.original[hashed] = rel
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// URL returns the fingerprinted URL of the static file. Unknown files are
+// returned with their regular URL.
+func (m *assetManifest) URL(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if hashed, ok := m.fingerprinted[name]; ok {
+		return "/static/" + hashed
+	}
+
+	return "/static/" + name
+}
+
+// staticHandler serves the static files. Requests for fingerprinted names are
+// served as immutable, while the regular names are served with a short cache
+// lifetime, as their content may change between deployments.
+func (app *application) staticHandler() http.Handler {
+	fileServer := http.FileServer(http.FS(app.files))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("filepath")
+
+		app.templateMu.RLock()
+		original, ok := app.assets.original[name]
+		app.templateMu.RUnlock()
+
+		if !ok {
+			w.Header().Set("Cache-Control", "public, max-age=300")
+			fileServer.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+
+		r2 := r.Clone(r.Context())
+		r2.URL.Path = "/static/" + original
+		r2.URL.RawPath = ""
+		fileServer.ServeHTTP(w, r2)
+	})
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"textonly.islandwind.me/internal/assert"
+)
+
+func TestAssetManifest(t *testing.T) {
+	files := fstest.MapFS{
+		"static/css/site.css": {Data: []byte("body { color: red; }")},
+		"static/favicon.ico":  {Data: []byte("icon")},
+	}
+
+	assets, err := newAssetManifest(files)
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+
+	app := &application{
+		logger: slog.New(slog.NewJSONHandler(io.Discard, nil)),
+		files:  files,
+		assets: assets,
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /static/{filepath...}", app.staticHandler())
+
+	tests := []struct {
+		name         string
+		url          string
+		expectedCode int
+		expectedBody string
+		immutable    bool
+	}{
+		{
+			name:         "Fingerprinted",
+			url:          assets.URL("css/site.css"),
+			expectedCode: http.StatusOK,
+			expectedBody: "body { color: red; }",
+			immutable:    true,
+		},
+		{
+			name:         "Regular",
+			url:          "/static/css/site.css",
+			expectedCode: http.StatusOK,
+			expectedBody: "body { color: red; }",
+		},
+		{
+			name:         "Unknown",
+			url:          assets.URL("js/missing.js"),
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, rr.Code, tt.expectedCode)
+			if tt.expectedBody != "" {
+				assert.Equal(t, rr.Body.String(), tt.expectedBody)
+			}
+			cacheControl := rr.Header().Get("Cache-Control")
+			assert.Equal(t, cacheControl == "public, max-age=31536000, immutable", tt.immutable)
+		})
+	}
+
+	assert.Equal(t, assets.URL("js/missing.js"), "/static/js/missing.js")
+	if assets.URL("css/site.css") == "/static/css/site.css" {
+		t.Errorf("Expected fingerprinted URL but got '%s'", assets.URL("css/site.css"))
+	}
+}
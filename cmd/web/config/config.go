@@ -2,13 +2,27 @@ package config
 
 import (
 	_ "embed"
+	"time"
 
 	"github.com/spf13/viper"
+	"textonly.islandwind.me/internal/data"
+	"textonly.islandwind.me/internal/markdown"
+)
+
+const (
+	HomeModePage     = "page"
+	HomeModeRedirect = "redirect"
 )
 
 type Config struct {
-	Database *DatabaseConfig `json:"database"`
-	Server   *ServerConfig   `json:"server"`
+	Database  *DatabaseConfig  `json:"database"`
+	Server    *ServerConfig    `json:"server"`
+	Home      *HomeConfig      `json:"home"`
+	Posts     *PostsConfig     `json:"posts"`
+	Feed      *FeedConfig      `json:"feed"`
+	Theme     *ThemeConfig     `json:"theme"`
+	Sanitizer *SanitizerConfig `json:"sanitizer"`
+	Markdown  *MarkdownConfig  `json:"markdown"`
 }
 
 type DatabaseConfig struct {
@@ -21,7 +35,86 @@ type ServerConfig struct {
 	ENV      string `json:"env"`
 	User     string `json:"user"`
 	Password string `json:"-"`
-	Realm    string `json:"realm"`
+	// Users are the accounts of the other members of the team, by name and
+	// password, with the same access as User. Reviews record who took them,
+	// so approving a post needs an account other than the one that asked for
+	// the review. Names are lower case.
+	Users map[string]string `json:"-"`
+	Realm string            `json:"realm"`
+	// CSPReports enables the /csp-report endpoint, which logs the content
+	// security policy violations reported by browsers.
+	CSPReports bool `json:"csp_reports" mapstructure:"csp_reports"`
+}
+
+// HomeConfig controls the layout of the landing page. Mode is either "page",
+// which renders the home template, or "redirect", which sends the reader to
+// the newest post. At most FeaturedPosts featured posts are shown, and
+// RecentPosts other recent posts.
+type HomeConfig struct {
+	Mode          string `json:"mode"`
+	RecentPosts   int    `json:"recent_posts" mapstructure:"recent_posts"`
+	FeaturedPosts int    `json:"featured_posts" mapstructure:"featured_posts"`
+	ShowFeatured  bool   `json:"show_featured" mapstructure:"show_featured"`
+	ShowIntro     bool   `json:"show_intro" mapstructure:"show_intro"`
+}
+
+// PostsConfig controls what is shown below a post. RelatedPosts is the number
+// of related posts listed; 0 turns them off. Meta describes the custom fields
+// of posts that are validated, and can be made filterable. Share controls the
+// share links of private posts, and Review the editorial review of posts.
+type PostsConfig struct {
+	RelatedPosts int             `json:"related_posts" mapstructure:"related_posts"`
+	Meta         data.MetaSchema `json:"meta"`
+	Share        ShareConfig     `json:"share"`
+	Review       ReviewConfig    `json:"review"`
+}
+
+// ShareConfig controls the share links of private posts. Secret signs the
+// links, which stop working when it changes; a random secret is used until
+// the application restarts when it is empty. Links expire after DefaultExpiry
+// unless another time is asked for, which can be at most MaxExpiry.
+type ShareConfig struct {
+	Secret        string        `json:"-"`
+	DefaultExpiry time.Duration `json:"default_expiry" mapstructure:"default_expiry"`
+	MaxExpiry     time.Duration `json:"max_expiry" mapstructure:"max_expiry"`
+}
+
+// FeedConfig controls the RSS feeds. Items is the number of newest posts a
+// feed has.
+type FeedConfig struct {
+	Items int `json:"items"`
+}
+
+// ReviewConfig controls the editorial review of posts. With RequireApproval,
+// posts are created as drafts and can only be published once a reviewer has
+// approved them, and go back to draft when their content is changed.
+type ReviewConfig struct {
+	RequireApproval bool `json:"require_approval" mapstructure:"require_approval"`
+}
+
+// ThemeConfig points to an optional theme directory. Files in its html, xml
+// and static directories override the embedded files of the same name.
+type ThemeConfig struct {
+	Path string `json:"path"`
+}
+
+// SanitizerConfig maps content types (post, page, profile and untrusted) to
+// the sanitizer policy (author, ugc or strict) applied to their rendered HTML.
+type SanitizerConfig struct {
+	Policies map[string]string `json:"policies"`
+}
+
+// MarkdownConfig defines the named markdown renderers. Default is the name of
+// the renderer given to new posts; the built-in "legacy" renderer is used when
+// it is empty. A renderer should not be changed once posts use it; add a new
+// one instead. HighlightStyle selects the stylesheet in static/css/highlight
+// used for highlighted code blocks. Posts get a table of contents when they
+// have at least TOCMinHeadings headings; 0 turns it off.
+type MarkdownConfig struct {
+	Default        string                      `json:"default"`
+	Renderers      map[string]markdown.Options `json:"renderers"`
+	HighlightStyle string                      `json:"highlight_style" mapstructure:"highlight_style"`
+	TOCMinHeadings int                         `json:"toc_min_headings" mapstructure:"toc_min_headings"`
 }
 
 func New() (*Config, error) {
@@ -33,6 +126,25 @@ func New() (*Config, error) {
 	viper.AddConfigPath("$HOME/.config/textonly/")
 	viper.AddConfigPath(".")
 
+	viper.SetDefault("server.users", map[string]string{})
+	viper.SetDefault("home.mode", HomeModePage)
+	viper.SetDefault("home.recent_posts", 5)
+	viper.SetDefault("home.featured_posts", 5)
+	viper.SetDefault("home.show_featured", true)
+	viper.SetDefault("home.show_intro", true)
+	viper.SetDefault("posts.related_posts", 3)
+	viper.SetDefault("posts.meta", map[string]any{})
+	viper.SetDefault("posts.share.secret", "")
+	viper.SetDefault("posts.share.default_expiry", "48h")
+	viper.SetDefault("posts.share.max_expiry", "720h")
+	viper.SetDefault("posts.review.require_approval", false)
+	viper.SetDefault("feed.items", 20)
+	viper.SetDefault("theme.path", "")
+	viper.SetDefault("sanitizer.policies", map[string]string{})
+	viper.SetDefault("markdown.default", markdown.Legacy)
+	viper.SetDefault("markdown.highlight_style", "github-dark")
+	viper.SetDefault("markdown.toc_min_headings", 3)
+
 	err := viper.ReadInConfig()
 	if err != nil {
 		return nil, err
@@ -65,6 +177,21 @@ func New() (*Config, error) {
 		return nil, err
 	}
 
+	err = viper.BindEnv("home.mode", "TEXTONLY_HOME_MODE")
+	if err != nil {
+		return nil, err
+	}
+
+	err = viper.BindEnv("theme.path", "TEXTONLY_THEME_PATH")
+	if err != nil {
+		return nil, err
+	}
+
+	err = viper.BindEnv("posts.share.secret", "TEXTONLY_SHARE_SECRET")
+	if err != nil {
+		return nil, err
+	}
+
 	var config Config
 	err = viper.Unmarshal(&config)
 	if err != nil {
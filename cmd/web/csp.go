@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"html/template"
+	"io"
+	"net/http"
+	"strings"
+
+	"textonly.islandwind.me/internal/utils"
+)
+
+const nonceKey utils.ContextKey = "nonce"
+
+// nonceSource is replaced by a fresh nonce for every request.
+const nonceSource = "'nonce-{nonce}'"
+
+// Content security policies for the different parts of the application. The
+// blog only allows scripts and styles from its own origin, or inline ones
+// carrying the nonce of the request.
+var (
+	blogCSP = []string{
+		"default-src 'self'",
+		"script-src 'self' " + nonceSource,
+		"style-src 'self' " + nonceSource,
+		"img-src * data:",
+		"object-src 'none'",
+		"base-uri 'self'",
+		"form-action 'self'",
+		"frame-ancestors 'none'",
+	}
+	// The Swagger UI initialises itself with inline scripts and styles.
+	swaggerCSP = []string{
+		"default-src 'self'",
+		"script-src 'self' 'unsafe-inline' 'unsafe-eval'",
+		"style-src 'self' 'unsafe-inline'",
+		"img-src 'self' data:",
+		"object-src 'none'",
+		"frame-ancestors 'none'",
+	}
+	apiCSP = []string{
+		"default-src 'none'",
+		"frame-ancestors 'none'",
+	}
+)
+
+// WithNonce embeds a CSP nonce in the given context.
+func WithNonce(ctx context.Context, nonce string) context.Context {
+	return context.WithValue(ctx, nonceKey, nonce)
+}
+
+// NonceFromContext returns the CSP nonce embedded in the context, or an empty
+// string if there is none.
+func NonceFromContext(ctx context.Context) string {
+	nonce, _ := ctx.Value(nonceKey).(string)
+	return nonce
+}
+
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// csp sets the Content-Security-Policy header from the given directives. If
+// the policy uses a nonce, a new one is generated and embedded in the request
+// context, where it is picked up when rendering templates.
+func (app *application) csp(directives []string) func(http.Handler) http.Handler {
+	policy := strings.Join(directives, "; ")
+	if app.config.Server.CSPReports {
+		policy += "; report-uri /csp-report"
+	}
+	usesNonce := strings.Contains(policy, nonceSource)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !usesNonce {
+				w.Header().Set("Content-Security-Policy", policy)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			nonce, err := newNonce()
+			if err != nil {
+				app.serverError(w, err)
+				return
+			}
+
+			w.Header().Set(
+				"Content-Security-Policy",
+				strings.ReplaceAll(policy, nonceSource, "'nonce-"+nonce+"'"),
+			)
+			next.ServeHTTP(w, r.WithContext(WithNonce(r.Context(), nonce)))
+		})
+	}
+}
+
+// nonceAttr returns the nonce attribute for inline <script> and <style>
+// tags, used as <script {{ nonce . }}>.
+func nonceAttr(data *templateData) template.HTMLAttr {
+	if data == nil || data.Nonce == "" {
+		return ""
+	}
+
+	return template.HTMLAttr(`nonce="` + template.HTMLEscapeString(data.Nonce) + `"`)
+}
+
+// cspReportHandler logs the CSP violation reports sent by browsers.
+func (app *application) cspReportHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to read CSP report", "error", err)
+		app.badRequestResponse(w, r, "unable to read CSP report")
+		return
+	}
+
+	var report any
+	if err := json.Unmarshal(body, &report); err != nil {
+		logger.InfoContext(ctx, "invalid CSP report", "error", err)
+		app.badRequestResponse(w, r, "unable to parse CSP report")
+		return
+	}
+
+	logger.WarnContext(ctx, "content security policy violation", "report", report)
+	w.WriteHeader(http.StatusNoContent)
+}
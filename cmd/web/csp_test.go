@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"textonly.islandwind.me/cmd/web/config"
+	"textonly.islandwind.me/internal/assert"
+)
+
+func TestCSP(t *testing.T) {
+	app := &application{config: &config.Config{Server: &config.ServerConfig{}}}
+
+	var nonce string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce = NonceFromContext(r.Context())
+	})
+
+	rr := httptest.NewRecorder()
+	app.csp(blogCSP)(next).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	policy := rr.Header().Get("Content-Security-Policy")
+	if nonce == "" {
+		t.Fatal("Expected nonce in request context")
+	}
+	assert.Equal(t, strings.Contains(policy, "script-src 'self' 'nonce-"+nonce+"'"), true)
+	assert.Equal(t, strings.Contains(policy, "unsafe-inline"), false)
+	assert.Equal(t, strings.Contains(policy, "unsafe-eval"), false)
+	assert.Equal(t, strings.Contains(policy, "report-uri"), false)
+
+	first := nonce
+	rr = httptest.NewRecorder()
+	app.csp(blogCSP)(next).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	if nonce == first {
+		t.Error("Expected a new nonce for every request")
+	}
+
+	app.config.Server.CSPReports = true
+	rr = httptest.NewRecorder()
+	app.csp(apiCSP)(next).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/post", nil))
+	assert.Equal(
+		t,
+		rr.Header().Get("Content-Security-Policy"),
+		"default-src 'none'; frame-ancestors 'none'; report-uri /csp-report",
+	)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"textonly.islandwind.me/internal/data"
+	"textonly.islandwind.me/internal/rss"
+	"textonly.islandwind.me/internal/sanitize"
+	"textonly.islandwind.me/internal/utils"
+)
+
+// feedGenerator names the application in the generator element of feeds.
+const feedGenerator = "Textonly"
+
+// feedItem returns the item of the post, with its full HTML. Its permalink is
+// the GUID, which stays the same when the post is edited. Link posts link to
+// the page they share, and to the post for comments.
+func (app *application) feedItem(settings *data.SiteSettings, bp *data.BlogPost) rss.Item {
+	permalink := settings.URL(fmt.Sprintf("/post/read/%d", bp.ID))
+
+	item := rss.Item{
+		Link:       permalink,
+		Creator:    settings.DefaultAuthor,
+		Categories: []string{bp.Kind},
+		GUID:       rss.GUID{Value: permalink, IsPermaLink: true},
+		Stats: &rss.Stats{
+			WordCount:     bp.WordCount,
+			ReadingTime:   bp.ReadingTime,
+			CodeBlocks:    bp.CodeBlocks,
+			OutboundLinks: bp.OutboundLinks,
+			Images:        bp.Images,
+		},
+	}
+
+	// notes are only given a title when they have one
+	item.Title = bp.Title
+	if bp.Kind != data.KindNote {
+		item.Title = bp.DisplayTitle()
+	}
+
+	item.Description = bp.Lead
+	if item.Description == "" {
+		item.Description = bp.Excerpt
+	}
+	if bp.Kind == data.KindLink {
+		item.Link = bp.LinkURL
+		item.Comments = permalink
+		if bp.Quote != "" {
+			item.Description = fmt.Sprintf("“%s” %s", bp.Quote, bp.Lead)
+		}
+	}
+
+	// posts stored before they were rendered on save are rendered here until
+	// the rerender command has been run
+	html := bp.HTML
+	if html == "" && bp.Post != "" {
+		html = string(app.markdownToHTML(sanitize.ContentPost, bp.Renderer, bp.Post))
+	}
+	if html != "" {
+		item.Content = &rss.CDATA{Text: html}
+	}
+
+	if bp.Created != nil {
+		item.PubDate = rss.Date(*bp.Created)
+	}
+
+	return item
+}
+
+// lastBuildDate returns when the newest of the posts was created or last
+// updated, so the date only changes with the content of the feed.
+func lastBuildDate(posts []*data.BlogPost) time.Time {
+	var last time.Time
+	for _, bp := range posts {
+		for _, t := range []*time.Time{bp.Created, bp.LastUpdate} {
+			if t != nil && t.After(last) {
+				last = *t
+			}
+		}
+	}
+
+	return last
+}
+
+// writeFeed writes the channel with the items of the posts as an RSS 2.0
+// feed. The title, link, description and language of the channel are set by
+// the caller.
+func (app *application) writeFeed(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	channel rss.Channel,
+	posts []*data.BlogPost,
+) {
+	logger := utils.LoggerFromContext(ctx)
+	settings := app.siteSettings(ctx)
+
+	channel.LastBuildDate = rss.Date(lastBuildDate(posts))
+	channel.Generator = feedGenerator
+	channel.Self = rss.Self(settings.URL(r.URL.RequestURI()))
+	for _, bp := range posts {
+		channel.Items = append(channel.Items, app.feedItem(settings, bp))
+	}
+
+	b, err := rss.New(channel).Marshal()
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to write feed", "error", err)
+		app.serverError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", rss.ContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
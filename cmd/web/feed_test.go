@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"textonly.islandwind.me/internal/assert"
+	"textonly.islandwind.me/internal/data"
+)
+
+func TestFeedItem(t *testing.T) {
+	app := newTestApplication(t)
+	settings := &data.SiteSettings{BaseURL: "https://example.com/", DefaultAuthor: "Jane"}
+	created := time.Date(2024, 3, 17, 10, 15, 0, 0, time.UTC)
+
+	article := app.feedItem(settings, &data.BlogPost{
+		ID:      1,
+		Kind:    data.KindArticle,
+		Title:   "Hello",
+		Lead:    "The lead",
+		HTML:    "<p>The text of the post.</p>",
+		Created: &created,
+	})
+	assert.Equal(t, article.Title, "Hello")
+	assert.Equal(t, article.Link, "https://example.com/post/read/1")
+	assert.Equal(t, article.GUID.Value, "https://example.com/post/read/1")
+	assert.Equal(t, article.GUID.IsPermaLink, true)
+	assert.Equal(t, article.Description, "The lead")
+	assert.Equal(t, article.Content.Text, "<p>The text of the post.</p>")
+	assert.Equal(t, article.Creator, "Jane")
+	assert.Equal(t, time.Time(article.PubDate), created)
+
+	// link posts link to the page they share and keep the post as their GUID
+	link := app.feedItem(settings, &data.BlogPost{
+		ID:      2,
+		Kind:    data.KindLink,
+		LinkURL: "https://example.org/",
+		Quote:   "Quoted",
+		Lead:    "A comment",
+	})
+	assert.Equal(t, link.Link, "https://example.org/")
+	assert.Equal(t, link.Comments, "https://example.com/post/read/2")
+	assert.Equal(t, link.GUID.Value, "https://example.com/post/read/2")
+	assert.Equal(t, link.Description, "“Quoted” A comment")
+
+	// notes without a title have none in the feed
+	note := app.feedItem(settings, &data.BlogPost{ID: 3, Kind: data.KindNote, Post: "A short note"})
+	assert.Equal(t, note.Title, "")
+	assert.Equal(t, note.Content != nil, true)
+}
+
+func TestLastBuildDate(t *testing.T) {
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, lastBuildDate(nil).IsZero(), true)
+	assert.Equal(t, lastBuildDate([]*data.BlogPost{
+		{Created: &older, LastUpdate: &newer},
+		{Created: &older},
+	}), newer)
+}
@@ -2,10 +2,16 @@ package main
 
 import (
 	"errors"
+	"html/template"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 
+	"textonly.islandwind.me/cmd/web/config"
 	"textonly.islandwind.me/internal/data"
+	"textonly.islandwind.me/internal/rss"
+	"textonly.islandwind.me/internal/sanitize"
 	"textonly.islandwind.me/internal/utils"
 	"textonly.islandwind.me/internal/validator"
 )
@@ -14,8 +20,80 @@ func (app *application) home(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	logger := utils.LoggerFromContext(ctx)
 
-	logger.InfoContext(r.Context(), "redirecting to latest post", "request", r.URL)
-	app.redirectToLatestPost(w, r)
+	if app.config.Home.Mode == config.HomeModeRedirect {
+		logger.InfoContext(ctx, "redirecting to latest post", "request", r.URL)
+		app.redirectToLatestPost(w, r)
+		return
+	}
+
+	app.homePage(w, r)
+}
+
+func (app *application) homePage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	featured := []*data.BlogPost{}
+	if app.config.Home.ShowFeatured {
+		isFeatured := true
+		filters := data.Filters{
+			Page:       1,
+			PageSize:   app.config.Home.FeaturedPosts,
+			Featured:   &isFeatured,
+			Visibility: data.VisibilityPublic,
+			Status:     data.StatusPublished,
+			OrderBy:    []string{"-created"},
+		}
+
+		logger.InfoContext(ctx, "querying featured blogposts")
+		var err error
+		featured, _, err = app.models.BlogPosts.GetAll(ctx, filters)
+		if err != nil {
+			logger.ErrorContext(ctx, "unable to query featured blogposts", "error", err)
+			app.serverError(w, err)
+			return
+		}
+		logger.InfoContext(ctx, "retrieved featured blogposts", "number", len(featured))
+	}
+
+	// the featured posts among the newest are left out of the recent ones, so
+	// as many more are queried
+	limit := app.config.Home.RecentPosts + len(featured)
+	logger.InfoContext(ctx, "querying recent blogposts", "limit", limit)
+	latest, err := app.models.BlogPosts.LastN(ctx, limit)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query recent blogposts", "error", err)
+		app.serverError(w, err)
+		return
+	}
+
+	recent := []*data.BlogPost{}
+	for _, bp := range latest {
+		if len(recent) == app.config.Home.RecentPosts {
+			break
+		}
+		if !slices.ContainsFunc(featured, func(f *data.BlogPost) bool { return f.ID == bp.ID }) {
+			recent = append(recent, bp)
+		}
+	}
+	logger.InfoContext(ctx, "retrieved recent blogposts", "number", len(recent))
+
+	var user *data.User
+	if app.config.Home.ShowIntro {
+		logger.InfoContext(ctx, "querying user data", "id", 1)
+		user, err = app.models.Users.Get(ctx, 1)
+		if err != nil && !errors.Is(err, data.ErrNoRecord) {
+			logger.ErrorContext(ctx, "unable to query user data", "error", err)
+			app.serverError(w, err)
+			return
+		}
+	}
+
+	app.render(ctx, w, http.StatusOK, "home.tmpl", &templateData{
+		FeaturedPosts: featured,
+		BlogPosts:     recent,
+		User:          user,
+	})
 }
 
 func (app *application) readPost(w http.ResponseWriter, r *http.Request) {
@@ -57,8 +135,67 @@ func (app *application) readPost(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.InfoContext(ctx, "retrieved post", "id", blogPost.ID, "title", blogPost.Title)
 
+	// private and unpublished posts are read with a share link, and are not
+	// found without one
+	if blogPost.Restricted() && !app.authenticated(r) &&
+		!app.validShareToken(ctx, r.URL.Query().Get(shareParam), blogPost.ID) {
+		logger.InfoContext(ctx, "restricted post requested without a valid share link", "id", blogPost.ID)
+		app.notFound(w)
+		return
+	}
+	if !blogPost.Listed() {
+		w.Header().Set("X-Robots-Tag", "noindex")
+	}
+	if blogPost.Restricted() {
+		w.Header().Set("Cache-Control", "private, no-store")
+	}
+
+	// posts stored before they were rendered on save are rendered here until
+	// the rerender command has been run
+	content, outline := template.HTML(blogPost.HTML), blogPost.Outline
+	if blogPost.HTML == "" {
+		content, outline = app.renderMarkdown(sanitize.ContentPost, blogPost.Renderer, blogPost.Post)
+	}
+
+	backlinks, err := app.models.BlogPosts.Backlinks(ctx, blogPost.ID)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query backlinks", "id", blogPost.ID, "error", err)
+	}
+	backlinks = data.ListedPosts(backlinks)
+
+	var related []*data.BlogPost
+	if limit := app.config.Posts.RelatedPosts; limit > 0 {
+		related, err = app.models.BlogPosts.Related(ctx, blogPost.ID, limit)
+		if err != nil {
+			logger.ErrorContext(ctx, "unable to query related posts", "id", blogPost.ID, "error", err)
+		}
+	}
+
+	series, err := app.models.Series.ForPost(ctx, blogPost.ID)
+	if err != nil {
+		if !errors.Is(err, data.ErrRecordNotFound) {
+			logger.ErrorContext(ctx, "unable to query series", "id", blogPost.ID, "error", err)
+		}
+		series = nil
+	}
+	if series != nil {
+		series.HideUnlisted(blogPost.ID)
+	}
+
+	translations, err := app.models.BlogPosts.Translations(ctx, blogPost.ID)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query translations", "id", blogPost.ID, "error", err)
+	}
+
 	app.render(ctx, w, http.StatusOK, "read.tmpl", &templateData{
-		BlogPost: blogPost,
+		BlogPost:     blogPost,
+		Series:       series,
+		Backlinks:    backlinks,
+		RelatedPosts: related,
+		Translations: translations,
+		Content:      content,
+		TOC:          app.tableOfContents(outline),
+		Lang:         blogPost.Language,
 	})
 }
 
@@ -75,6 +212,15 @@ func (app *application) posts(w http.ResponseWriter, r *http.Request) {
 
 	input.Filters.Page = app.readQueryInt(qs, "page", 1, v)
 	input.Filters.PageSize = app.readQueryInt(qs, "page_size", 50_000, v)
+	input.Filters.Kind = app.readQueryString(qs, "kind", "")
+	if !slices.Contains(data.PostKinds, input.Filters.Kind) {
+		input.Filters.Kind = ""
+	}
+	input.Filters.Language = app.readQueryLanguage(qs)
+	input.Filters.Visibility = data.VisibilityPublic
+	input.Filters.Status = data.StatusPublished
+	// filters by fields that are not filterable are left out
+	input.Filters.Meta = app.readMetaFilters(qs, v)
 
 	logger.InfoContext(ctx, "querying blogposts")
 	blogPosts, _, err := app.models.BlogPosts.GetAll(ctx, input.Filters)
@@ -84,8 +230,17 @@ func (app *application) posts(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.InfoContext(ctx, "retrieved blogposts", "number", len(blogPosts))
 
+	languages, err := app.models.BlogPosts.Languages(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query languages", "error", err)
+	}
+
 	app.render(ctx, w, http.StatusOK, "posts.tmpl", &templateData{
 		BlogPosts: blogPosts,
+		Kind:      input.Filters.Kind,
+		Language:  input.Filters.Language,
+		Languages: languages,
+		Lang:      input.Filters.Language,
 	})
 }
 
@@ -117,6 +272,39 @@ func (app *application) about(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (app *application) page(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	slug := r.PathValue("slug")
+	if !data.SlugRX.MatchString(slug) {
+		logger.InfoContext(ctx, "invalid slug", "slug", slug)
+		app.notFound(w)
+		return
+	}
+
+	logger.InfoContext(ctx, "querying page", "slug", slug)
+	page, err := app.models.Pages.GetBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+	if !page.Visible {
+		logger.InfoContext(ctx, "page is not visible", "slug", slug)
+		app.notFound(w)
+		return
+	}
+	logger.InfoContext(ctx, "retrieved page", "id", page.ID, "slug", page.Slug)
+
+	app.render(ctx, w, http.StatusOK, "page.tmpl", &templateData{
+		Page: page,
+	})
+}
+
 func (app *application) feed(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	logger := utils.LoggerFromContext(ctx)
@@ -125,13 +313,21 @@ func (app *application) feed(w http.ResponseWriter, r *http.Request) {
 		data.Filters `json:"filters,omitempty"`
 	}
 
-	v := validator.New()
 	qs := r.URL.Query()
 
-	input.Filters.Page = app.readQueryInt(qs, "page", 1, v)
-	input.Filters.PageSize = app.readQueryInt(qs, "page_size", 50_000, v)
+	// feeds have the newest posts only
+	input.Filters.Page = 1
+	input.Filters.PageSize = app.config.Feed.Items
+	input.Filters.OrderBy = []string{"-created", "-id"}
+	input.Filters.Kind = app.readQueryString(qs, "kind", "")
+	if !slices.Contains(data.PostKinds, input.Filters.Kind) {
+		input.Filters.Kind = ""
+	}
+	input.Filters.Language = app.readQueryLanguage(qs)
+	input.Filters.Visibility = data.VisibilityPublic
+	input.Filters.Status = data.StatusPublished
 
-	logger.InfoContext(ctx, "querying blogposts")
+	logger.InfoContext(ctx, "querying blogposts", "limit", input.Filters.PageSize)
 	blogPosts, _, err := app.models.BlogPosts.GetAll(ctx, input.Filters)
 	if err != nil {
 		logger.ErrorContext(ctx, "unable to query blogposts", "error", err)
@@ -140,7 +336,17 @@ func (app *application) feed(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.InfoContext(ctx, "retrieved blogposts", "amount", len(blogPosts))
 
-	app.renderXML(ctx, w, http.StatusOK, &templateData{
-		BlogPosts: blogPosts,
-	})
+	settings := app.siteSettings(ctx)
+	channel := rss.Channel{
+		Title:       settings.Title,
+		Link:        settings.URL("/"),
+		Description: settings.Tagline,
+		Language:    settings.Language,
+	}
+	if input.Filters.Language != "" {
+		channel.Link = settings.URL("/post?language=" + url.QueryEscape(input.Filters.Language))
+		channel.Language = input.Filters.Language
+	}
+
+	app.writeFeed(ctx, w, r, channel, blogPosts)
 }
@@ -14,6 +14,7 @@ import (
 	"strings"
 	"time"
 
+	"textonly.islandwind.me/internal/data"
 	"textonly.islandwind.me/internal/utils"
 	"textonly.islandwind.me/internal/validator"
 )
@@ -39,18 +40,9 @@ func (app *application) render(
 	page string,
 	data *templateData,
 ) {
-	ts, ok := app.templateCache[page]
-	if !ok {
-		err := fmt.Errorf("the template %s does not exist", page)
-		app.logger.ErrorContext(ctx, "error occurred while rendering template", "error", err)
-		app.serverError(w, err)
-		return
-	}
-
-	buf := new(bytes.Buffer)
-
-	err := ts.ExecuteTemplate(buf, "base", data)
+	buf, err := app.executeTemplate(ctx, page, "base", data)
 	if err != nil {
+		app.logger.ErrorContext(ctx, "error occurred while rendering template", "error", err)
 		app.serverError(w, err)
 		return
 	}
@@ -60,31 +52,50 @@ func (app *application) render(
 	buf.WriteTo(w)
 }
 
-func (app *application) renderXML(
+// executeTemplate executes the named template of the page with the data every
+// page has, such as the navigation and the site settings. The base template
+// renders the whole page, and the main template only its content.
+func (app *application) executeTemplate(
 	ctx context.Context,
-	w http.ResponseWriter,
-	status int,
+	page, name string,
 	data *templateData,
-) {
-	ts, ok := app.templateCache["feed"]
+) (*bytes.Buffer, error) {
+	ts, ok := app.template(page)
 	if !ok {
-		err := fmt.Errorf("the feed template does not exist")
-		app.logger.ErrorContext(ctx, "error occurred while rendering template", "error", err)
-		app.serverError(w, err)
-		return
+		return nil, fmt.Errorf("the template %s does not exist", page)
+	}
+
+	navPages, err := app.models.Pages.Navigation(ctx)
+	if err != nil {
+		app.logger.ErrorContext(ctx, "unable to query navigation pages", "error", err)
+	}
+	data.NavPages = navPages
+	data.Settings = app.siteSettings(ctx)
+	data.Nonce = NonceFromContext(ctx)
+	data.i18n = app.translations()
+	// handlers set the language of the content they show
+	if data.Lang == "" {
+		data.Lang = pageLanguage(ctx, data.i18n, data.Settings)
 	}
 
 	buf := new(bytes.Buffer)
+	if err = ts.ExecuteTemplate(buf, name, data); err != nil {
+		return nil, err
+	}
 
-	err := ts.ExecuteTemplate(buf, "feed.tmpl", data)
+	return buf, nil
+}
+
+// siteSettings returns the site settings stored in the database, falling
+// back to the defaults if they cannot be read, so that pages still render.
+func (app *application) siteSettings(ctx context.Context) *data.SiteSettings {
+	settings, err := app.models.Settings.Get(ctx)
 	if err != nil {
-		app.serverError(w, err)
-		return
+		app.logger.ErrorContext(ctx, "unable to query site settings", "error", err)
+		return data.DefaultSiteSettings()
 	}
 
-	w.WriteHeader(status)
-
-	buf.WriteTo(w)
+	return settings
 }
 
 func GetURL() string {
@@ -153,18 +164,52 @@ func (app *application) redirectToLatestPost(w http.ResponseWriter, r *http.Requ
 	posts, err := app.models.BlogPosts.LastN(ctx, 1)
 	if err != nil {
 		logger.ErrorContext(ctx, "error occurred while redirecting", "error", err)
+		app.serverError(w, err)
 		return
 	}
 
-	if len(posts) != 1 {
-		logger.ErrorContext(ctx, "unexected number of posts returned", "posts", posts)
-		app.notFound(w)
+	if len(posts) == 0 {
+		logger.InfoContext(ctx, "no posts to redirect to, rendering home page")
+		app.homePage(w, r)
+		return
 	}
 
 	urlString := fmt.Sprintf("/post/read/%d", posts[0].ID)
 
 	logger.InfoContext(ctx, "redirecting to last post", "url", urlString)
-	http.Redirect(w, r, urlString, http.StatusMovedPermanently)
+	http.Redirect(w, r, urlString, http.StatusFound)
+}
+
+// readMetaFilters reads the filters by custom fields, given as meta.<key>
+// query parameters, and converts their values to the type of the field.
+func (app *application) readMetaFilters(qs url.Values, v *validator.Validator) map[string]any {
+	filters := map[string]any{}
+	for param := range qs {
+		key, ok := strings.CutPrefix(param, "meta.")
+		if !ok {
+			continue
+		}
+
+		value, err := app.config.Posts.Meta.ParseFilter(key, qs.Get(param))
+		if err != nil {
+			v.AddError(param, err.Error())
+			continue
+		}
+		filters[key] = value
+	}
+
+	return filters
+}
+
+// readQueryLanguage reads the language the blog pages are filtered by. Pages
+// leave out invalid languages rather than failing.
+func (app *application) readQueryLanguage(qs url.Values) string {
+	language := qs.Get("language")
+	if !data.LanguageRX.MatchString(language) || !validator.MaxChars(language, 35) {
+		return ""
+	}
+
+	return language
 }
 
 func (app *application) readQueryString(
@@ -220,6 +265,25 @@ func (app *application) readQueryParamToIntPtr(
 	return &i
 }
 
+func (app *application) readQueryBoolPtr(
+	qs url.Values,
+	key string,
+	v *validator.Validator,
+) *bool {
+	s := qs.Get(key)
+	if s == "" {
+		return nil
+	}
+
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		v.AddError(key, "must be a boolean value")
+		return nil
+	}
+
+	return &b
+}
+
 func (app *application) readQueryDate(
 	qs url.Values,
 	key string,
@@ -5,7 +5,13 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+
+	"textonly.islandwind.me/cmd/web/config"
+	"textonly.islandwind.me/internal/assert"
+	"textonly.islandwind.me/internal/data"
+	"textonly.islandwind.me/internal/validator"
 )
 
 func TestWriteJSON(t *testing.T) {
@@ -59,3 +65,62 @@ func TestWriteJSON(t *testing.T) {
 		}
 	}
 }
+
+func TestReadMetaFilters(t *testing.T) {
+	app := &application{config: &config.Config{Posts: &config.PostsConfig{
+		Meta: data.MetaSchema{
+			"license": {Type: data.MetaString, Filterable: true},
+			"rating":  {Type: data.MetaNumber, Filterable: true},
+			"draft":   {Type: data.MetaBool, Filterable: true},
+			"cover":   {Type: data.MetaURL},
+		},
+	}}}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantFilter map[string]any
+		wantErrors []string
+	}{
+		{
+			name:       "Typed values",
+			query:      "meta.license=cc-by&meta.rating=4.5&meta.draft=false&title=x",
+			wantFilter: map[string]any{"license": "cc-by", "rating": 4.5, "draft": false},
+		},
+		{
+			name:       "Not filterable",
+			query:      "meta.cover=https://example.com&meta.unknown=1",
+			wantFilter: map[string]any{},
+			wantErrors: []string{"meta.cover", "meta.unknown"},
+		},
+		{
+			name:       "Invalid number",
+			query:      "meta.rating=high",
+			wantFilter: map[string]any{},
+			wantErrors: []string{"meta.rating"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qs, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v := validator.New()
+			filters := app.readMetaFilters(qs, v)
+
+			assert.Equal(t, len(filters), len(tt.wantFilter))
+			for key, want := range tt.wantFilter {
+				assert.Equal(t, filters[key], want)
+			}
+			assert.Equal(t, len(v.Errors), len(tt.wantErrors))
+			for _, key := range tt.wantErrors {
+				if _, ok := v.Errors[key]; !ok {
+					t.Errorf("Expected error for %s in %v", key, v.Errors)
+				}
+			}
+		})
+	}
+}
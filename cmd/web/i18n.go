@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"textonly.islandwind.me/internal/data"
+	"textonly.islandwind.me/internal/i18n"
+	"textonly.islandwind.me/internal/utils"
+)
+
+const languagesKey utils.ContextKey = "languages"
+
+// WithLanguages embeds the languages the client prefers in the given context.
+func WithLanguages(ctx context.Context, languages []string) context.Context {
+	return context.WithValue(ctx, languagesKey, languages)
+}
+
+// LanguagesFromContext returns the languages the client prefers, the most
+// preferred first, or nil if there are none in the context.
+func LanguagesFromContext(ctx context.Context) []string {
+	languages, _ := ctx.Value(languagesKey).([]string)
+	return languages
+}
+
+// acceptLanguage embeds the languages of the Accept-Language header in the
+// request context, where they are picked up when rendering templates.
+func (app *application) acceptLanguage(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Language")
+
+		languages := i18n.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+		next.ServeHTTP(w, r.WithContext(WithLanguages(r.Context(), languages)))
+	})
+}
+
+// translations returns the message catalogs. They may be replaced at any time
+// while a theme is being watched.
+func (app *application) translations() *i18n.Bundle {
+	app.templateMu.RLock()
+	defer app.templateMu.RUnlock()
+
+	return app.i18n
+}
+
+// pageLanguage returns the language the user interface of a page is shown in
+// when the handler has not chosen one: the first language the client prefers
+// that there is a catalog for, or else the language of the site.
+func pageLanguage(ctx context.Context, bundle *i18n.Bundle, settings *data.SiteSettings) string {
+	if bundle != nil {
+		if language, ok := bundle.Match(LanguagesFromContext(ctx)...); ok {
+			return language
+		}
+	}
+
+	return settings.Language
+}
+
+// T translates the message with the key into the language of the page, as in
+// {{ .T "post.read" }}, formatting it with the arguments.
+func (td *templateData) T(key string, args ...any) string {
+	if td.i18n == nil {
+		return key
+	}
+
+	return td.i18n.T(td.Lang, key, args...)
+}
+
+// Date formats the date of the time in the language of the page.
+func (td *templateData) Date(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	if td.i18n == nil {
+		return humanDate(*t)
+	}
+
+	return td.i18n.Date(td.Lang, t.UTC())
+}
+
+// LanguageName returns the name of the language in the language itself.
+func (td *templateData) LanguageName(language string) string {
+	if td.i18n == nil {
+		return language
+	}
+
+	return td.i18n.LanguageName(language)
+}
+
+// postCard is a post shown as a card on a listing page, where the card needs
+// the page to translate its strings, as in {{ .Page.T "post.read" }}.
+type postCard struct {
+	*data.BlogPost
+	Page *templateData
+}
+
+// card pairs the post with the page it is listed on, as in
+// {{ template "post-card-body" (card . $) }}.
+func card(bp *data.BlogPost, page *templateData) postCard {
+	return postCard{BlogPost: bp, Page: page}
+}
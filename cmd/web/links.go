@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"textonly.islandwind.me/internal/data"
+	"textonly.islandwind.me/internal/markdown"
+	"textonly.islandwind.me/internal/utils"
+)
+
+type BrokenLinksResponse struct {
+	Data []*data.PostLink `json:"data"`
+}
+
+// postLinkResolver returns the resolver of the wiki links in the post, or in
+// other content when the post is nil. Content everyone can read only links to
+// posts everyone can read, so links to private posts and to posts that are not
+// published are shown as broken there instead of giving the posts away.
+func (app *application) postLinkResolver(source *data.BlogPost) markdown.LinkResolver {
+	public := source == nil || !source.Restricted()
+
+	return func(target string) (string, string, bool) {
+		return app.resolvePostLink(target, public)
+	}
+}
+
+// resolvePostLink resolves the target of a wiki link, a post slug prefixed
+// with post: or a post ID, to the current URL and title of the post. From
+// public content, restricted posts are not resolved.
+func (app *application) resolvePostLink(target string, public bool) (string, string, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var (
+		bp  *data.BlogPost
+		err error
+	)
+	if slug, ok := strings.CutPrefix(target, markdown.PostLinkPrefix); ok {
+		bp, err = app.models.BlogPosts.GetBySlug(ctx, slug)
+	} else {
+		var id int
+		if id, err = strconv.Atoi(target); err == nil {
+			bp, err = app.models.BlogPosts.Get(ctx, id)
+		}
+	}
+	if err != nil {
+		if !errors.Is(err, data.ErrNoRecord) {
+			app.logger.Error("unable to resolve post link", "target", target, "error", err)
+		}
+		return "", "", false
+	}
+	if public && bp.Restricted() {
+		return "", "", false
+	}
+
+	return fmt.Sprintf("/post/read/%d", bp.ID), bp.Title, true
+}
+
+// @Summary		List broken internal links
+// @Description	List the wiki links in blog posts whose target post does not exist, or that public posts have to private or unpublished posts
+// @Tags			Blog Post
+// @Produce		json
+// @Success		200	{object}	BrokenLinksResponse
+// @Failure		500	{object}	ErrorMessage
+// @Failure		401	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/post/links/broken [get]
+func (app *application) brokenLinksHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	links, err := app.models.BlogPosts.BrokenLinks(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query broken links", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, BrokenLinksResponse{Data: links}, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to write response", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
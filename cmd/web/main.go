@@ -1,19 +1,26 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"flag"
 	"fmt"
 	"html/template"
+	"io/fs"
 	"log/slog"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"textonly.islandwind.me/cmd/web/config"
 	"textonly.islandwind.me/internal/data"
+	"textonly.islandwind.me/internal/i18n"
+	"textonly.islandwind.me/internal/markdown"
+	"textonly.islandwind.me/internal/sanitize"
 	"textonly.islandwind.me/internal/vcs"
+	"textonly.islandwind.me/ui"
 )
 
 var (
@@ -23,7 +30,13 @@ var (
 type application struct {
 	logger        *slog.Logger
 	models        data.Models
+	files         fs.FS
+	assets        *assetManifest
+	templateMu    sync.RWMutex
 	templateCache map[string]*template.Template
+	i18n          *i18n.Bundle
+	markdown      *markdown.Registry
+	sanitizer     *sanitize.Sanitizer
 	config        *config.Config
 }
 
@@ -51,6 +64,10 @@ func main() {
 		fmt.Printf("textonly.islandwind.me %s\n", version)
 	}
 
+	if flag.Arg(0) == "highlight-css" {
+		os.Exit(writeHighlightCSS(flag.Arg(1)))
+	}
+
 	slog.Info("loading configuration")
 	config, err := config.New()
 	if err != nil {
@@ -59,6 +76,56 @@ func main() {
 	}
 	slog.Info("configuration loaded", "configuration", config)
 
+	var files fs.FS = ui.Files
+	if config.Theme.Path != "" {
+		slog.Info("using theme", "path", config.Theme.Path)
+		files = ui.Theme(config.Theme.Path)
+	}
+
+	sanitizer, err := sanitize.New(config.Sanitizer.Policies)
+	if err != nil {
+		slog.Error("unable to create HTML sanitizer", "error", err)
+		os.Exit(1)
+	}
+
+	renderers, err := markdown.NewRegistry(config.Markdown.Renderers, config.Markdown.Default)
+	if err != nil {
+		slog.Error("unable to create markdown renderers", "error", err)
+		os.Exit(1)
+	}
+
+	if err = config.Posts.Meta.Check(); err != nil {
+		slog.Error("invalid custom fields", "error", err)
+		os.Exit(1)
+	}
+
+	if config.Feed.Items < 1 {
+		slog.Error("invalid feed configuration", "error", "items must be at least 1", "items", config.Feed.Items)
+		os.Exit(1)
+	}
+
+	if config.Posts.Share.Secret == "" {
+		slog.Warn("no share link secret configured, share links stop working on restart")
+		config.Posts.Share.Secret, err = newShareSecret()
+		if err != nil {
+			slog.Error("unable to create share link secret", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	if flag.Arg(0) == "validate-theme" {
+		if path := flag.Arg(1); path != "" {
+			files = ui.Theme(path)
+		}
+		app := &application{
+			logger:    instanceLogger,
+			markdown:  renderers,
+			sanitizer: sanitizer,
+			config:    config,
+		}
+		os.Exit(app.validateTheme(files))
+	}
+
 	logger.Info("opening database connection pool...")
 	db, err := openDB(config.Database.DSN)
 	if err != nil {
@@ -69,21 +136,58 @@ func main() {
 	queryTimeout := time.Duration(config.Database.Timeout) * time.Second
 	logger.Info("database connection pool established")
 
+	logger.Info("fingerprinting static files...")
+	assets, err := newAssetManifest(files)
+	if err != nil {
+		logger.Error("an error occurred while fingerprinting static files", "error", err)
+		os.Exit(1)
+	}
+
+	app := &application{
+		logger:    instanceLogger,
+		models:    data.NewModels(db, &queryTimeout),
+		files:     files,
+		assets:    assets,
+		markdown:  renderers,
+		sanitizer: sanitizer,
+		config:    config,
+	}
+	app.registerShortcodes()
+	app.markdown.SetLinkResolver(app.postLinkResolver(nil))
+	app.models.BlogPosts.Render = app.renderPost
+	app.models.BlogPosts.RequireApproval = config.Posts.Review.RequireApproval
+
+	if flag.Arg(0) == "rerender" {
+		os.Exit(app.rerender())
+	}
+
 	logger.Info("caching templates...")
-	templateCache, err := newTemplateCache()
+	app.templateCache, err = newTemplateCache(files, app.templateFunctions(assets))
 	if err != nil {
 		logger.Error("an error occurred while caching templates", "error", err)
 		os.Exit(1)
 	}
 	logger.Info("templates successfully cached")
 
-	app := &application{
-		logger:        instanceLogger,
-		models:        data.NewModels(db, &queryTimeout),
-		templateCache: templateCache,
-		config:        config,
+	logger.Info("loading message catalogs...")
+	app.i18n, err = i18n.Load(files)
+	if err != nil {
+		logger.Error("an error occurred while loading message catalogs", "error", err)
+		os.Exit(1)
+	}
+	logger.Info("message catalogs loaded", "languages", app.i18n.Languages())
+
+	if config.Server.ENV == "development" && config.Theme.Path != "" {
+		err = app.watchTheme(config.Theme.Path)
+		if err != nil {
+			logger.Error("unable to watch theme for changes", "error", err)
+		}
 	}
 
+	// comparing every post to the others takes too long to wait for when a
+	// post is stored
+	app.models.BlogPosts.RefreshRelatedInBackground(context.Background())
+
 	err = app.serve(app.config.Server.URL)
 	if err != nil {
 		logger.Error("an error occurred", "error", err)
@@ -16,13 +16,6 @@ import (
 
 func secureHeaders(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Security-Policy",
-			"default-src 'self' https://cdn.jsdelivr.net/npm/; "+
-				"style-src 'self' 'unsafe-inline' https://cdn.jsdelivr.net/npm/; "+
-				"script-src 'self' 'unsafe-inline' 'unsafe-eval' https://cdn.jsdelivr.net/npm/;"+
-				"font-src 'self' https://cdn.jsdelivr.net/npm/; "+
-				"img-src * data: https://online.swagger.io;",
-		)
 		w.Header().Set("Referrer-Policy", "origin-when-cross-origin")
 		w.Header().Set("X-Content-Type-Options", "nosniff")
 		w.Header().Set("X-Frame-Options", "deny")
@@ -115,12 +108,41 @@ func (app *application) rateLimit(next http.Handler) http.Handler {
 	})
 }
 
+// authenticated reports whether the request carries the credentials of the
+// configured user.
+func (app *application) authenticated(r *http.Request) bool {
+	_, ok := app.authenticatedUser(r)
+	return ok
+}
+
+// authenticatedUser returns the name of the account the request is signed in
+// with: the configured user or one of the other users.
+func (app *application) authenticatedUser(r *http.Request) (string, bool) {
+	user, pass, ok := r.BasicAuth()
+	if !ok {
+		return "", false
+	}
+
+	if credentialsMatch(user, pass, app.config.Server.User, app.config.Server.Password) {
+		return user, true
+	}
+	for name, password := range app.config.Server.Users {
+		if password != "" && credentialsMatch(user, pass, name, password) {
+			return user, true
+		}
+	}
+
+	return "", false
+}
+
+func credentialsMatch(user, pass, wantUser, wantPass string) bool {
+	return subtle.ConstantTimeCompare([]byte(user), []byte(wantUser)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(pass), []byte(wantPass)) == 1
+}
+
 func (app *application) basicAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		user, pass, ok := r.BasicAuth()
-
-		if !ok || subtle.ConstantTimeCompare([]byte(user), []byte(app.config.Server.User)) != 1 ||
-			subtle.ConstantTimeCompare([]byte(pass), []byte(app.config.Server.Password)) != 1 {
+		if !app.authenticated(r) {
 			w.Header().Set("WWW-Authenticate", `Basic realm="`+app.config.Server.Realm+`"`)
 			w.WriteHeader(http.StatusUnauthorized)
 			return
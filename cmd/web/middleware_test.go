@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"textonly.islandwind.me/cmd/web/config"
+	"textonly.islandwind.me/internal/assert"
+)
+
+func TestAuthenticatedUser(t *testing.T) {
+	app := &application{config: &config.Config{Server: &config.ServerConfig{
+		User:     "admin",
+		Password: "secret",
+		Users:    map[string]string{"jane": "hunter2", "nopass": ""},
+	}}}
+
+	tests := []struct {
+		name     string
+		user     string
+		password string
+		want     string
+		ok       bool
+	}{
+		{"Configured user", "admin", "secret", "admin", true},
+		{"Other user", "jane", "hunter2", "jane", true},
+		{"Wrong password", "jane", "secret", "", false},
+		{"Unknown user", "john", "hunter2", "", false},
+		{"Empty password", "nopass", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/post/1/review/approve", nil)
+			r.SetBasicAuth(tt.user, tt.password)
+
+			user, ok := app.authenticatedUser(r)
+			assert.Equal(t, user, tt.want)
+			assert.Equal(t, ok, tt.ok)
+		})
+	}
+}
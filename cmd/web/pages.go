@@ -0,0 +1,359 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"textonly.islandwind.me/internal/data"
+	"textonly.islandwind.me/internal/utils"
+	"textonly.islandwind.me/internal/validator"
+)
+
+// reservedSlugs are top level paths already served by the application, which
+// pages can therefore not use.
+var reservedSlugs = []string{"api", "about", "feed", "home", "post", "series", "static", "swagger", "v1"}
+
+type PageResponse struct {
+	Metadata data.Metadata `json:"metadata"`
+	Data     data.Page     `json:"data"`
+}
+
+type PageListResponse struct {
+	Metadata data.Metadata `json:"metadata"`
+	Data     []*data.Page  `json:"data"`
+}
+
+type PagePostRequest struct {
+	Slug        string `json:"slug"`
+	Title       string `json:"title"`
+	Content     string `json:"content"`
+	NavPosition int    `json:"nav_position"`
+	ShowInNav   bool   `json:"show_in_nav"`
+	Visible     bool   `json:"visible"`
+}
+
+type UpdatePageResponse struct {
+	Message      string `json:"message,omitempty"`
+	ID           int    `json:"id,omitempty"`
+	RowsAffected int64  `json:"rows_affected,omitempty"`
+}
+
+// @Summary		Get a page
+// @Description	Get a page by ID
+// @Param			id	path	string	true	"ID (int)"
+// @Tags			Page
+// @Produce		json
+// @Success		200	{object}	PageResponse
+// @Failure		500	{object}	ErrorMessage
+// @Failure		401	{object}	ErrorMessage
+// @Failure		404	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/page/{id} [get]
+func (app *application) getPageHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	logger.InfoContext(ctx, "parsing page ID from path", "key", "id", "path", r.URL.Path)
+	rawValue := r.PathValue("id")
+	if rawValue == "" {
+		logger.ErrorContext(ctx, "parameter value empty", "id", rawValue)
+		app.badRequestResponse(w, r, "parameter value empty")
+		return
+	}
+
+	id, err := strconv.Atoi(rawValue)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to parse id value", "value", rawValue)
+		app.badRequestResponse(w, r, "unable to parse id value")
+		return
+	}
+	if id < 0 {
+		logger.InfoContext(ctx, "invalid ID", "id", id)
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	logger.InfoContext(ctx, "retrieving page", "id", id)
+	p, err := app.models.Pages.Get(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.InfoContext(ctx, "no records found", "id", id)
+			app.notFoundResponse(w, r)
+			return
+		default:
+			logger.ErrorContext(ctx, "an error occurred during retrieval", "error", err)
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+	// hidden pages do not exist for anyone but the author
+	if !p.Visible && !app.authenticated(r) {
+		logger.InfoContext(ctx, "hidden page requested without credentials", "id", id)
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	logger.InfoContext(ctx, "returning page", "id", p.ID, "slug", p.Slug)
+	err = app.writeJSON(w, http.StatusOK, PageResponse{Metadata: data.Metadata{}, Data: *p}, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to write response", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// @Summary		List pages
+// @Description	List pages
+// @Tags			Page
+// @Produce		json
+// @Param			id			query		int		false	"id"
+// @Param			slug		query		string	false	"slug"
+// @Param			title		query		string	false	"title"
+// @Param			visible		query		bool	false	"visible, needs credentials"
+// @Param			order_by	query		string	false	"order_by"
+// @Success		200			{object}	PageListResponse
+// @Failure		500			{object}	ErrorMessage
+// @Failure		401			{object}	ErrorMessage
+// @Failure		404			{object}	ErrorMessage
+// @Failure		429			{object}	ErrorMessage
+// @Router			/api/page/ [get]
+func (app *application) listPageHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	var input struct {
+		data.Filters `json:"filters,omitempty"`
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Filters.ID = app.readQueryParamToIntPtr(qs, "id", v)
+	input.Filters.Slug = app.readQueryString(qs, "slug", "")
+	input.Filters.Title = app.readQueryString(qs, "title", "")
+	// only the author lists hidden pages
+	isVisible := true
+	input.Filters.Visible = &isVisible
+	if app.authenticated(r) {
+		input.Filters.Visible = app.readQueryBoolPtr(qs, "visible", v)
+	}
+
+	input.Filters.Page = app.readQueryInt(qs, "page", 1, v)
+	input.Filters.PageSize = app.readQueryInt(qs, "page_size", 50_000, v)
+
+	input.Filters.OrderBy = app.readQueryCommaSeperatedString(qs, "order_by", "nav_position")
+	input.Filters.OrderBySafeList = []string{
+		"id", "slug", "title", "nav_position",
+		"-id", "-slug", "-title", "-nav_position",
+	}
+
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	pages, metadata, err := app.models.Pages.GetAll(ctx, input.Filters)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to get pages", "error", err, "input", input)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, PageListResponse{Metadata: metadata, Data: pages}, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "error writing response", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// @Summary		Post a page
+// @Description	Post a page
+//
+// @Param			PagePostRequest	body	PagePostRequest	true	"Push Page"
+//
+// @Tags			Page
+// @Produce		json
+// @Success		201	{object}	data.Page
+// @Failure		500	{object}	ErrorMessage
+// @Failure		401	{object}	ErrorMessage
+// @Failure		422	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/page [post]
+func (app *application) postPageHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	var input PagePostRequest
+
+	err := app.readJSON(r, &input)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to parse JSON request body", "error", err)
+		app.badRequestResponse(w, r, "unable to parse JSON request body")
+		return
+	}
+
+	page := &data.Page{
+		Slug:        input.Slug,
+		Title:       input.Title,
+		Content:     input.Content,
+		NavPosition: input.NavPosition,
+		ShowInNav:   input.ShowInNav,
+		Visible:     input.Visible,
+	}
+
+	v := validator.New()
+	if app.validatePage(v, page); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	p, err := app.models.Pages.Insert(ctx, page)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateSlug):
+			v.AddError("slug", "a page with this slug already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			logger.ErrorContext(ctx, "unable to create page", "error", err)
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, p, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to write response", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// @Summary		Update a page
+// @Description	Update a page by ID
+//
+// @Param			data.Page	body	data.Page	true	"Update Page"
+//
+// @Tags			Page
+// @Produce		json
+// @Success		200	{object}	UpdatePageResponse
+// @Failure		500	{object}	ErrorMessage
+// @Failure		401	{object}	ErrorMessage
+// @Failure		404	{object}	ErrorMessage
+// @Failure		422	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/page [put]
+func (app *application) updatePageHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	var input data.Page
+
+	err := app.readJSON(r, &input)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to parse JSON request body", "error", err)
+		app.badRequestResponse(w, r, "unable to parse JSON request body")
+		return
+	}
+
+	v := validator.New()
+	if app.validatePage(v, &input); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	rowsAffected, err := app.models.Pages.Update(ctx, &input)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrDuplicateSlug):
+			v.AddError("slug", "a page with this slug already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			logger.ErrorContext(ctx, "unable to update page", "error", err)
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(
+		w,
+		http.StatusOK,
+		UpdatePageResponse{Message: "page updated", RowsAffected: rowsAffected, ID: input.ID},
+		nil,
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to write response", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// @Summary		Delete a page
+// @Description	Delete a page by ID
+// @Param			id	path	string	true	"ID (int)"
+// @Tags			Page
+// @Produce		json
+// @Success		200	{object}	UpdatePageResponse
+// @Failure		500	{object}	ErrorMessage
+// @Failure		401	{object}	ErrorMessage
+// @Failure		404	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/page/{id} [delete]
+func (app *application) deletePageHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	logger.InfoContext(ctx, "parsing page ID from path", "key", "id")
+	rawValue := r.PathValue("id")
+	if rawValue == "" {
+		logger.ErrorContext(ctx, "parameter value empty", "id", rawValue)
+		app.badRequestResponse(w, r, "parameter value empty")
+		return
+	}
+
+	id, err := strconv.Atoi(rawValue)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to parse id value", "value", rawValue)
+		app.badRequestResponse(w, r, "unable to parse id value")
+		return
+	}
+	if id < 0 {
+		logger.InfoContext(ctx, "invalid ID", "id", id)
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	rowsAffected, err := app.models.Pages.Delete(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(
+		w,
+		http.StatusOK,
+		UpdatePageResponse{Message: "page deleted", RowsAffected: rowsAffected, ID: id},
+		nil,
+	)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+func (app *application) validatePage(v *validator.Validator, p *data.Page) {
+	data.ValidatePage(v, p)
+	v.Check(!slices.Contains(reservedSlugs, p.Slug), "slug", "is reserved by the application")
+	app.validateShortcodes(v, "content", "", p.Content)
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"textonly.islandwind.me/internal/data"
 	"textonly.islandwind.me/internal/utils"
@@ -21,9 +24,35 @@ type BlogPostListResponse struct {
 }
 
 type BlogPostRequest struct {
-	Title string `json:"title"`
-	Lead  string `json:"lead"`
-	Post  string `json:"post_content"`
+	// Kind is article, note or link. Posts are articles when it is empty.
+	Kind     string `json:"kind,omitempty"`
+	Title    string `json:"title"`
+	Lead     string `json:"lead"`
+	Post     string `json:"post_content"`
+	Featured bool   `json:"featured"`
+	// Renderer is the name of the markdown renderer. The default renderer is
+	// used when it is empty.
+	Renderer string `json:"renderer,omitempty"`
+	// Slug identifies the post in wiki links. It is derived from the title
+	// when it is empty.
+	Slug string `json:"slug,omitempty"`
+	// LinkURL is the page a link shares, and Quote an optional quote from it.
+	LinkURL string `json:"link_url,omitempty"`
+	Quote   string `json:"quote,omitempty"`
+	// Meta holds custom fields, such as a cover image or a license.
+	Meta data.PostMeta `json:"meta,omitempty"`
+	// Language is the language tag of the post. Posts are in the language of
+	// the site when it is empty.
+	Language string `json:"language,omitempty"`
+	// TranslationOf is the ID of the post this post translates.
+	TranslationOf *int `json:"translation_of,omitempty"`
+	// Visibility is public, unlisted or private. Posts are public when it is
+	// empty.
+	Visibility string `json:"visibility,omitempty"`
+	// Status is draft or published; posts are published later through the
+	// review workflow. Posts are drafts when approval is required and it is
+	// empty, and published otherwise.
+	Status string `json:"status,omitempty"`
 }
 
 type UpdateBlogResponse struct {
@@ -82,6 +111,18 @@ func (app *application) getBlogHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	// private and unpublished posts do not exist for anyone but the author
+	if bp.Restricted() && !app.authenticated(r) {
+		logger.InfoContext(ctx, "restricted post requested without credentials", "id", id)
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if bp.HTML == "" {
+		if err = app.renderPost(bp); err != nil {
+			logger.ErrorContext(ctx, "unable to render blog post", "error", err)
+		}
+	}
 
 	logger.InfoContext(ctx, "returning blog post", "id", bp.ID, "title", bp.Title)
 	err = app.writeJSON(
@@ -108,6 +149,12 @@ func (app *application) getBlogHandler(w http.ResponseWriter, r *http.Request) {
 // @Param			created_to			query		string	false	"created_to"
 // @Param			last_updated_from	query		string	false	"last_updated_from"
 // @Param			last_updated_to		query		string	false	"last_updated_to"
+// @Param			featured			query		bool	false	"featured"
+// @Param			kind				query		string	false	"kind (article, note or link)"
+// @Param			language			query		string	false	"language (as in en or nb)"
+// @Param			meta.{key}			query		string	false	"value of a filterable custom field"
+// @Param			visibility			query		string	false	"visibility (public, unlisted or private), needs credentials"
+// @Param			status				query		string	false	"status (draft, in_review, approved or published), needs credentials"
 // @Param			order_by			query		string	false	"order_by"
 //
 // @Success		200					{object}	BlogPostListResponse
@@ -138,6 +185,17 @@ func (app *application) listBlogHandler(w http.ResponseWriter, r *http.Request)
 	input.Filters.CreatedTo = app.readQueryDate(qs, "created_to", v)
 	input.Filters.LastUpdatedFrom = app.readQueryDate(qs, "last_updated_from", v)
 	input.Filters.LastUpdatedTo = app.readQueryDate(qs, "last_updated_to", v)
+	input.Filters.Featured = app.readQueryBoolPtr(qs, "featured", v)
+	input.Filters.Kind = app.readQueryString(qs, "kind", "")
+	input.Filters.Language = app.readQueryString(qs, "language", "")
+	input.Filters.Meta = app.readMetaFilters(qs, v)
+	// only the author lists posts that are not public and published
+	input.Filters.Visibility = data.VisibilityPublic
+	input.Filters.Status = data.StatusPublished
+	if app.authenticated(r) {
+		input.Filters.Visibility = app.readQueryString(qs, "visibility", "")
+		input.Filters.Status = app.readQueryString(qs, "status", "")
+	}
 
 	input.Filters.Page = app.readQueryInt(qs, "page", 1, v)
 	input.Filters.PageSize = app.readQueryInt(qs, "page_size", 50_000, v)
@@ -199,16 +257,37 @@ func (app *application) postBlogHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	bp, err := app.models.BlogPosts.Insert(ctx, &data.BlogPost{
-		Title: blogPost.Title,
-		Lead:  blogPost.Lead,
-		Post:  blogPost.Post,
-	})
-	if err != nil {
-		logger.ErrorContext(ctx, "unable to create blog post", "error", err)
+	post, derivedSlug := app.newBlogPost(ctx, &blogPost)
+
+	v := validator.New()
+	app.validateNewBlogPost(v, post)
+	if err = app.validateTranslationOf(ctx, v, 0, post.TranslationOf); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	slug := post.Slug
+	bp, err := app.models.BlogPosts.Insert(ctx, post)
+	// a slug derived from a title another post has gets a number
+	for n := 2; derivedSlug && errors.Is(err, data.ErrDuplicateSlug) && n <= 10; n++ {
+		post.Slug = fmt.Sprintf("%s-%d", slug, n)
+		bp, err = app.models.BlogPosts.Insert(ctx, post)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateSlug):
+			v.AddError("slug", "a blog post with this slug already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			logger.ErrorContext(ctx, "unable to create blog post", "error", err)
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
 
 	err = app.writeJSON(w, http.StatusCreated, bp, nil)
 	if err != nil {
@@ -308,16 +387,65 @@ func (app *application) updateBlogHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// an empty kind keeps the kind the post already has, which the fields
+	// are validated for
+	if input.Kind == "" {
+		current, err := app.models.BlogPosts.Get(ctx, input.ID)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound), errors.Is(err, data.ErrNoRecord):
+				app.notFoundResponse(w, r)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+		input.Kind = current.Kind
+	}
+
+	// an empty renderer keeps the renderer the post already has
+	v := validator.New()
+	data.ValidateBlogPost(v, &input)
+	data.ValidateMeta(v, input.Meta, app.config.Posts.Meta)
+	if input.Renderer != "" {
+		app.validateRenderer(v, input.Renderer)
+	}
+	app.validateShortcodes(v, "post", input.Renderer, input.Post)
+	// an empty slug keeps the slug the post already has
+	if input.Slug != "" {
+		data.ValidatePostSlug(v, input.Slug)
+	}
+	if err = app.validateTranslationOf(ctx, v, input.ID, input.TranslationOf); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
 	rowsAffected, err := app.models.BlogPosts.Update(ctx, &input)
 	if err != nil {
-		app.serverErrorResponse(w, r, err)
+		switch {
+		case errors.Is(err, data.ErrDuplicateSlug):
+			v.AddError("slug", "a blog post with this slug already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
 		return
 	}
 
+	// with approval required, changed content has to be reviewed again
+	message := "blog post updated"
+	if app.config.Posts.Review.RequireApproval && input.Status == data.StatusDraft {
+		message = "blog post updated as a draft, to be reviewed before it is published"
+	}
+
 	err = app.writeJSON(
 		w,
 		http.StatusOK,
-		UpdateBlogResponse{Message: "blog post updated", RowsAffected: rowsAffected, ID: input.ID},
+		UpdateBlogResponse{Message: message, RowsAffected: rowsAffected, ID: input.ID},
 		nil,
 	)
 	if err != nil {
@@ -325,3 +453,111 @@ func (app *application) updateBlogHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 }
+
+// newBlogPost returns the post the request creates, with defaults for the
+// fields it leaves empty. derivedSlug reports whether the slug was derived
+// from the title.
+func (app *application) newBlogPost(
+	ctx context.Context,
+	blogPost *BlogPostRequest,
+) (post *data.BlogPost, derivedSlug bool) {
+	if blogPost.Renderer == "" {
+		blogPost.Renderer = app.markdown.Default()
+	}
+	if blogPost.Kind == "" {
+		blogPost.Kind = data.KindArticle
+	}
+	if blogPost.Language == "" {
+		blogPost.Language = app.siteSettings(ctx).Language
+	}
+	if blogPost.Visibility == "" {
+		blogPost.Visibility = data.VisibilityPublic
+	}
+	if blogPost.Status == "" {
+		blogPost.Status = data.StatusPublished
+		if app.config.Posts.Review.RequireApproval {
+			blogPost.Status = data.StatusDraft
+		}
+	}
+
+	post = &data.BlogPost{
+		Slug:       blogPost.Slug,
+		Kind:       blogPost.Kind,
+		Visibility: blogPost.Visibility,
+		Status:     blogPost.Status,
+		Title:      blogPost.Title,
+		Lead:       blogPost.Lead,
+		Post:       blogPost.Post,
+		LinkURL:    blogPost.LinkURL,
+		Quote:      blogPost.Quote,
+		Meta:       blogPost.Meta,
+		Featured:   blogPost.Featured,
+		Renderer:   blogPost.Renderer,
+		Language:   blogPost.Language,
+		// translations are stored as translations of the original
+		TranslationOf: blogPost.TranslationOf,
+	}
+
+	// notes and links without a title get the slug of what is shown instead
+	derivedSlug = blogPost.Slug == ""
+	if derivedSlug {
+		post.Slug = data.Slugify(post.DisplayTitle())
+	}
+
+	return post, derivedSlug
+}
+
+// validateNewBlogPost checks the post a request creates, except for the post
+// it translates, which is looked up in the database.
+func (app *application) validateNewBlogPost(v *validator.Validator, post *data.BlogPost) {
+	data.ValidateBlogPost(v, post)
+	data.ValidateMeta(v, post.Meta, app.config.Posts.Meta)
+	app.validateRenderer(v, post.Renderer)
+	app.validateShortcodes(v, "post", post.Renderer, post.Post)
+	data.ValidatePostSlug(v, post.Slug)
+	app.validateNewStatus(v, post.Status)
+}
+
+// validateTranslationOf checks that the post a post translates exists, and
+// is neither the post itself nor one of its translations.
+func (app *application) validateTranslationOf(
+	ctx context.Context,
+	v *validator.Validator,
+	id int,
+	translationOf *int,
+) error {
+	if translationOf == nil {
+		return nil
+	}
+
+	original, err := app.models.BlogPosts.Get(ctx, *translationOf)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) || errors.Is(err, data.ErrNoRecord) {
+			v.AddError("translation_of", "must be the ID of an existing post")
+			return nil
+		}
+		return err
+	}
+
+	// translations are stored as translations of the original
+	group := original.ID
+	if original.TranslationOf != nil {
+		group = *original.TranslationOf
+	}
+	v.Check(
+		group != id,
+		"translation_of",
+		"must not be the post itself or one of its translations",
+	)
+
+	return nil
+}
+
+// validateRenderer checks that a markdown renderer with the name exists.
+func (app *application) validateRenderer(v *validator.Validator, name string) {
+	v.Check(
+		app.markdown.Has(name),
+		"renderer",
+		fmt.Sprintf("must be one of %s", strings.Join(app.markdown.Names(), ", ")),
+	)
+}
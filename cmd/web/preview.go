@@ -0,0 +1,78 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+
+	"textonly.islandwind.me/internal/utils"
+	"textonly.islandwind.me/internal/validator"
+)
+
+// @Summary		Preview a blog post
+// @Description	Render a blog post through the templates of the site without storing it. The whole page is returned, or only the article with fragment=true.
+// @Param			BlogPostRequest	body	BlogPostRequest	true	"Blog Post"
+// @Param			fragment		query	bool			false	"only the article, without the layout of the site"
+// @Tags			Blog Post
+// @Accept			json
+// @Produce		html
+// @Success		200	{string}	string	"HTML"
+// @Failure		500	{object}	ErrorMessage
+// @Failure		400	{object}	ErrorMessage
+// @Failure		401	{object}	ErrorMessage
+// @Failure		422	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/preview [post]
+func (app *application) previewHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	var blogPost BlogPostRequest
+
+	err := app.readJSON(r, &blogPost)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to parse JSON request body", "error", err)
+		app.badRequestResponse(w, r, "unable to parse JSON request body")
+		return
+	}
+
+	v := validator.New()
+	fragment := app.readQueryBoolPtr(r.URL.Query(), "fragment", v)
+
+	post, _ := app.newBlogPost(ctx, &blogPost)
+	app.validateNewBlogPost(v, post)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// the post is rendered as it is when it is stored, shortcodes included
+	if err = app.renderPost(post); err != nil {
+		logger.ErrorContext(ctx, "unable to render blog post", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	name := "base"
+	if fragment != nil && *fragment {
+		name = "main"
+	}
+
+	logger.InfoContext(ctx, "rendering preview", "title", post.DisplayTitle(), "template", name)
+	buf, err := app.executeTemplate(ctx, "read.tmpl", name, &templateData{
+		BlogPost: post,
+		Content:  template.HTML(post.HTML),
+		TOC:      app.tableOfContents(post.Outline),
+		Lang:     post.Language,
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to render preview", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	w.WriteHeader(http.StatusOK)
+	buf.WriteTo(w)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"textonly.islandwind.me/cmd/web/config"
+	"textonly.islandwind.me/internal/assert"
+	"textonly.islandwind.me/internal/data"
+	"textonly.islandwind.me/internal/markdown"
+)
+
+func TestPreviewHandler(t *testing.T) {
+	db := &statementRecorder{}
+	timeout := time.Second
+	renderers, err := markdown.NewRegistry(map[string]markdown.Options{
+		"commonmark": {Engine: markdown.EngineCommonMark, Extensions: []string{markdown.ExtShortcodes}},
+	}, "commonmark")
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+
+	app := newTestApplication(t)
+	app.markdown = renderers
+	app.models = data.NewModels(sql.OpenDB(db), &timeout)
+	app.config = &config.Config{Posts: &config.PostsConfig{}, Markdown: &config.MarkdownConfig{}}
+
+	tests := []struct {
+		name     string
+		url      string
+		body     string
+		status   int
+		want     []string
+		unwanted []string
+	}{
+		{
+			name:   "Fragment",
+			url:    "/api/preview?fragment=true",
+			body:   `{"title": "Hello", "post_content": "{{< note >}}Mind the gap.{{< /note >}}"}`,
+			status: http.StatusOK,
+			want: []string{
+				`<aside class="note note-note" role="note">`,
+				`<p>Mind the gap.</p>`,
+			},
+			unwanted: []string{"<html"},
+		},
+		{
+			name:   "Page",
+			url:    "/api/preview",
+			body:   `{"title": "Hello", "post_content": "Some text."}`,
+			status: http.StatusOK,
+			want:   []string{`<html lang="en">`, `<p>Some text.</p>`},
+		},
+		{
+			name:   "Unknown shortcode",
+			url:    "/api/preview?fragment=true",
+			body:   `{"title": "Hello", "post_content": "{{< youtube abc >}}"}`,
+			status: http.StatusUnprocessableEntity,
+			want:   []string{"unknown shortcode"},
+		},
+		{
+			name:   "No title",
+			url:    "/api/preview?fragment=true",
+			body:   `{"post_content": "Some text."}`,
+			status: http.StatusUnprocessableEntity,
+			want:   []string{"must be provided"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+
+			app.previewHandler(rr, r)
+
+			assert.Equal(t, rr.Code, tt.status)
+			for _, want := range tt.want {
+				if !strings.Contains(rr.Body.String(), want) {
+					t.Errorf("Expected '%s' in '%s'", want, rr.Body.String())
+				}
+			}
+			for _, unwanted := range tt.unwanted {
+				if strings.Contains(rr.Body.String(), unwanted) {
+					t.Errorf("Expected no '%s' in '%s'", unwanted, rr.Body.String())
+				}
+			}
+		})
+	}
+
+	// previews read the navigation and the settings, but are never stored
+	assert.Equal(t, len(db.statements) > 0, true)
+	for _, stmt := range db.statements {
+		verb := strings.ToUpper(strings.Fields(stmt)[0])
+		if verb != "SELECT" {
+			t.Errorf("Expected only queries but got '%s'", stmt)
+		}
+	}
+}
+
+// errNoDatabase is returned for every statement given to a statementRecorder.
+var errNoDatabase = errors.New("no database")
+
+// statementRecorder is a database connector whose connections record the
+// statements they are given and fail them, so that handlers can be checked
+// for what they would have done to the database.
+type statementRecorder struct {
+	mu         sync.Mutex
+	statements []string
+}
+
+func (d *statementRecorder) Connect(context.Context) (driver.Conn, error) {
+	return statementConn{d}, nil
+}
+
+func (d *statementRecorder) Driver() driver.Driver {
+	return statementDriver{d}
+}
+
+type statementDriver struct {
+	d *statementRecorder
+}
+
+func (d statementDriver) Open(string) (driver.Conn, error) {
+	return statementConn(d), nil
+}
+
+type statementConn struct {
+	d *statementRecorder
+}
+
+func (c statementConn) Prepare(query string) (driver.Stmt, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+
+	c.d.statements = append(c.d.statements, strings.TrimSpace(query))
+	return nil, errNoDatabase
+}
+
+func (c statementConn) Close() error {
+	return nil
+}
+
+func (c statementConn) Begin() (driver.Tx, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+
+	c.d.statements = append(c.d.statements, "BEGIN")
+	return nil, errNoDatabase
+}
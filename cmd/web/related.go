@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"textonly.islandwind.me/internal/data"
+	"textonly.islandwind.me/internal/utils"
+	"textonly.islandwind.me/internal/validator"
+)
+
+type RelatedPostsResponse struct {
+	Data []*data.BlogPost `json:"data"`
+}
+
+// @Summary		List related posts
+// @Description	List the posts most similar to a blog post by the words of their title, lead and text
+// @Param			id		path	string	true	"ID (int)"
+// @Param			limit	query	int		false	"maximum number of posts (1-10, default 5)"
+// @Tags			Blog Post
+// @Produce		json
+// @Success		200	{object}	RelatedPostsResponse
+// @Failure		500	{object}	ErrorMessage
+// @Failure		404	{object}	ErrorMessage
+// @Failure		422	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/post/{id}/related [get]
+func (app *application) relatedPostsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	rawValue := r.PathValue("id")
+	id, err := strconv.Atoi(rawValue)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to parse id value", "value", rawValue)
+		app.badRequestResponse(w, r, "unable to parse id value")
+		return
+	}
+
+	v := validator.New()
+	limit := app.readQueryInt(r.URL.Query(), "limit", 5, v)
+	v.Check(limit >= 1 && limit <= 10, "limit", "must be between 1 and 10")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	bp, err := app.models.BlogPosts.Get(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound), errors.Is(err, data.ErrNoRecord):
+			app.notFoundResponse(w, r)
+		default:
+			logger.ErrorContext(ctx, "unable to query blog post", "error", err)
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// private and unpublished posts do not exist for anyone but the author
+	if bp.Restricted() && !app.authenticated(r) {
+		logger.InfoContext(ctx, "restricted post requested without credentials", "id", id)
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	related, err := app.models.BlogPosts.Related(ctx, id, limit)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query related posts", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, RelatedPostsResponse{Data: related}, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to write response", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"textonly.islandwind.me/internal/data"
+	"textonly.islandwind.me/internal/markdown"
+	"textonly.islandwind.me/internal/sanitize"
+	"textonly.islandwind.me/internal/utils"
+)
+
+// excerptLength is the maximum number of characters in the excerpt of a post.
+const excerptLength = 300
+
+// renderPost renders the markdown of the post with its renderer, sanitizes
+// the HTML and derives the excerpt, outline and stats from it. The models
+// store the result with the post.
+func (app *application) renderPost(bp *data.BlogPost) error {
+	doc, err := app.markdown.RenderWithResolver(bp.Renderer, []byte(bp.Post), app.postLinkResolver(bp))
+	if err != nil {
+		return err
+	}
+
+	html := app.sanitizer.Sanitize(sanitize.ContentPost, doc.HTML)
+	text := markdown.PlainText(html)
+	words := markdown.WordCount(text)
+	stats := markdown.CountContent(html)
+
+	bp.HTML = string(html)
+	bp.Excerpt = markdown.Excerpt(text, excerptLength)
+	bp.Outline = doc.Outline
+	bp.Dynamic = doc.Dynamic
+	bp.PostStats = data.PostStats{
+		WordCount:     words,
+		ReadingTime:   markdown.ReadingTime(words),
+		CodeBlocks:    stats.CodeBlocks,
+		OutboundLinks: stats.OutboundLinks,
+		Images:        stats.Images,
+	}
+
+	return nil
+}
+
+// rerender renders every post again and returns the exit code for the
+// rerender command.
+func (app *application) rerender() int {
+	rendered, err := app.models.BlogPosts.Rerender(context.Background())
+	if err != nil {
+		fmt.Printf("unable to render posts: %s\n", err)
+		return 1
+	}
+
+	fmt.Printf("%d posts rendered\n", rendered)
+	return 0
+}
+
+// @Summary		Render all blog posts again
+// @Description	Render every blog post again and store the result, after the renderer configuration has changed
+// @Tags			Blog Post
+// @Produce		json
+// @Success		200	{object}	UpdateBlogResponse
+// @Failure		500	{object}	ErrorMessage
+// @Failure		401	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/post/rerender [post]
+func (app *application) rerenderHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	rendered, err := app.models.BlogPosts.Rerender(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to render blog posts", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(
+		w,
+		http.StatusOK,
+		UpdateBlogResponse{Message: "blog posts rendered", RowsAffected: int64(rendered)},
+		nil,
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to write response", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"textonly.islandwind.me/internal/data"
+	"textonly.islandwind.me/internal/utils"
+	"textonly.islandwind.me/internal/validator"
+)
+
+type ReviewRequest struct {
+	Comment string `json:"comment,omitempty"`
+}
+
+type ReviewResponse struct {
+	Data data.Review `json:"data"`
+}
+
+type ReviewListResponse struct {
+	Data []*data.Review `json:"data"`
+}
+
+// validateNewStatus checks the status a post is created with. Other statuses
+// are reached through reviews, and posts are only created published when no
+// approval is required.
+func (app *application) validateNewStatus(v *validator.Validator, status string) {
+	if app.config.Posts.Review.RequireApproval {
+		v.Check(status == data.StatusDraft, "status", "must be draft, as posts are published once approved")
+		return
+	}
+	v.Check(
+		status == data.StatusDraft || status == data.StatusPublished,
+		"status",
+		"must be draft or published",
+	)
+}
+
+// review takes the action of the review workflow on the post in the path,
+// recording the signed in user as who took it.
+func (app *application) review(w http.ResponseWriter, r *http.Request, action string) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	rawValue := r.PathValue("id")
+	id, err := strconv.Atoi(rawValue)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to parse id value", "value", rawValue)
+		app.badRequestResponse(w, r, "unable to parse id value")
+		return
+	}
+
+	var input ReviewRequest
+	if r.ContentLength != 0 {
+		if err = app.readJSON(r, &input); err != nil {
+			logger.ErrorContext(ctx, "unable to parse JSON request body", "error", err)
+			app.badRequestResponse(w, r, "unable to parse JSON request body")
+			return
+		}
+	}
+	reviewer, _ := app.authenticatedUser(r)
+
+	review := &data.Review{PostID: id, Action: action, Reviewer: reviewer, Comment: input.Comment}
+
+	v := validator.New()
+	if data.ValidateReview(v, review); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	requireApproval := app.config.Posts.Review.RequireApproval
+	if err = app.models.Reviews.Insert(ctx, review, requireApproval); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrReviewStatus):
+			v.AddError("status", reviewStatusMessage(action, requireApproval))
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrSelfReview):
+			v.AddError("reviewer", "must be someone other than who requested the review")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			logger.ErrorContext(ctx, "unable to review post", "id", id, "action", action, "error", err)
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// once published, the post is shown in the links and lists of other
+	// posts, and its own links to restricted posts are shown as broken
+	if action == data.ReviewPublish {
+		app.models.BlogPosts.RerenderPost(ctx, id)
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, ReviewResponse{Data: *review}, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to write response", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// reviewStatusMessage explains which posts the action can be taken on.
+func reviewStatusMessage(action string, requireApproval bool) string {
+	switch action {
+	case data.ReviewRequest:
+		return "review can only be requested for drafts"
+	case data.ReviewApprove:
+		return "only posts in review can be approved"
+	case data.ReviewReject:
+		return "only posts in review or approved can be rejected"
+	case data.ReviewPublish:
+		if requireApproval {
+			return "only approved posts can be published"
+		}
+		return "the post is published already"
+	}
+	return fmt.Sprintf("the post can not be given the action %s", action)
+}
+
+// @Summary		Request a review
+// @Description	Send a draft to review, after which it can be approved or rejected
+// @Param			id				path	string			true	"ID (int)"
+// @Param			ReviewRequest	body	ReviewRequest	false	"Review"
+// @Tags			Review
+// @Produce		json
+// @Success		201	{object}	ReviewResponse
+// @Failure		500	{object}	ErrorMessage
+// @Failure		401	{object}	ErrorMessage
+// @Failure		404	{object}	ErrorMessage
+// @Failure		422	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/post/{id}/review [post]
+func (app *application) requestReviewHandler(w http.ResponseWriter, r *http.Request) {
+	app.review(w, r, data.ReviewRequest)
+}
+
+// @Summary		Approve a post
+// @Description	Approve a post in review, by someone other than who requested the review
+// @Param			id				path	string			true	"ID (int)"
+// @Param			ReviewRequest	body	ReviewRequest	false	"Review"
+// @Tags			Review
+// @Produce		json
+// @Success		201	{object}	ReviewResponse
+// @Failure		500	{object}	ErrorMessage
+// @Failure		401	{object}	ErrorMessage
+// @Failure		404	{object}	ErrorMessage
+// @Failure		422	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/post/{id}/review/approve [post]
+func (app *application) approveReviewHandler(w http.ResponseWriter, r *http.Request) {
+	app.review(w, r, data.ReviewApprove)
+}
+
+// @Summary		Reject a post
+// @Description	Send a post in review or approved back to draft, with a comment saying why
+// @Param			id				path	string			true	"ID (int)"
+// @Param			ReviewRequest	body	ReviewRequest	true	"Review"
+// @Tags			Review
+// @Produce		json
+// @Success		201	{object}	ReviewResponse
+// @Failure		500	{object}	ErrorMessage
+// @Failure		401	{object}	ErrorMessage
+// @Failure		404	{object}	ErrorMessage
+// @Failure		422	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/post/{id}/review/reject [post]
+func (app *application) rejectReviewHandler(w http.ResponseWriter, r *http.Request) {
+	app.review(w, r, data.ReviewReject)
+}
+
+// @Summary		Publish a post
+// @Description	Publish a post, which must be approved when approval is required
+// @Param			id				path	string			true	"ID (int)"
+// @Param			ReviewRequest	body	ReviewRequest	false	"Review"
+// @Tags			Review
+// @Produce		json
+// @Success		201	{object}	ReviewResponse
+// @Failure		500	{object}	ErrorMessage
+// @Failure		401	{object}	ErrorMessage
+// @Failure		404	{object}	ErrorMessage
+// @Failure		422	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/post/{id}/publish [post]
+func (app *application) publishHandler(w http.ResponseWriter, r *http.Request) {
+	app.review(w, r, data.ReviewPublish)
+}
+
+// @Summary		List reviews
+// @Description	List the reviews of a blog post, oldest first, with who took each action and when
+// @Param			id	path	string	true	"ID (int)"
+// @Tags			Review
+// @Produce		json
+// @Success		200	{object}	ReviewListResponse
+// @Failure		500	{object}	ErrorMessage
+// @Failure		401	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/post/{id}/review [get]
+func (app *application) listReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	rawValue := r.PathValue("id")
+	id, err := strconv.Atoi(rawValue)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to parse id value", "value", rawValue)
+		app.badRequestResponse(w, r, "unable to parse id value")
+		return
+	}
+
+	reviews, err := app.models.Reviews.ForPost(ctx, id)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to get reviews", "id", id, "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, ReviewListResponse{Data: reviews}, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to write response", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
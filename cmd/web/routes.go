@@ -6,7 +6,6 @@ import (
 	"github.com/justinas/alice"
 	httpSwagger "github.com/swaggo/http-swagger"
 	_ "textonly.islandwind.me/docs"
-	"textonly.islandwind.me/ui"
 )
 
 func (app *application) routes() http.Handler {
@@ -16,10 +15,13 @@ func (app *application) routes() http.Handler {
 	app.logger.Info("creating middleware chains")
 	// for routes that require authentication
 	protected := alice.New(app.basicAuth)
+	// for routes rendering the blog templates
+	blog := alice.New(app.csp(blogCSP), app.acceptLanguage)
+	// for the swagger documentation
+	swagger := alice.New(app.csp(swaggerCSP))
 
 	// static files
-	fileServer := http.FileServer(http.FS(ui.Files))
-	mux.Handle("GET /static/{filepath...}", fileServer)
+	mux.Handle("GET /static/{filepath...}", app.staticHandler())
 
 	// healthcheck
 	app.logger.Info("adding healthcheck route")
@@ -27,22 +29,42 @@ func (app *application) routes() http.Handler {
 
 	// swagger
 	app.logger.Info("adding swagger documentation routes")
-	mux.HandleFunc("GET /swagger/{any...}", httpSwagger.WrapHandler)
+	mux.Handle("GET /swagger/{any...}", swagger.Then(httpSwagger.WrapHandler))
+
+	// CSP violation reports
+	if app.config.Server.CSPReports {
+		mux.HandleFunc("POST /csp-report", app.cspReportHandler)
+	}
 
 	// UI
-	mux.HandleFunc("GET /", app.home)
-	mux.HandleFunc("GET /home", app.home)
-	mux.HandleFunc("GET /post/read/{id}", app.readPost)
-	mux.HandleFunc("GET /post", app.posts)
-	mux.HandleFunc("GET /about", app.about)
+	mux.Handle("GET /{$}", blog.ThenFunc(app.home))
+	mux.Handle("GET /home", blog.ThenFunc(app.home))
+	mux.Handle("GET /post/read/{id}", blog.ThenFunc(app.readPost))
+	mux.Handle("GET /post", blog.ThenFunc(app.posts))
+	mux.Handle("GET /about", blog.ThenFunc(app.about))
 	mux.HandleFunc("GET /feed.rss", app.feed)
+	mux.Handle("GET /series/{slug}", blog.ThenFunc(app.series))
+	mux.HandleFunc("GET /series/{slug}/feed.rss", app.seriesFeed)
+	mux.Handle("GET /{slug}", blog.ThenFunc(app.page))
 
 	// API
 	mux.HandleFunc("GET /api/post", app.listBlogHandler)
 	mux.HandleFunc("GET /api/post/{id}", app.getBlogHandler)
+	mux.HandleFunc("GET /api/post/{id}/related", app.relatedPostsHandler)
+	mux.Handle("GET /api/post/links/broken", protected.ThenFunc(app.brokenLinksHandler))
 	mux.Handle("POST /api/post", protected.ThenFunc(app.postBlogHandler))
 	mux.Handle("DELETE /api/post/{id}", protected.ThenFunc(app.deleteBlogHandler))
 	mux.Handle("PUT /api/post", protected.ThenFunc(app.updateBlogHandler))
+	mux.Handle("POST /api/post/rerender", protected.ThenFunc(app.rerenderHandler))
+	mux.Handle("POST /api/preview", protected.ThenFunc(app.previewHandler))
+	mux.Handle("GET /api/post/{id}/share", protected.ThenFunc(app.listShareLinksHandler))
+	mux.Handle("POST /api/post/{id}/share", protected.ThenFunc(app.postShareLinkHandler))
+	mux.Handle("GET /api/post/{id}/review", protected.ThenFunc(app.listReviewsHandler))
+	mux.Handle("POST /api/post/{id}/review", protected.ThenFunc(app.requestReviewHandler))
+	mux.Handle("POST /api/post/{id}/review/approve", protected.ThenFunc(app.approveReviewHandler))
+	mux.Handle("POST /api/post/{id}/review/reject", protected.ThenFunc(app.rejectReviewHandler))
+	mux.Handle("POST /api/post/{id}/publish", protected.ThenFunc(app.publishHandler))
+	mux.Handle("DELETE /api/share/{id}", protected.ThenFunc(app.revokeShareLinkHandler))
 
 	mux.HandleFunc("GET /api/social", app.listSocialHandler)
 	mux.HandleFunc("GET /api/social/{id}", app.getSocialHandler)
@@ -50,10 +72,31 @@ func (app *application) routes() http.Handler {
 	mux.Handle("DELETE /api/social/{id}", protected.ThenFunc(app.deleteSocialHandler))
 	mux.Handle("PUT /api/social", protected.ThenFunc(app.updateSocialHandler))
 
+	mux.HandleFunc("GET /api/page", app.listPageHandler)
+	mux.HandleFunc("GET /api/page/{id}", app.getPageHandler)
+	mux.Handle("POST /api/page", protected.ThenFunc(app.postPageHandler))
+	mux.Handle("DELETE /api/page/{id}", protected.ThenFunc(app.deletePageHandler))
+	mux.Handle("PUT /api/page", protected.ThenFunc(app.updatePageHandler))
+
+	mux.HandleFunc("GET /api/series", app.listSeriesHandler)
+	mux.HandleFunc("GET /api/series/{id}", app.getSeriesHandler)
+	mux.Handle("POST /api/series", protected.ThenFunc(app.postSeriesHandler))
+	mux.Handle("DELETE /api/series/{id}", protected.ThenFunc(app.deleteSeriesHandler))
+	mux.Handle("PUT /api/series", protected.ThenFunc(app.updateSeriesHandler))
+
+	mux.HandleFunc("GET /api/settings", app.getSiteSettingsHandler)
+	mux.Handle("PUT /api/settings", protected.ThenFunc(app.updateSiteSettingsHandler))
+
 	mux.HandleFunc("GET /api/user/{id}", app.getUserHandler)
 	mux.Handle("PUT /api/user", protected.ThenFunc(app.updateUserHandler))
 
-	standard := alice.New(app.recoverPanic, app.rateLimit, app.logRequest, secureHeaders)
+	mux.HandleFunc("GET /api/stats", app.statsHandler)
+
+	// API responses and static files get the most restrictive policy, which
+	// the blog and swagger routes replace with their own.
+	standard := alice.New(
+		app.recoverPanic, app.rateLimit, app.logRequest, secureHeaders, app.csp(apiCSP),
+	)
 
 	return standard.Then(mux)
 }
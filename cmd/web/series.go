@@ -0,0 +1,410 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"textonly.islandwind.me/internal/data"
+	"textonly.islandwind.me/internal/rss"
+	"textonly.islandwind.me/internal/utils"
+	"textonly.islandwind.me/internal/validator"
+)
+
+type SeriesResponse struct {
+	Metadata data.Metadata `json:"metadata"`
+	Data     data.Series   `json:"data"`
+}
+
+type SeriesListResponse struct {
+	Metadata data.Metadata  `json:"metadata"`
+	Data     []*data.Series `json:"data"`
+}
+
+type SeriesRequest struct {
+	ID          int    `json:"id,omitempty"`
+	Slug        string `json:"slug"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// PostIDs are the IDs of the parts of the series, in order.
+	PostIDs []int `json:"post_ids"`
+}
+
+type UpdateSeriesResponse struct {
+	Message      string `json:"message,omitempty"`
+	ID           int    `json:"id,omitempty"`
+	RowsAffected int64  `json:"rows_affected,omitempty"`
+}
+
+// @Summary		Get a series
+// @Description	Get a series of posts by ID, with its parts in order
+// @Param			id	path	string	true	"ID (int)"
+// @Tags			Series
+// @Produce		json
+// @Success		200	{object}	SeriesResponse
+// @Failure		500	{object}	ErrorMessage
+// @Failure		404	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/series/{id} [get]
+func (app *application) getSeriesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	rawValue := r.PathValue("id")
+	id, err := strconv.Atoi(rawValue)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to parse id value", "value", rawValue)
+		app.badRequestResponse(w, r, "unable to parse id value")
+		return
+	}
+
+	logger.InfoContext(ctx, "retrieving series", "id", id)
+	s, err := app.models.Series.Get(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			logger.ErrorContext(ctx, "an error occurred during retrieval", "error", err)
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !app.authenticated(r) {
+		s.HideUnlisted(0)
+	}
+
+	err = app.writeJSON(w, http.StatusOK, SeriesResponse{Metadata: data.Metadata{}, Data: *s}, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to write response", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// @Summary		List series
+// @Description	List series of posts, with their parts in order
+// @Tags			Series
+// @Produce		json
+// @Param			id			query		int		false	"id"
+// @Param			slug		query		string	false	"slug"
+// @Param			title		query		string	false	"title"
+// @Param			order_by	query		string	false	"order_by"
+// @Success		200			{object}	SeriesListResponse
+// @Failure		500			{object}	ErrorMessage
+// @Failure		422			{object}	ErrorMessage
+// @Failure		429			{object}	ErrorMessage
+// @Router			/api/series [get]
+func (app *application) listSeriesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	var input struct {
+		data.Filters `json:"filters,omitempty"`
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Filters.ID = app.readQueryParamToIntPtr(qs, "id", v)
+	input.Filters.Slug = app.readQueryString(qs, "slug", "")
+	input.Filters.Title = app.readQueryString(qs, "title", "")
+
+	input.Filters.Page = app.readQueryInt(qs, "page", 1, v)
+	input.Filters.PageSize = app.readQueryInt(qs, "page_size", 50_000, v)
+
+	input.Filters.OrderBy = app.readQueryCommaSeperatedString(qs, "order_by", "-created")
+	input.Filters.OrderBySafeList = []string{
+		"id", "slug", "title", "created", "last_update",
+		"-id", "-slug", "-title", "-created", "-last_update",
+	}
+
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	series, metadata, err := app.models.Series.GetAll(ctx, input.Filters)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to get series", "error", err, "input", input)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !app.authenticated(r) {
+		for _, s := range series {
+			s.HideUnlisted(0)
+		}
+	}
+
+	err = app.writeJSON(w, http.StatusOK, SeriesListResponse{Metadata: metadata, Data: series}, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "error writing response", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// @Summary		Post a series
+// @Description	Create a series of posts. A post can only be part of one series.
+//
+// @Param			SeriesRequest	body	SeriesRequest	true	"Push Series"
+//
+// @Tags			Series
+// @Produce		json
+// @Success		201	{object}	data.Series
+// @Failure		500	{object}	ErrorMessage
+// @Failure		401	{object}	ErrorMessage
+// @Failure		422	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/series [post]
+func (app *application) postSeriesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	var input SeriesRequest
+
+	err := app.readJSON(r, &input)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to parse JSON request body", "error", err)
+		app.badRequestResponse(w, r, "unable to parse JSON request body")
+		return
+	}
+
+	series := &data.Series{
+		Slug:        input.Slug,
+		Title:       input.Title,
+		Description: input.Description,
+		PostIDs:     input.PostIDs,
+	}
+
+	v := validator.New()
+	if data.ValidateSeries(v, series); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	s, err := app.models.Series.Insert(ctx, series)
+	if err != nil {
+		if app.seriesError(v, err) {
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+		logger.ErrorContext(ctx, "unable to create series", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, s, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to write response", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// @Summary		Update a series
+// @Description	Update a series of posts by ID, replacing its parts with the posts in order
+//
+// @Param			SeriesRequest	body	SeriesRequest	true	"Update Series"
+//
+// @Tags			Series
+// @Produce		json
+// @Success		200	{object}	UpdateSeriesResponse
+// @Failure		500	{object}	ErrorMessage
+// @Failure		401	{object}	ErrorMessage
+// @Failure		404	{object}	ErrorMessage
+// @Failure		422	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/series [put]
+func (app *application) updateSeriesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	var input SeriesRequest
+
+	err := app.readJSON(r, &input)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to parse JSON request body", "error", err)
+		app.badRequestResponse(w, r, "unable to parse JSON request body")
+		return
+	}
+
+	series := &data.Series{
+		ID:          input.ID,
+		Slug:        input.Slug,
+		Title:       input.Title,
+		Description: input.Description,
+		PostIDs:     input.PostIDs,
+	}
+
+	v := validator.New()
+	if data.ValidateSeries(v, series); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	rowsAffected, err := app.models.Series.Update(ctx, series)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		case app.seriesError(v, err):
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			logger.ErrorContext(ctx, "unable to update series", "error", err)
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(
+		w,
+		http.StatusOK,
+		UpdateSeriesResponse{Message: "series updated", RowsAffected: rowsAffected, ID: series.ID},
+		nil,
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to write response", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// @Summary		Delete a series
+// @Description	Delete a series by ID. Its posts are kept.
+// @Param			id	path	string	true	"ID (int)"
+// @Tags			Series
+// @Produce		json
+// @Success		200	{object}	UpdateSeriesResponse
+// @Failure		500	{object}	ErrorMessage
+// @Failure		401	{object}	ErrorMessage
+// @Failure		404	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/series/{id} [delete]
+func (app *application) deleteSeriesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	rawValue := r.PathValue("id")
+	id, err := strconv.Atoi(rawValue)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to parse id value", "value", rawValue)
+		app.badRequestResponse(w, r, "unable to parse id value")
+		return
+	}
+
+	rowsAffected, err := app.models.Series.Delete(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(
+		w,
+		http.StatusOK,
+		UpdateSeriesResponse{Message: "series deleted", RowsAffected: rowsAffected, ID: id},
+		nil,
+	)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// seriesError adds the validation error for errors caused by the input of a
+// series, and reports whether it was one.
+func (app *application) seriesError(v *validator.Validator, err error) bool {
+	switch {
+	case errors.Is(err, data.ErrDuplicateSlug):
+		v.AddError("slug", "a series with this slug already exists")
+	case errors.Is(err, data.ErrPostInOtherSeries), errors.Is(err, data.ErrUnknownPost):
+		v.AddError("post_ids", err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
+// seriesBySlug queries the series in the slug path value, responding with
+// not found if there is none.
+func (app *application) seriesBySlug(w http.ResponseWriter, r *http.Request) (*data.Series, bool) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	slug := r.PathValue("slug")
+	if !data.SlugRX.MatchString(slug) {
+		logger.InfoContext(ctx, "invalid slug", "slug", slug)
+		app.notFound(w)
+		return nil, false
+	}
+
+	logger.InfoContext(ctx, "querying series", "slug", slug)
+	series, err := app.models.Series.GetBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return nil, false
+	}
+	logger.InfoContext(ctx, "retrieved series", "id", series.ID, "posts", len(series.Posts))
+	series.HideUnlisted(0)
+
+	return series, true
+}
+
+func (app *application) series(w http.ResponseWriter, r *http.Request) {
+	series, ok := app.seriesBySlug(w, r)
+	if !ok {
+		return
+	}
+
+	app.render(r.Context(), w, http.StatusOK, "series.tmpl", &templateData{
+		Series: series,
+	})
+}
+
+func (app *application) seriesFeed(w http.ResponseWriter, r *http.Request) {
+	series, ok := app.seriesBySlug(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	// the parts of a series are listed without their content, which the feed
+	// has, so the newest parts are read in full
+	parts := series.Posts
+	if limit := app.config.Feed.Items; len(parts) > limit {
+		parts = parts[len(parts)-limit:]
+	}
+	posts := make([]*data.BlogPost, 0, len(parts))
+	for _, p := range parts {
+		bp, err := app.models.BlogPosts.Get(ctx, p.ID)
+		if err != nil {
+			logger.ErrorContext(ctx, "unable to query series post", "id", p.ID, "error", err)
+			app.serverError(w, err)
+			return
+		}
+		posts = append(posts, bp)
+	}
+
+	settings := app.siteSettings(ctx)
+	app.writeFeed(ctx, w, r, rss.Channel{
+		Title:       fmt.Sprintf("%s: %s", settings.Title, series.Title),
+		Link:        settings.URL("/series/" + series.Slug),
+		Description: cmp.Or(series.Description, settings.Tagline),
+		Language:    settings.Language,
+	}, posts)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"textonly.islandwind.me/internal/data"
+	"textonly.islandwind.me/internal/utils"
+	"textonly.islandwind.me/internal/validator"
+)
+
+type SiteSettingsResponse struct {
+	Metadata data.Metadata     `json:"metadata"`
+	Data     data.SiteSettings `json:"data"`
+}
+
+type SiteSettingsRequest struct {
+	Title         string `json:"title"`
+	Tagline       string `json:"tagline"`
+	BaseURL       string `json:"base_url"`
+	DefaultAuthor string `json:"default_author"`
+	Language      string `json:"language"`
+	FooterText    string `json:"footer_text"`
+}
+
+type UpdateSiteSettingsResponse struct {
+	Message      string `json:"message,omitempty"`
+	RowsAffected int64  `json:"rows_affected,omitempty"`
+}
+
+// @Summary		Get site settings
+// @Description	Get the site wide settings
+// @Tags			Settings
+// @Produce		json
+// @Success		200	{object}	SiteSettingsResponse
+// @Failure		500	{object}	ErrorMessage
+// @Failure		404	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/settings [get]
+func (app *application) getSiteSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	logger.InfoContext(ctx, "retrieving site settings")
+	s, err := app.models.Settings.Get(ctx)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			logger.ErrorContext(ctx, "an error occurred during retrieval", "error", err)
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(
+		w,
+		http.StatusOK,
+		SiteSettingsResponse{Metadata: data.Metadata{}, Data: *s},
+		nil,
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to write response", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// @Summary		Update site settings
+// @Description	Update the site wide settings
+//
+// @Param			SiteSettingsRequest	body	SiteSettingsRequest	true	"Update Site Settings"
+//
+// @Tags			Settings
+// @Produce		json
+// @Success		200	{object}	UpdateSiteSettingsResponse
+// @Failure		500	{object}	ErrorMessage
+// @Failure		401	{object}	ErrorMessage
+// @Failure		422	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/settings [put]
+func (app *application) updateSiteSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	var input SiteSettingsRequest
+	err := app.readJSON(r, &input)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to parse JSON request body", "error", err)
+		app.badRequestResponse(w, r, "unable to parse JSON request body")
+		return
+	}
+
+	s := &data.SiteSettings{
+		Title:         input.Title,
+		Tagline:       input.Tagline,
+		BaseURL:       input.BaseURL,
+		DefaultAuthor: input.DefaultAuthor,
+		Language:      input.Language,
+		FooterText:    input.FooterText,
+	}
+
+	v := validator.New()
+	if data.ValidateSiteSettings(v, s); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	rowsAffected, err := app.models.Settings.Update(ctx, s)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to update site settings", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(
+		w,
+		http.StatusOK,
+		UpdateSiteSettingsResponse{Message: "site settings updated", RowsAffected: rowsAffected},
+		nil,
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to write response", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
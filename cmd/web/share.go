@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"textonly.islandwind.me/internal/data"
+	"textonly.islandwind.me/internal/share"
+	"textonly.islandwind.me/internal/utils"
+	"textonly.islandwind.me/internal/validator"
+)
+
+// shareParam is the query parameter of the post URL holding the token of a
+// share link.
+const shareParam = "share"
+
+type ShareLinkRequest struct {
+	// Expires is how long the link is valid for, as in 48h. The configured
+	// default is used when it is empty.
+	Expires string `json:"expires,omitempty"`
+}
+
+// SharedLink is a share link with its token and the URL of the post it
+// grants access to.
+type SharedLink struct {
+	*data.ShareLink
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+type ShareLinkResponse struct {
+	Data SharedLink `json:"data"`
+}
+
+type ShareLinkListResponse struct {
+	Data []SharedLink `json:"data"`
+}
+
+type RevokeShareLinkResponse struct {
+	Message      string `json:"message,omitempty"`
+	ID           int    `json:"id,omitempty"`
+	RowsAffected int64  `json:"rows_affected,omitempty"`
+}
+
+// newShareSecret returns a random secret to sign share links with when none
+// is configured.
+func newShareSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// sharedLink signs the link and builds the URL of the post with its token.
+func (app *application) sharedLink(ctx context.Context, l *data.ShareLink) SharedLink {
+	token := share.Sign([]byte(app.config.Posts.Share.Secret), share.Claims{
+		LinkID:  l.ID,
+		PostID:  l.PostID,
+		Expires: l.Expires,
+	})
+	path := fmt.Sprintf("/post/read/%d?%s=%s", l.PostID, shareParam, url.QueryEscape(token))
+
+	return SharedLink{ShareLink: l, Token: token, URL: app.siteSettings(ctx).URL(path)}
+}
+
+// validShareToken reports whether the token belongs to an active share link
+// of the post.
+func (app *application) validShareToken(ctx context.Context, token string, postID int) bool {
+	logger := utils.LoggerFromContext(ctx)
+
+	if token == "" {
+		return false
+	}
+
+	now := time.Now()
+	claims, err := share.Verify([]byte(app.config.Posts.Share.Secret), token, now)
+	if err != nil {
+		logger.InfoContext(ctx, "share token rejected", "error", err)
+		return false
+	}
+	if claims.PostID != postID {
+		logger.InfoContext(ctx, "share token is for another post", "post_id", claims.PostID)
+		return false
+	}
+
+	// the link may have been revoked since it was signed
+	link, err := app.models.Shares.Get(ctx, claims.LinkID)
+	if err != nil {
+		if !errors.Is(err, data.ErrRecordNotFound) {
+			logger.ErrorContext(ctx, "unable to query share link", "error", err)
+		}
+		return false
+	}
+
+	return link.PostID == postID && link.Active(now)
+}
+
+// readShareExpiry parses how long a share link is valid for.
+func (app *application) readShareExpiry(v *validator.Validator, raw string) time.Duration {
+	cfg := app.config.Posts.Share
+	if raw == "" {
+		return cfg.DefaultExpiry
+	}
+
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		v.AddError("expires", "must be a duration, as in 48h")
+		return 0
+	}
+	v.Check(d > 0, "expires", "must be greater than zero")
+	v.Check(d <= cfg.MaxExpiry, "expires", fmt.Sprintf("must not be more than %s", cfg.MaxExpiry))
+
+	return d
+}
+
+// @Summary		Share a blog post
+// @Description	Create a signed, expiring link to a blog post, through which private posts can be read
+// @Param			id					path	string				true	"ID (int)"
+// @Param			ShareLinkRequest	body	ShareLinkRequest	false	"Share Link"
+// @Tags			Blog Post
+// @Produce		json
+// @Success		201	{object}	ShareLinkResponse
+// @Failure		500	{object}	ErrorMessage
+// @Failure		401	{object}	ErrorMessage
+// @Failure		404	{object}	ErrorMessage
+// @Failure		422	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/post/{id}/share [post]
+func (app *application) postShareLinkHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	rawValue := r.PathValue("id")
+	id, err := strconv.Atoi(rawValue)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to parse id value", "value", rawValue)
+		app.badRequestResponse(w, r, "unable to parse id value")
+		return
+	}
+
+	var input ShareLinkRequest
+	if r.ContentLength != 0 {
+		if err = app.readJSON(r, &input); err != nil {
+			logger.ErrorContext(ctx, "unable to parse JSON request body", "error", err)
+			app.badRequestResponse(w, r, "unable to parse JSON request body")
+			return
+		}
+	}
+
+	v := validator.New()
+	expires := app.readShareExpiry(v, input.Expires)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	link := &data.ShareLink{PostID: id, Expires: time.Now().UTC().Add(expires).Truncate(time.Second)}
+	if err = app.models.Shares.Insert(ctx, link); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			logger.ErrorContext(ctx, "unable to create share link", "error", err)
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(
+		w, http.StatusCreated, ShareLinkResponse{Data: app.sharedLink(ctx, link)}, nil,
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to write response", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// @Summary		List share links
+// @Description	List the share links of a blog post, newest first, including expired and revoked ones
+// @Param			id	path	string	true	"ID (int)"
+// @Tags			Blog Post
+// @Produce		json
+// @Success		200	{object}	ShareLinkListResponse
+// @Failure		500	{object}	ErrorMessage
+// @Failure		401	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/post/{id}/share [get]
+func (app *application) listShareLinksHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	rawValue := r.PathValue("id")
+	id, err := strconv.Atoi(rawValue)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to parse id value", "value", rawValue)
+		app.badRequestResponse(w, r, "unable to parse id value")
+		return
+	}
+
+	links, err := app.models.Shares.ForPost(ctx, id)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query share links", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	shared := make([]SharedLink, 0, len(links))
+	for _, l := range links {
+		shared = append(shared, app.sharedLink(ctx, l))
+	}
+
+	err = app.writeJSON(w, http.StatusOK, ShareLinkListResponse{Data: shared}, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to write response", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
+
+// @Summary		Revoke a share link
+// @Description	Revoke a share link by ID, which stops granting access at once
+// @Param			id	path	string	true	"ID (int)"
+// @Tags			Blog Post
+// @Produce		json
+// @Success		200	{object}	RevokeShareLinkResponse
+// @Failure		500	{object}	ErrorMessage
+// @Failure		401	{object}	ErrorMessage
+// @Failure		404	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/share/{id} [delete]
+func (app *application) revokeShareLinkHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	rawValue := r.PathValue("id")
+	id, err := strconv.Atoi(rawValue)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to parse id value", "value", rawValue)
+		app.badRequestResponse(w, r, "unable to parse id value")
+		return
+	}
+
+	rowsAffected, err := app.models.Shares.Revoke(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(
+		w,
+		http.StatusOK,
+		RevokeShareLinkResponse{Message: "share link revoked", RowsAffected: rowsAffected, ID: id},
+		nil,
+	)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
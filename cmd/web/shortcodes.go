@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"textonly.islandwind.me/internal/data"
+	"textonly.islandwind.me/internal/markdown"
+	"textonly.islandwind.me/internal/validator"
+)
+
+// registerShortcodes adds the shortcodes that need the models to the markdown
+// renderers.
+func (app *application) registerShortcodes() {
+	params := []string{"limit", "title", "featured"}
+	params = append(params, app.config.Posts.Meta.FilterableKeys()...)
+	app.markdown.RegisterDynamicShortcode("postlist", app.postListShortcode, params...)
+}
+
+// postListShortcode lists posts as links, newest first. Posts can be limited
+// to featured ones with featured=true, to titles containing a text with title,
+// and by the filterable custom fields, as in tag=go with the tag field of the
+// default configuration. Posts have no tags of their own. The number of posts
+// is set with limit, which is 5 by default.
+func (app *application) postListShortcode(sc markdown.Shortcode) (string, error) {
+	limit, err := strconv.Atoi(sc.Param("limit", "5"))
+	if err != nil || limit < 1 || limit > 100 {
+		return "", fmt.Errorf("limit must be a number between 1 and 100")
+	}
+
+	filters := data.Filters{
+		Page:       1,
+		PageSize:   limit,
+		Title:      sc.Param("title", ""),
+		Visibility: data.VisibilityPublic,
+		Status:     data.StatusPublished,
+		OrderBy:    []string{"-created"},
+	}
+	if featured, ok := sc.Params["featured"]; ok {
+		isFeatured, err := strconv.ParseBool(featured)
+		if err != nil {
+			return "", fmt.Errorf("featured must be true or false")
+		}
+		filters.Featured = &isFeatured
+	}
+	for _, key := range app.config.Posts.Meta.FilterableKeys() {
+		raw, ok := sc.Params[key]
+		if !ok {
+			continue
+		}
+		value, err := app.config.Posts.Meta.ParseFilter(key, raw)
+		if err != nil {
+			return "", fmt.Errorf("%s %s", key, err)
+		}
+		if filters.Meta == nil {
+			filters.Meta = map[string]any{}
+		}
+		filters.Meta[key] = value
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	posts, _, err := app.models.BlogPosts.GetAll(ctx, filters)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(`<ul class="post-list">`)
+	for _, post := range posts {
+		fmt.Fprintf(
+			&b,
+			`<li><a href="/post/read/%d">%s</a></li>`,
+			post.ID,
+			html.EscapeString(post.Title),
+		)
+	}
+	b.WriteString(`</ul>`)
+
+	return b.String(), nil
+}
+
+// validateShortcodes checks that the shortcodes in the markdown can be
+// expanded by the named renderer.
+func (app *application) validateShortcodes(v *validator.Validator, key, renderer, input string) {
+	err := app.markdown.CheckShortcodes(renderer, []byte(input))
+	switch {
+	case errors.Is(err, markdown.ErrUnknownShortcode):
+		v.AddError(key, fmt.Sprintf(
+			"%s; available shortcodes are %s",
+			err, strings.Join(app.markdown.Shortcodes(), ", "),
+		))
+	case err != nil:
+		v.AddError(key, err.Error())
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+
+	"textonly.islandwind.me/cmd/web/config"
+	"textonly.islandwind.me/internal/assert"
+	"textonly.islandwind.me/internal/data"
+	"textonly.islandwind.me/internal/markdown"
+	"textonly.islandwind.me/internal/validator"
+)
+
+func TestValidateShortcodes(t *testing.T) {
+	renderers, err := markdown.NewRegistry(map[string]markdown.Options{
+		"commonmark": {Engine: markdown.EngineCommonMark, Extensions: []string{markdown.ExtShortcodes}},
+	}, "commonmark")
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+	app := &application{
+		markdown: renderers,
+		config: &config.Config{Posts: &config.PostsConfig{
+			Meta: data.MetaSchema{
+				"tag":   {Type: data.MetaString, Filterable: true},
+				"cover": {Type: data.MetaURL},
+			},
+		}},
+	}
+	app.registerShortcodes()
+
+	tests := []struct {
+		name  string
+		input string
+		valid bool
+	}{
+		{"Filters", `{{< postlist limit=3 title=go featured=true >}}`, true},
+		{"Filterable field", `{{< postlist tag=go >}}`, true},
+		{"Other field", `{{< postlist cover=https://example.com >}}`, false},
+		{"Unknown parameter", `{{< postlist series=go >}}`, false},
+		{"Unknown shortcode", `{{< youtube abc >}}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			app.validateShortcodes(v, "post", "", tt.input)
+			assert.Equal(t, v.Valid(), tt.valid)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http"
+
+	"textonly.islandwind.me/internal/data"
+	"textonly.islandwind.me/internal/utils"
+)
+
+type StatsResponse struct {
+	Data data.SiteStats `json:"data"`
+}
+
+// @Summary		Get content statistics
+// @Description	Get the number of posts and words in total and per month, and the average number of days between posts
+// @Tags			Stats
+// @Produce		json
+// @Success		200	{object}	StatsResponse
+// @Failure		500	{object}	ErrorMessage
+// @Failure		429	{object}	ErrorMessage
+// @Router			/api/stats [get]
+func (app *application) statsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.LoggerFromContext(ctx)
+
+	stats, err := app.models.BlogPosts.Stats(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query stats", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, StatsResponse{Data: *stats}, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to write response", "error", err)
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+}
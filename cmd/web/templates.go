@@ -1,28 +1,58 @@
 package main
 
 import (
+	"fmt"
 	"html/template"
 	"io/fs"
 	"log/slog"
 	"path/filepath"
+	"slices"
 	"time"
 
-	"github.com/russross/blackfriday/v2"
 	"textonly.islandwind.me/internal/data"
-	"textonly.islandwind.me/ui"
+	"textonly.islandwind.me/internal/i18n"
+	"textonly.islandwind.me/internal/markdown"
+	"textonly.islandwind.me/internal/sanitize"
 )
 
 type templateData struct {
-	BlogPost  *data.BlogPost
-	BlogPosts []*data.BlogPost
-	Socials   []*data.Social
-	User      *data.User
+	BlogPost      *data.BlogPost
+	BlogPosts     []*data.BlogPost
+	FeaturedPosts []*data.BlogPost
+	Backlinks     []*data.BlogPost
+	RelatedPosts  []*data.BlogPost
+	Page          *data.Page
+	Series        *data.Series
+	NavPages      []*data.Page
+	Settings      *data.SiteSettings
+	Socials       []*data.Social
+	User          *data.User
+	Nonce         string
+	// Translations are the other posts in the translation group of the post.
+	Translations []*data.BlogPost
+	// Kind is the kind of post the listed posts are filtered by.
+	Kind string
+	// Language is the language the listed posts are filtered by, and
+	// Languages the ones posts are written in.
+	Language  string
+	Languages []string
+	// Lang is the language of the page, which the user interface strings are
+	// translated into with the message catalogs.
+	Lang string
+	i18n *i18n.Bundle
+	// Content is the rendered markdown of the page, and TOC the headings
+	// listed in its table of contents.
+	Content template.HTML
+	TOC     []markdown.Heading
 }
 
-func newTemplateCache() (map[string]*template.Template, error) {
+func newTemplateCache(
+	files fs.FS,
+	funcs template.FuncMap,
+) (map[string]*template.Template, error) {
 	cache := map[string]*template.Template{}
 
-	pages, err := fs.Glob(ui.Files, "html/pages/*.tmpl")
+	pages, err := fs.Glob(files, "html/pages/*.tmpl")
 	if err != nil {
 		slog.Error("an error occurred while walking template directory", "error", err)
 		return nil, err
@@ -37,7 +67,10 @@ func newTemplateCache() (map[string]*template.Template, error) {
 			page,
 		}
 
-		ts, err := template.New(name).Funcs(functions).ParseFS(ui.Files, patterns...)
+		ts, err := template.New(name).
+			Funcs(functions).
+			Funcs(funcs).
+			ParseFS(files, patterns...)
 		if err != nil {
 			return nil, err
 		}
@@ -46,16 +79,45 @@ func newTemplateCache() (map[string]*template.Template, error) {
 		cache[name] = ts
 	}
 
-	feedTemplate, err := template.ParseFS(ui.Files, "xml/feed.tmpl")
+	return cache, nil
+}
+
+// requiredTemplates lists the blocks every page template has to define,
+// either itself or through the base and partial templates.
+var requiredTemplates = []string{"base", "nav", "title", "main"}
+
+// validateTemplates parses the templates in files and reports every page
+// template missing one of the required blocks. Parsing errors are returned
+// as they are.
+func (app *application) validateTemplates(files fs.FS) ([]string, error) {
+	assets, err := newAssetManifest(files)
 	if err != nil {
-		slog.Error("an error occurred when collecting RSS feed template", "error", err)
 		return nil, err
 	}
-	slog.Info("parsed RSS feed template")
 
-	cache["feed"] = feedTemplate
+	cache, err := newTemplateCache(files, app.templateFunctions(assets))
+	if err != nil {
+		return nil, err
+	}
 
-	return cache, nil
+	if _, err = i18n.Load(files); err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	for name, ts := range cache {
+		for _, block := range requiredTemplates {
+			if ts.Lookup(block) == nil {
+				problems = append(
+					problems,
+					fmt.Sprintf("html/pages/%s: missing define %q", name, block),
+				)
+			}
+		}
+	}
+	slices.Sort(problems)
+
+	return problems, nil
 }
 
 func humanDate(t time.Time) string {
@@ -65,11 +127,70 @@ func humanDate(t time.Time) string {
 	return t.UTC().Format("2006-01-02 15:04")
 }
 
-func markdownToHTML(input string) template.HTML {
-	return template.HTML(blackfriday.Run([]byte(input)))
+// renderMarkdown renders the markdown with the named renderer and sanitizes
+// the result with the policy of the content type. It returns the HTML and the
+// outline of its headings. An empty renderer name selects the default
+// renderer. If the markdown cannot be rendered, the escaped source is shown
+// instead.
+func (app *application) renderMarkdown(
+	contentType, renderer, input string,
+) (template.HTML, []markdown.Heading) {
+	doc, err := app.markdown.Render(renderer, []byte(input))
+	if err != nil {
+		app.logger.Error("unable to render markdown", "renderer", renderer, "error", err)
+		return template.HTML("<pre>" + template.HTMLEscapeString(input) + "</pre>"), nil
+	}
+
+	return template.HTML(app.sanitizer.Sanitize(contentType, doc.HTML)), doc.Outline
+}
+
+// markdownToHTML renders and sanitizes the markdown like renderMarkdown,
+// without the outline.
+func (app *application) markdownToHTML(contentType, renderer, input string) template.HTML {
+	html, _ := app.renderMarkdown(contentType, renderer, input)
+	return html
+}
+
+// tableOfContents returns the outline if it has enough headings to be shown
+// as a table of contents.
+func (app *application) tableOfContents(outline []markdown.Heading) []markdown.Heading {
+	minHeadings := app.config.Markdown.TOCMinHeadings
+	if minHeadings < 1 || len(outline) < minHeadings {
+		return nil
+	}
+
+	return outline
+}
+
+// templateFunctions returns the template functions that depend on the
+// application, in addition to the ones in functions.
+func (app *application) templateFunctions(assets *assetManifest) template.FuncMap {
+	return template.FuncMap{
+		"asset": assets.URL,
+		"highlightCSS": func() string {
+			if app.config == nil || app.config.Markdown.HighlightStyle == "" {
+				return ""
+			}
+			return assets.URL("css/highlight/" + app.config.Markdown.HighlightStyle + ".css")
+		},
+		"markdown": func(contentType, input string) template.HTML {
+			return app.markdownToHTML(contentType, "", input)
+		},
+		"markdownToHTML": func(input string) template.HTML {
+			return app.markdownToHTML(sanitize.ContentPost, "", input)
+		},
+		"renderPost": func(bp *data.BlogPost) template.HTML {
+			// the stored HTML was sanitized when the post was saved
+			if bp.HTML != "" {
+				return template.HTML(bp.HTML)
+			}
+			return app.markdownToHTML(sanitize.ContentPost, bp.Renderer, bp.Post)
+		},
+	}
 }
 
 var functions = template.FuncMap{
-	"humanDate":      humanDate,
-	"markdownToHTML": markdownToHTML,
+	"card":      card,
+	"humanDate": humanDate,
+	"nonce":     nonceAttr,
 }
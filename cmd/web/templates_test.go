@@ -1,10 +1,22 @@
 package main
 
 import (
+	"context"
+	"html/template"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"textonly.islandwind.me/internal/assert"
+	"textonly.islandwind.me/internal/data"
+	"textonly.islandwind.me/internal/i18n"
+	"textonly.islandwind.me/internal/markdown"
+	"textonly.islandwind.me/internal/sanitize"
+	"textonly.islandwind.me/ui"
 )
 
 func TestHumanDate(t *testing.T) {
@@ -37,3 +49,264 @@ func TestHumanDate(t *testing.T) {
 		})
 	}
 }
+
+func TestNewTemplateCache(t *testing.T) {
+	assets, err := newAssetManifest(ui.Files)
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+
+	cache, err := newTemplateCache(ui.Files, newTestApplication(t).templateFunctions(assets))
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+
+	for _, name := range []string{"home.tmpl", "posts.tmpl", "read.tmpl", "about.tmpl", "series.tmpl"} {
+		if _, ok := cache[name]; !ok {
+			t.Errorf("Expected template '%s' in cache", name)
+		}
+	}
+}
+
+func TestValidateTemplates(t *testing.T) {
+	theme := t.TempDir()
+	err := os.MkdirAll(filepath.Join(theme, "html", "pages"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(
+		filepath.Join(theme, "html", "pages", "now.tmpl"),
+		[]byte(`{{ define "title" }}Now{{ end }}`),
+		0o644,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	problems, err := newTestApplication(t).validateTemplates(ui.Theme(theme))
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+
+	assert.Equal(t, len(problems), 1)
+	if len(problems) == 1 {
+		assert.Equal(t, problems[0], `html/pages/now.tmpl: missing define "main"`)
+	}
+
+	problems, err = newTestApplication(t).validateTemplates(ui.Files)
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+	assert.Equal(t, len(problems), 0)
+}
+
+func TestRenderTemplates(t *testing.T) {
+	app := newTestApplication(t)
+
+	created := time.Date(2024, 3, 17, 10, 15, 0, 0, time.UTC)
+	original := 1
+	noindex := `<meta name="robots" content="noindex">`
+	readPost := func(visibility, status string) *templateData {
+		return &templateData{
+			Settings: data.DefaultSiteSettings(),
+			BlogPost: &data.BlogPost{
+				ID: 1, Kind: data.KindArticle, Title: "Hello", Visibility: visibility, Status: status,
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		page     string
+		template string
+		data     *templateData
+		want     []string
+		unwanted []string
+	}{
+		{
+			name: "Nonce",
+			page: "home.tmpl",
+			data: &templateData{Settings: data.DefaultSiteSettings(), Nonce: "bm9uY2U="},
+			want: []string{`<script nonce="bm9uY2U=" src="/static/js/activePage.`},
+		},
+		{
+			name: "Post kinds",
+			page: "posts.tmpl",
+			data: &templateData{
+				Settings: data.DefaultSiteSettings(),
+				BlogPosts: []*data.BlogPost{
+					{ID: 1, Kind: data.KindArticle, Title: "An article", Lead: "The lead", Created: &created},
+					{ID: 2, Kind: data.KindNote, HTML: "<p>A short note</p>", Created: &created},
+					{
+						ID:      3,
+						Kind:    data.KindLink,
+						LinkURL: "https://www.example.com/page",
+						Quote:   "A quote",
+						Created: &created,
+					},
+				},
+				Kind: data.KindNote,
+				Lang: "en",
+			},
+			want: []string{
+				`<h5 class="card-title">An article</h5>`,
+				`<div class="card-text mt-2"><p>A short note</p></div>`,
+				`<a href="https://www.example.com/page" rel="external">example.com</a>`,
+				`<p>A quote</p>`,
+				`<a class="nav-link active" href="/post?kind=note">Notes</a>`,
+				`<span title="2024-03-17 10:15" class="text-muted">March 17, 2024</span>`,
+			},
+		},
+		{
+			name: "Translations",
+			page: "read.tmpl",
+			data: &templateData{
+				Settings: data.DefaultSiteSettings(),
+				BlogPost: &data.BlogPost{
+					ID: 2, Kind: data.KindArticle, Title: "Hei", Language: "nb", TranslationOf: &original,
+				},
+				Translations: []*data.BlogPost{
+					{ID: 1, Kind: data.KindArticle, Title: "Hello", Language: "en"},
+				},
+				Lang: "nb",
+			},
+			want: []string{
+				`<html lang="nb">`,
+				`<link rel="alternate" hreflang="nb" href="http://localhost:4000/post/read/2">`,
+				`<link rel="alternate" hreflang="en" href="http://localhost:4000/post/read/1">`,
+				`<a href="/post/read/1" hreflang="en" lang="en" class="ms-2">English</a>`,
+				`<a class="nav-link" href="/post">Innlegg</a>`,
+			},
+		},
+		{
+			name:     "Public",
+			page:     "read.tmpl",
+			data:     readPost(data.VisibilityPublic, data.StatusPublished),
+			unwanted: []string{noindex},
+		},
+		{
+			name: "Unlisted",
+			page: "read.tmpl",
+			data: readPost(data.VisibilityUnlisted, data.StatusPublished),
+			want: []string{noindex, "This post is unlisted."},
+		},
+		{
+			name: "Private",
+			page: "read.tmpl",
+			data: readPost(data.VisibilityPrivate, data.StatusPublished),
+			want: []string{noindex, "This post is private"},
+		},
+		{
+			name: "Draft",
+			page: "read.tmpl",
+			data: readPost(data.VisibilityPublic, data.StatusDraft),
+			want: []string{noindex, "This post is not published yet."},
+		},
+		{
+			name: "Approved",
+			page: "read.tmpl",
+			data: readPost(data.VisibilityPublic, data.StatusApproved),
+			want: []string{noindex, "This post is not published yet."},
+		},
+		{
+			name:     "Preview fragment",
+			page:     "read.tmpl",
+			template: "main",
+			data: &templateData{
+				Settings: data.DefaultSiteSettings(),
+				BlogPost: &data.BlogPost{Kind: data.KindArticle, Title: "Hello"},
+				Content:  template.HTML("<p>The text of the post.</p>"),
+			},
+			want:     []string{"<p>The text of the post.</p>"},
+			unwanted: []string{"<html"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := tt.template
+			if name == "" {
+				name = "base"
+			}
+			tt.data.i18n = app.i18n
+
+			buf := new(strings.Builder)
+			err := app.templateCache[tt.page].ExecuteTemplate(buf, name, tt.data)
+			if err != nil {
+				t.Fatalf("Expected nil but got '%v'", err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("Expected '%s' in '%s'", want, buf.String())
+				}
+			}
+			for _, unwanted := range tt.unwanted {
+				if strings.Contains(buf.String(), unwanted) {
+					t.Errorf("Expected no '%s' in '%s'", unwanted, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestPageLanguage(t *testing.T) {
+	bundle := newTestApplication(t).i18n
+	settings := &data.SiteSettings{Language: "nb"}
+
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"Catalog", "en-US,en;q=0.9", "en"},
+		{"Region", "nb-NO", "nb"},
+		{"No catalog", "de", "nb"},
+		{"No header", "", "nb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithLanguages(context.Background(), i18n.ParseAcceptLanguage(tt.header))
+			assert.Equal(t, pageLanguage(ctx, bundle, settings), tt.want)
+		})
+	}
+}
+
+// newTestApplication returns an application with the legacy renderer, the
+// default sanitizer policies, and the templates and message catalogs of the
+// embedded files.
+func newTestApplication(t *testing.T) *application {
+	t.Helper()
+
+	sanitizer, err := sanitize.New(nil)
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+
+	renderers, err := markdown.NewRegistry(nil, "")
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+
+	app := &application{
+		logger:    slog.New(slog.NewJSONHandler(io.Discard, nil)),
+		markdown:  renderers,
+		sanitizer: sanitizer,
+	}
+
+	assets, err := newAssetManifest(ui.Files)
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+	app.templateCache, err = newTemplateCache(ui.Files, app.templateFunctions(assets))
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+	app.i18n, err = i18n.Load(ui.Files)
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+
+	return app
+}
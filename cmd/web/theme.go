@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"textonly.islandwind.me/internal/i18n"
+	"textonly.islandwind.me/internal/markdown"
+)
+
+// template returns the named template from the template cache. The cache
+// may be replaced at any time while a theme is being watched.
+func (app *application) template(name string) (*template.Template, bool) {
+	app.templateMu.RLock()
+	defer app.templateMu.RUnlock()
+
+	ts, ok := app.templateCache[name]
+	return ts, ok
+}
+
+// reloadTemplates fingerprints the static files, parses the templates and
+// loads the message catalogs again, replacing the template cache. The current
+// cache is kept if the templates or catalogs cannot be parsed.
+func (app *application) reloadTemplates() error {
+	assets, err := newAssetManifest(app.files)
+	if err != nil {
+		return err
+	}
+
+	cache, err := newTemplateCache(app.files, app.templateFunctions(assets))
+	if err != nil {
+		return err
+	}
+
+	bundle, err := i18n.Load(app.files)
+	if err != nil {
+		return err
+	}
+
+	app.templateMu.Lock()
+	app.assets = assets
+	app.templateCache = cache
+	app.i18n = bundle
+	app.templateMu.Unlock()
+
+	return nil
+}
+
+// watchTheme reloads the templates whenever a file in the html, static or i18n
+// directory of the theme changes.
+func (app *application) watchTheme(path string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	dirs := []string{"html", "html/pages", "html/partials", "static", "static/css", "static/js", "i18n"}
+	for _, dir := range dirs {
+		dir = filepath.Join(path, dir)
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return err
+		}
+	}
+
+	app.logger.Info("watching theme for changes", "path", path)
+	go func() {
+		defer watcher.Close()
+
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Has(fsnotify.Chmod) {
+					continue
+				}
+
+				app.logger.Info("theme changed, reloading templates", "file", event.Name)
+				if err := app.reloadTemplates(); err != nil {
+					app.logger.Error("unable to reload templates", "error", err)
+					continue
+				}
+				app.logger.Info("templates reloaded")
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				app.logger.Error("error while watching theme", "error", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// validateTheme prints the problems found in the templates and returns the
+// exit code for the validate-theme command.
+func (app *application) validateTheme(files fs.FS) int {
+	problems, err := app.validateTemplates(files)
+	if err != nil {
+		fmt.Printf("unable to parse templates: %s\n", err)
+		return 1
+	}
+
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if len(problems) > 0 {
+		return 1
+	}
+
+	fmt.Println("theme is valid")
+	return 0
+}
+
+// writeHighlightCSS prints the stylesheet for highlighted code blocks in the
+// named style and returns the exit code for the highlight-css command.
+func writeHighlightCSS(style string) int {
+	if style == "" {
+		fmt.Println("usage: web highlight-css <style>")
+		fmt.Printf("styles: %s\n", strings.Join(markdown.HighlightStyles(), ", "))
+		return 1
+	}
+
+	if err := markdown.WriteHighlightCSS(os.Stdout, style); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	return 0
+}
@@ -15,71 +15,32 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/post/": {
-            "get": {
-                "description": "List blog posts",
+        "/api/page": {
+            "put": {
+                "description": "Update a page by ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Blog Post"
+                    "Page"
                 ],
-                "summary": "List blog posts",
+                "summary": "Update a page",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "id",
-                        "name": "id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "title",
-                        "name": "title",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "lead",
-                        "name": "lead",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "created_from",
-                        "name": "created_from",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "created_to",
-                        "name": "created_to",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "last_updated_from",
-                        "name": "last_updated_from",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "last_updated_to",
-                        "name": "last_updated_to",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "order_by",
-                        "name": "order_by",
-                        "in": "query"
+                        "description": "Update Page",
+                        "name": "data.Page",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/data.Page"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.BlogPostListResponse"
+                            "$ref": "#/definitions/main.UpdatePageResponse"
                         }
                     },
                     "401": {
@@ -94,6 +55,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.ErrorMessage"
                         }
                     },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
                     "429": {
                         "description": "Too Many Requests",
                         "schema": {
@@ -107,32 +74,32 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/post/{id}": {
-            "get": {
-                "description": "Get a blog post by ID",
+            },
+            "post": {
+                "description": "Post a page",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Blog Post"
+                    "Page"
                 ],
-                "summary": "Get a blog post",
+                "summary": "Post a page",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID (int)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Push Page",
+                        "name": "PagePostRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.PagePostRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/main.BlogPostResponse"
+                            "$ref": "#/definitions/data.Page"
                         }
                     },
                     "401": {
@@ -141,8 +108,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.ErrorMessage"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorMessage"
                         }
@@ -160,32 +127,55 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update a blog post by ID",
+            }
+        },
+        "/api/page/": {
+            "get": {
+                "description": "List pages",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Blog Post"
+                    "Page"
                 ],
-                "summary": "Update a blog post",
+                "summary": "List pages",
                 "parameters": [
                     {
-                        "description": "Update Blog Post",
-                        "name": "data.BlogPost",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/data.BlogPost"
-                        }
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "slug",
+                        "name": "slug",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "title",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "visible, needs credentials",
+                        "name": "visible",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "order_by",
+                        "name": "order_by",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.UpdateBlogResponse"
+                            "$ref": "#/definitions/main.PageListResponse"
                         }
                     },
                     "401": {
@@ -213,32 +203,32 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Post a blog post by ID",
+            }
+        },
+        "/api/page/{id}": {
+            "get": {
+                "description": "Get a page by ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Blog Post"
+                    "Page"
                 ],
-                "summary": "Post a blog post",
+                "summary": "Get a page",
                 "parameters": [
                     {
-                        "description": "Push Blog Post",
-                        "name": "BlogPostRequest",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.BlogPostRequest"
-                        }
+                        "type": "string",
+                        "description": "ID (int)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.BlogPostResponse"
+                            "$ref": "#/definitions/main.PageResponse"
                         }
                     },
                     "401": {
@@ -268,14 +258,14 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete a blog post by ID",
+                "description": "Delete a page by ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Blog Post"
+                    "Page"
                 ],
-                "summary": "Delete a blog post",
+                "summary": "Delete a page",
                 "parameters": [
                     {
                         "type": "string",
@@ -289,7 +279,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.UpdateBlogResponse"
+                            "$ref": "#/definitions/main.UpdatePageResponse"
                         }
                     },
                     "401": {
@@ -319,16 +309,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/social/": {
+        "/api/post/": {
             "get": {
-                "description": "List social data",
+                "description": "List blog posts",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Social"
+                    "Blog Post"
                 ],
-                "summary": "List social data",
+                "summary": "List blog posts",
                 "parameters": [
                     {
                         "type": "integer",
@@ -337,15 +327,81 @@ const docTemplate = `{
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "user_id",
-                        "name": "user_id",
+                        "type": "string",
+                        "description": "title",
+                        "name": "title",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "social_platform",
-                        "name": "social_platform",
+                        "description": "lead",
+                        "name": "lead",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_from",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_to",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "last_updated_from",
+                        "name": "last_updated_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "last_updated_to",
+                        "name": "last_updated_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "featured",
+                        "name": "featured",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "kind (article, note or link)",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "language (as in en or nb)",
+                        "name": "language",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "visibility (public, unlisted or private), needs credentials",
+                        "name": "visibility",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "status (draft, in_review, approved or published), needs credentials",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "value of a filterable custom field",
+                        "name": "meta.{key}",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "order_by",
+                        "name": "order_by",
                         "in": "query"
                     }
                 ],
@@ -353,7 +409,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.SocialListResponse"
+                            "$ref": "#/definitions/main.BlogPostListResponse"
                         }
                     },
                     "401": {
@@ -383,30 +439,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/social/{id}": {
+        "/api/post/links/broken": {
             "get": {
-                "description": "Get social data by ID",
+                "description": "List the wiki links in blog posts whose target post does not exist, or that public posts have to private or unpublished posts",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Social"
-                ],
-                "summary": "Get social data",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "ID (int)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Blog Post"
                 ],
+                "summary": "List broken internal links",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.SocialResponse"
+                            "$ref": "#/definitions/main.BrokenLinksResponse"
                         }
                     },
                     "401": {
@@ -415,12 +462,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.ErrorMessage"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/main.ErrorMessage"
-                        }
-                    },
                     "429": {
                         "description": "Too Many Requests",
                         "schema": {
@@ -434,24 +475,115 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update social data",
+            }
+        },
+        "/api/post/rerender": {
+            "post": {
+                "description": "Render every blog post again and store the result, after the renderer configuration has changed",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Social"
+                    "Blog Post"
                 ],
-                "summary": "Update social data",
-                "parameters": [
-                    {
-                        "description": "Update Social Data",
-                        "name": "data.Social",
-                        "in": "body",
+                "summary": "Render all blog posts again",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateBlogResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/post/{id}": {
+            "get": {
+                "description": "Get a blog post by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Blog Post"
+                ],
+                "summary": "Get a blog post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID (int)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.BlogPostResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a blog post by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Blog Post"
+                ],
+                "summary": "Update a blog post",
+                "parameters": [
+                    {
+                        "description": "Update Blog Post",
+                        "name": "data.BlogPost",
+                        "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/data.Social"
+                            "$ref": "#/definitions/data.BlogPost"
                         }
                     }
                 ],
@@ -459,7 +591,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.UpdateSocialResponse"
+                            "$ref": "#/definitions/main.UpdateBlogResponse"
                         }
                     },
                     "401": {
@@ -489,22 +621,22 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Post social data",
+                "description": "Post a blog post by ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Social"
+                    "Blog Post"
                 ],
-                "summary": "Post social data",
+                "summary": "Post a blog post",
                 "parameters": [
                     {
-                        "description": "Push social data",
-                        "name": "SocialPostRequest",
+                        "description": "Push Blog Post",
+                        "name": "BlogPostRequest",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.SocialPostRequest"
+                            "$ref": "#/definitions/main.BlogPostRequest"
                         }
                     }
                 ],
@@ -512,7 +644,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.SocialResponse"
+                            "$ref": "#/definitions/main.BlogPostResponse"
                         }
                     },
                     "401": {
@@ -542,14 +674,14 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete social data",
+                "description": "Delete a blog post by ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Social"
+                    "Blog Post"
                 ],
-                "summary": "Delete social data",
+                "summary": "Delete a blog post",
                 "parameters": [
                     {
                         "type": "string",
@@ -563,7 +695,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.UpdateSocialResponse"
+                            "$ref": "#/definitions/main.UpdateBlogResponse"
                         }
                     },
                     "401": {
@@ -593,16 +725,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/{id}": {
-            "get": {
-                "description": "Get user data",
+        "/api/post/{id}/publish": {
+            "post": {
+                "description": "Publish a post, which must be approved when approval is required",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Review"
                 ],
-                "summary": "Get user data",
+                "summary": "Publish a post",
                 "parameters": [
                     {
                         "type": "string",
@@ -610,13 +742,21 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Review",
+                        "name": "ReviewRequest",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/main.ReviewRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/main.UserPostResponse"
+                            "$ref": "#/definitions/main.ReviewResponse"
                         }
                     },
                     "401": {
@@ -631,6 +771,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.ErrorMessage"
                         }
                     },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
                     "429": {
                         "description": "Too Many Requests",
                         "schema": {
@@ -644,42 +790,48 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update user data",
+            }
+        },
+        "/api/post/{id}/related": {
+            "get": {
+                "description": "List the posts most similar to a blog post by the words of their title, lead and text",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Blog Post"
                 ],
-                "summary": "Update user data",
+                "summary": "List related posts",
                 "parameters": [
                     {
-                        "description": "Update User",
-                        "name": "data.User",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/data.User"
-                        }
+                        "type": "string",
+                        "description": "ID (int)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "maximum number of posts (1-10, default 5)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.UpdateUserResponse"
+                            "$ref": "#/definitions/main.RelatedPostsResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorMessage"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorMessage"
                         }
@@ -699,21 +851,107 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/healthcheck": {
+        "/api/post/{id}/review": {
             "get": {
-                "description": "Endpoint to check if the API is running",
+                "description": "List the reviews of a blog post, oldest first, with who took each action and when",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "healthcheck"
+                    "Review"
+                ],
+                "summary": "List reviews",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID (int)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Healthcheck",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.HealthCheckMessage"
+                            "$ref": "#/definitions/main.ReviewListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Send a draft to review, after which it can be approved or rejected",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Review"
+                ],
+                "summary": "Request a review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID (int)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Review",
+                        "name": "ReviewRequest",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/main.ReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.ReviewResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
                         }
                     },
                     "500": {
@@ -724,96 +962,1765 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "data.BlogPost": {
-            "type": "object",
-            "properties": {
+        },
+        "/api/post/{id}/review/approve": {
+            "post": {
+                "description": "Approve a post in review, by someone other than who requested the review",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Review"
+                ],
+                "summary": "Approve a post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID (int)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Review",
+                        "name": "ReviewRequest",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/main.ReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.ReviewResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/post/{id}/review/reject": {
+            "post": {
+                "description": "Send a post in review or approved back to draft, with a comment saying why",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Review"
+                ],
+                "summary": "Reject a post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID (int)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Review",
+                        "name": "ReviewRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.ReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.ReviewResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/post/{id}/share": {
+            "get": {
+                "description": "List the share links of a blog post, newest first, including expired and revoked ones",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Blog Post"
+                ],
+                "summary": "List share links",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID (int)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ShareLinkListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a signed, expiring link to a blog post, through which private posts can be read",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Blog Post"
+                ],
+                "summary": "Share a blog post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID (int)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Share Link",
+                        "name": "ShareLinkRequest",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/main.ShareLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.ShareLinkResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/preview": {
+            "post": {
+                "description": "Render a blog post through the templates of the site without storing it. The whole page is returned, or only the article with fragment=true.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Blog Post"
+                ],
+                "summary": "Preview a blog post",
+                "parameters": [
+                    {
+                        "description": "Blog Post",
+                        "name": "BlogPostRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.BlogPostRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only the article, without the layout of the site",
+                        "name": "fragment",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "HTML",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/series": {
+            "get": {
+                "description": "List series of posts, with their parts in order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Series"
+                ],
+                "summary": "List series",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "slug",
+                        "name": "slug",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "title",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "order_by",
+                        "name": "order_by",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.SeriesListResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a series of posts by ID, replacing its parts with the posts in order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Series"
+                ],
+                "summary": "Update a series",
+                "parameters": [
+                    {
+                        "description": "Update Series",
+                        "name": "SeriesRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.SeriesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateSeriesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a series of posts. A post can only be part of one series.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Series"
+                ],
+                "summary": "Post a series",
+                "parameters": [
+                    {
+                        "description": "Push Series",
+                        "name": "SeriesRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.SeriesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/data.Series"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/series/{id}": {
+            "get": {
+                "description": "Get a series of posts by ID, with its parts in order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Series"
+                ],
+                "summary": "Get a series",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID (int)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.SeriesResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a series by ID. Its posts are kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Series"
+                ],
+                "summary": "Delete a series",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID (int)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateSeriesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/settings": {
+            "get": {
+                "description": "Get the site wide settings",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Get site settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.SiteSettingsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the site wide settings",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Update site settings",
+                "parameters": [
+                    {
+                        "description": "Update Site Settings",
+                        "name": "SiteSettingsRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.SiteSettingsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateSiteSettingsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/share/{id}": {
+            "delete": {
+                "description": "Revoke a share link by ID, which stops granting access at once",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Blog Post"
+                ],
+                "summary": "Revoke a share link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID (int)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.RevokeShareLinkResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/social/": {
+            "get": {
+                "description": "List social data",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Social"
+                ],
+                "summary": "List social data",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "user_id",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "social_platform",
+                        "name": "social_platform",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.SocialListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/social/{id}": {
+            "get": {
+                "description": "Get social data by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Social"
+                ],
+                "summary": "Get social data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID (int)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.SocialResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update social data",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Social"
+                ],
+                "summary": "Update social data",
+                "parameters": [
+                    {
+                        "description": "Update Social Data",
+                        "name": "data.Social",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/data.Social"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateSocialResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Post social data",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Social"
+                ],
+                "summary": "Post social data",
+                "parameters": [
+                    {
+                        "description": "Push social data",
+                        "name": "SocialPostRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.SocialPostRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.SocialResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete social data",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Social"
+                ],
+                "summary": "Delete social data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID (int)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateSocialResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/stats": {
+            "get": {
+                "description": "Get the number of posts and words in total and per month, and the average number of days between posts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "summary": "Get content statistics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.StatsResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/{id}": {
+            "get": {
+                "description": "Get user data",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get user data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID (int)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.UserPostResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update user data",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Update user data",
+                "parameters": [
+                    {
+                        "description": "Update User",
+                        "name": "data.User",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/data.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateUserResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/healthcheck": {
+            "get": {
+                "description": "Endpoint to check if the API is running",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "healthcheck"
+                ],
+                "summary": "Healthcheck",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.HealthCheckMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorMessage"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "data.BlogPost": {
+            "type": "object",
+            "properties": {
+                "code_blocks": {
+                    "type": "integer"
+                },
+                "created": {
+                    "type": "string"
+                },
+                "excerpt": {
+                    "description": "Excerpt is the beginning of the text of the rendered post.",
+                    "type": "string"
+                },
+                "featured": {
+                    "type": "boolean"
+                },
+                "html": {
+                    "description": "HTML is the sanitized HTML of the post, rendered when it was stored.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "images": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "description": "Kind is one of PostKinds.",
+                    "type": "string"
+                },
+                "language": {
+                    "description": "Language is the language tag of the post, as in \"en\" or \"nb\".",
+                    "type": "string"
+                },
+                "last_update": {
+                    "type": "string"
+                },
+                "lead": {
+                    "type": "string"
+                },
+                "link_url": {
+                    "description": "LinkURL is the page a link post shares, and Quote an optional quote\nfrom it.",
+                    "type": "string"
+                },
+                "meta": {
+                    "description": "Meta holds the custom fields of the post.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/data.PostMeta"
+                        }
+                    ]
+                },
+                "outbound_links": {
+                    "type": "integer"
+                },
+                "outline": {
+                    "description": "Outline lists the headings of the rendered post.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/markdown.Heading"
+                    }
+                },
+                "post": {
+                    "type": "string"
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "reading_time": {
+                    "description": "ReadingTime is the estimated time it takes to read the post, in\nminutes.",
+                    "type": "integer"
+                },
+                "renderer": {
+                    "type": "string"
+                },
+                "slug": {
+                    "description": "Slug identifies the post in wiki links, as in [[post:slug]].",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status is one of Statuses. Only published posts are on the site.",
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "translation_of": {
+                    "description": "TranslationOf is the ID of the original post this post translates.\nTranslations always refer to the original, never to another\ntranslation.",
+                    "type": "integer"
+                },
+                "visibility": {
+                    "description": "Visibility is public, unlisted or private. Unlisted posts are left out\nof listings and feeds, and private posts are only read through share\nlinks.",
+                    "type": "string"
+                },
+                "word_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "data.Metadata": {
+            "type": "object",
+            "properties": {
+                "current_page": {
+                    "type": "integer"
+                },
+                "first_page": {
+                    "type": "integer"
+                },
+                "last_page": {
+                    "type": "integer"
+                },
+                "order_by": {
+                    "type": "string"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total_records": {
+                    "type": "integer"
+                }
+            }
+        },
+        "data.MonthStats": {
+            "type": "object",
+            "properties": {
+                "month": {
+                    "description": "Month is written as YYYY-MM.",
+                    "type": "string"
+                },
+                "posts": {
+                    "type": "integer"
+                },
+                "words": {
+                    "type": "integer"
+                }
+            }
+        },
+        "data.Page": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "created": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_update": {
+                    "type": "string"
+                },
+                "nav_position": {
+                    "type": "integer"
+                },
+                "show_in_nav": {
+                    "type": "boolean"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "visible": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "data.PostLink": {
+            "type": "object",
+            "properties": {
+                "source_id": {
+                    "type": "integer"
+                },
+                "source_title": {
+                    "type": "string"
+                },
+                "target": {
+                    "type": "string"
+                }
+            }
+        },
+        "data.PostMeta": {
+            "type": "object",
+            "additionalProperties": true
+        },
+        "data.Review": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "created": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "post_id": {
+                    "type": "integer"
+                },
+                "reviewer": {
+                    "description": "Reviewer is who took the action. For review requests, it is the author.",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status is the status of the post after the action.",
+                    "type": "string"
+                }
+            }
+        },
+        "data.Series": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_update": {
+                    "type": "string"
+                },
+                "post_ids": {
+                    "description": "PostIDs are the IDs of the parts of the series, in order.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "posts": {
+                    "description": "Posts are the parts of the series, in order, without their content.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/data.BlogPost"
+                    }
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "data.ShareLink": {
+            "type": "object",
+            "properties": {
                 "created": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
+                "expires": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "post_id": {
+                    "type": "integer"
+                },
+                "revoked": {
+                    "type": "string"
+                }
+            }
+        },
+        "data.SiteSettings": {
+            "type": "object",
+            "properties": {
+                "base_url": {
+                    "type": "string"
+                },
+                "default_author": {
+                    "type": "string"
+                },
+                "footer_text": {
+                    "type": "string"
+                },
+                "language": {
+                    "type": "string"
+                },
+                "last_update": {
+                    "type": "string"
+                },
+                "tagline": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "data.SiteStats": {
+            "type": "object",
+            "properties": {
+                "cadence_days": {
+                    "description": "Cadence is the average number of days between posts.",
+                    "type": "number"
+                },
+                "first_post": {
+                    "type": "string"
+                },
+                "last_post": {
+                    "type": "string"
+                },
+                "months": {
+                    "description": "Months lists the months with posts, newest first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/data.MonthStats"
+                    }
+                },
+                "total_posts": {
+                    "type": "integer"
+                },
+                "total_words": {
+                    "type": "integer"
+                }
+            }
+        },
+        "data.Social": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "social_platform": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "data.User": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "summary": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.BlogPostListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/data.BlogPost"
+                    }
+                },
+                "metadata": {
+                    "$ref": "#/definitions/data.Metadata"
+                }
+            }
+        },
+        "main.BlogPostRequest": {
+            "type": "object",
+            "properties": {
+                "featured": {
+                    "type": "boolean"
+                },
+                "kind": {
+                    "description": "Kind is article, note or link. Posts are articles when it is empty.",
+                    "type": "string"
+                },
+                "language": {
+                    "description": "Language is the language tag of the post. Posts are in the language of\nthe site when it is empty.",
+                    "type": "string"
+                },
+                "lead": {
+                    "type": "string"
+                },
+                "link_url": {
+                    "description": "LinkURL is the page a link shares, and Quote an optional quote from it.",
+                    "type": "string"
+                },
+                "meta": {
+                    "description": "Meta holds custom fields, such as a cover image or a license.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/data.PostMeta"
+                        }
+                    ]
                 },
-                "last_update": {
+                "post_content": {
                     "type": "string"
                 },
-                "lead": {
+                "quote": {
                     "type": "string"
                 },
-                "post": {
+                "renderer": {
+                    "description": "Renderer is the name of the markdown renderer. The default renderer is\nused when it is empty.",
+                    "type": "string"
+                },
+                "slug": {
+                    "description": "Slug identifies the post in wiki links. It is derived from the title\nwhen it is empty.",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status is draft or published; posts are published later through the\nreview workflow. Posts are drafts when approval is required and it is\nempty, and published otherwise.",
                     "type": "string"
                 },
                 "title": {
                     "type": "string"
+                },
+                "translation_of": {
+                    "description": "TranslationOf is the ID of the post this post translates.",
+                    "type": "integer"
+                },
+                "visibility": {
+                    "description": "Visibility is public, unlisted or private. Posts are public when it is\nempty.",
+                    "type": "string"
                 }
             }
         },
-        "data.Metadata": {
+        "main.BlogPostResponse": {
             "type": "object",
             "properties": {
-                "current_page": {
-                    "type": "integer"
-                },
-                "first_page": {
-                    "type": "integer"
+                "data": {
+                    "$ref": "#/definitions/data.BlogPost"
                 },
-                "last_page": {
-                    "type": "integer"
+                "metadata": {
+                    "$ref": "#/definitions/data.Metadata"
+                }
+            }
+        },
+        "main.BrokenLinksResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/data.PostLink"
+                    }
+                }
+            }
+        },
+        "main.ErrorMessage": {
+            "type": "object",
+            "properties": {
+                "message": {}
+            }
+        },
+        "main.HealthCheckMessage": {
+            "type": "object",
+            "properties": {
+                "environment": {
+                    "type": "string"
                 },
-                "order_by": {
+                "status": {
                     "type": "string"
                 },
-                "page_size": {
-                    "type": "integer"
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.PageListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/data.Page"
+                    }
                 },
-                "total_records": {
-                    "type": "integer"
+                "metadata": {
+                    "$ref": "#/definitions/data.Metadata"
                 }
             }
         },
-        "data.Social": {
+        "main.PagePostRequest": {
             "type": "object",
             "properties": {
-                "id": {
+                "content": {
+                    "type": "string"
+                },
+                "nav_position": {
                     "type": "integer"
                 },
-                "link": {
+                "show_in_nav": {
+                    "type": "boolean"
+                },
+                "slug": {
                     "type": "string"
                 },
-                "social_platform": {
+                "title": {
                     "type": "string"
                 },
-                "user_id": {
-                    "type": "integer"
+                "visible": {
+                    "type": "boolean"
                 }
             }
         },
-        "data.User": {
+        "main.PageResponse": {
             "type": "object",
             "properties": {
-                "content": {
-                    "type": "string"
+                "data": {
+                    "$ref": "#/definitions/data.Page"
                 },
+                "metadata": {
+                    "$ref": "#/definitions/data.Metadata"
+                }
+            }
+        },
+        "main.RelatedPostsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/data.BlogPost"
+                    }
+                }
+            }
+        },
+        "main.ReviewListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/data.Review"
+                    }
+                }
+            }
+        },
+        "main.ReviewRequest": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.ReviewResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/data.Review"
+                }
+            }
+        },
+        "main.RevokeShareLinkResponse": {
+            "type": "object",
+            "properties": {
                 "id": {
                     "type": "integer"
                 },
-                "name": {
+                "message": {
                     "type": "string"
                 },
-                "summary": {
-                    "type": "string"
+                "rows_affected": {
+                    "type": "integer"
                 }
             }
         },
-        "main.BlogPostListResponse": {
+        "main.SeriesListResponse": {
             "type": "object",
             "properties": {
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/data.BlogPost"
+                        "$ref": "#/definitions/data.Series"
                     }
                 },
                 "metadata": {
@@ -821,13 +2728,23 @@ const docTemplate = `{
                 }
             }
         },
-        "main.BlogPostRequest": {
+        "main.SeriesRequest": {
             "type": "object",
             "properties": {
-                "lead": {
+                "description": {
                     "type": "string"
                 },
-                "post_content": {
+                "id": {
+                    "type": "integer"
+                },
+                "post_ids": {
+                    "description": "PostIDs are the IDs of the parts of the series, in order.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "slug": {
                     "type": "string"
                 },
                 "title": {
@@ -835,37 +2752,105 @@ const docTemplate = `{
                 }
             }
         },
-        "main.BlogPostResponse": {
+        "main.SeriesResponse": {
             "type": "object",
             "properties": {
                 "data": {
-                    "$ref": "#/definitions/data.BlogPost"
+                    "$ref": "#/definitions/data.Series"
                 },
                 "metadata": {
                     "$ref": "#/definitions/data.Metadata"
                 }
             }
         },
-        "main.ErrorMessage": {
+        "main.ShareLinkListResponse": {
             "type": "object",
             "properties": {
-                "message": {}
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.SharedLink"
+                    }
+                }
             }
         },
-        "main.HealthCheckMessage": {
+        "main.ShareLinkRequest": {
             "type": "object",
             "properties": {
-                "environment": {
+                "expires": {
+                    "description": "Expires is how long the link is valid for, as in 48h. The configured\ndefault is used when it is empty.",
+                    "type": "string"
+                }
+            }
+        },
+        "main.ShareLinkResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/main.SharedLink"
+                }
+            }
+        },
+        "main.SharedLink": {
+            "type": "object",
+            "properties": {
+                "created": {
                     "type": "string"
                 },
-                "status": {
+                "expires": {
                     "type": "string"
                 },
-                "version": {
+                "id": {
+                    "type": "integer"
+                },
+                "post_id": {
+                    "type": "integer"
+                },
+                "revoked": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.SiteSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "base_url": {
+                    "type": "string"
+                },
+                "default_author": {
+                    "type": "string"
+                },
+                "footer_text": {
+                    "type": "string"
+                },
+                "language": {
+                    "type": "string"
+                },
+                "tagline": {
+                    "type": "string"
+                },
+                "title": {
                     "type": "string"
                 }
             }
         },
+        "main.SiteSettingsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/data.SiteSettings"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/data.Metadata"
+                }
+            }
+        },
         "main.SocialListResponse": {
             "type": "object",
             "properties": {
@@ -905,6 +2890,14 @@ const docTemplate = `{
                 }
             }
         },
+        "main.StatsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/data.SiteStats"
+                }
+            }
+        },
         "main.UpdateBlogResponse": {
             "type": "object",
             "properties": {
@@ -919,6 +2912,45 @@ const docTemplate = `{
                 }
             }
         },
+        "main.UpdatePageResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "rows_affected": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.UpdateSeriesResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "rows_affected": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.UpdateSiteSettingsResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "rows_affected": {
+                    "type": "integer"
+                }
+            }
+        },
         "main.UpdateSocialResponse": {
             "type": "object",
             "properties": {
@@ -954,6 +2986,20 @@ const docTemplate = `{
                     "$ref": "#/definitions/data.Metadata"
                 }
             }
+        },
+        "markdown.Heading": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "level": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
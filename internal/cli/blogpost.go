@@ -4,11 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	neturl "net/url"
 	"os"
 	"strconv"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"textonly.islandwind.me/internal/data"
 	"textonly.islandwind.me/internal/utils"
 )
@@ -23,6 +23,15 @@ type BlogPostResponse struct {
 	Data     data.BlogPost `json:"data"`
 }
 
+// blogPostKind, blogPostLanguage, blogPostVisibility and blogPostStatus
+// filter the listed blog posts by kind, language, visibility and status.
+var (
+	blogPostKind       string
+	blogPostLanguage   string
+	blogPostVisibility string
+	blogPostStatus     string
+)
+
 // getCmd represents the host command
 var getBlogPostCmd = &cobra.Command{
 	Use:   "blogpost",
@@ -30,11 +39,13 @@ var getBlogPostCmd = &cobra.Command{
 	Long: `Gets the blog post for the user of the Textonly host.
 
 By default, it lists all blog posts. If you specify the ID (integer), it
-only lists the for that ID.`,
+only lists the for that ID. The kind flag lists only articles, notes or
+links, and the language flag only posts in the language, as in nb. Posts that
+are not public and published are only listed with the visibility and status
+flags, which need the credentials of the configuration file.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// TODO: Cleanup this mess
-		host := viper.Get("host")
-		url := fmt.Sprintf("%s/api/post", host)
+		url := "/api/post"
 
 		if len(args) > 1 {
 			fmt.Println("Too many arguments")
@@ -49,10 +60,31 @@ only lists the for that ID.`,
 			}
 
 			url = fmt.Sprintf("%s/%d", url, id)
+		} else {
+			query := neturl.Values{}
+			if blogPostKind != "" {
+				query.Set("kind", blogPostKind)
+			}
+			if blogPostLanguage != "" {
+				query.Set("language", blogPostLanguage)
+			}
+			if blogPostVisibility != "" {
+				query.Set("visibility", blogPostVisibility)
+			}
+			if blogPostStatus != "" {
+				query.Set("status", blogPostStatus)
+			}
+			if len(query) > 0 {
+				url = fmt.Sprintf("%s?%s", url, query.Encode())
+			}
 		}
 
-		req, _ := http.NewRequest("GET", url, nil)
-		req.Header.Add("User-Agent", "toctl (Textonly API client)")
+		// the credentials are sent along, as only they show private posts
+		req, err := newRequest(http.MethodGet, url, nil)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
 
 		res, err := http.DefaultClient.Do(req)
 		if err != nil {
@@ -76,9 +108,12 @@ only lists the for that ID.`,
 
 			if !jsonOutput {
 				fmt.Printf(
-					"ID: %d, Title: %s, Created: %s, Last update: %s\n",
+					"ID: %d, Kind: %s, Status: %s, Language: %s, Title: %s, Created: %s, Last update: %s\n",
 					bp.Data.ID,
-					bp.Data.Title,
+					bp.Data.Kind,
+					bp.Data.Status,
+					bp.Data.Language,
+					bp.Data.DisplayTitle(),
 					bp.Data.Created,
 					bp.Data.LastUpdate,
 				)
@@ -104,9 +139,12 @@ only lists the for that ID.`,
 			if !jsonOutput {
 				for _, bp := range s.Data {
 					fmt.Printf(
-						"ID: %d, Title: %s, Created: %s, Last update: %s\n",
+						"ID: %d, Kind: %s, Status: %s, Language: %s, Title: %s, Created: %s, Last update: %s\n",
 						bp.ID,
-						bp.Title,
+						bp.Kind,
+						bp.Status,
+						bp.Language,
+						bp.DisplayTitle(),
 						bp.Created,
 						bp.LastUpdate,
 					)
@@ -123,10 +161,181 @@ only lists the for that ID.`,
 	},
 }
 
+// blogPostRequest is the body the host expects to create a blog post.
+type blogPostRequest struct {
+	Kind     string         `json:"kind,omitempty"`
+	Title    string         `json:"title"`
+	Lead     string         `json:"lead"`
+	Post     string         `json:"post_content"`
+	Featured bool           `json:"featured"`
+	Renderer string         `json:"renderer,omitempty"`
+	Slug     string         `json:"slug,omitempty"`
+	LinkURL  string         `json:"link_url,omitempty"`
+	Quote    string         `json:"quote,omitempty"`
+	Meta     map[string]any `json:"meta,omitempty"`
+	Language string         `json:"language,omitempty"`
+	// TranslationOf is the ID of the post the post translates.
+	TranslationOf *int   `json:"translation_of,omitempty"`
+	Visibility    string `json:"visibility,omitempty"`
+	Status        string `json:"status,omitempty"`
+}
+
+// blogPostFile is the markdown file a blog post is created or updated from.
+var blogPostFile string
+
+// createBlogPostCmd represents the create blogpost command
+var createBlogPostCmd = &cobra.Command{
+	Use:   "blogpost",
+	Short: "Create a blog post on the configured Textonly host",
+	Long: `Creates a blog post from the given markdown file. The fields of the
+post are read from the YAML front matter of the file, and custom fields from
+its meta map:
+
+  ---
+  title: Hello
+  lead: The first post
+  kind: article
+  language: en
+  visibility: public
+  status: draft
+  meta:
+    license: cc-by
+  ---
+  The text of the post.
+
+A translation gives the ID of the post it translates as translation_of. The
+visibility is public, unlisted or private; unlisted posts are left out of
+listings and feeds, and private posts are read through share links. Posts
+created as drafts are published through review, see toctl review.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		pf, err := readPostFile(blogPostFile)
+		if err != nil {
+			fmt.Printf("Unable to read blog post: %s\n", err)
+			os.Exit(1)
+		}
+
+		body := blogPostRequest{
+			Kind:     pf.Kind,
+			Title:    pf.Title,
+			Lead:     pf.Lead,
+			Post:     pf.Post,
+			Featured: pf.Featured,
+			Renderer: pf.Renderer,
+			Slug:     pf.Slug,
+			LinkURL:  pf.LinkURL,
+			Quote:    pf.Quote,
+			Meta:     pf.Meta,
+			Language: pf.Language,
+			// the host stores translations as translations of the original
+			TranslationOf: pf.TranslationOf,
+			Visibility:    pf.Visibility,
+			Status:        pf.Status,
+		}
+
+		req, err := newRequest(http.MethodPost, "/api/post", body)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		bp := data.BlogPost{}
+		if err := do(req, &bp); err != nil {
+			fmt.Printf("Unable to create blog post: %s\n", err)
+			os.Exit(1)
+		}
+
+		if !jsonOutput {
+			fmt.Printf(
+				"ID: %d, Kind: %s, Status: %s, Title: %s, Slug: %s\n",
+				bp.ID, bp.Kind, bp.Status, bp.DisplayTitle(), bp.Slug,
+			)
+			return
+		}
+		if err := printJSON(bp); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// updateBlogPostCmd represents the update blogpost command
+var updateBlogPostCmd = &cobra.Command{
+	Use:   "blogpost <id>",
+	Short: "Update a blog post on the configured Textonly host",
+	Long: `Updates the blog post with the given ID from the given markdown file.
+The text is replaced, and so are the fields given in the front matter; the
+rest are kept as they are.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Println("ID must be an integer")
+			os.Exit(1)
+		}
+
+		pf, err := readPostFile(blogPostFile)
+		if err != nil {
+			fmt.Printf("Unable to read blog post: %s\n", err)
+			os.Exit(1)
+		}
+
+		req, err := newRequest(http.MethodGet, fmt.Sprintf("/api/post/%d", id), nil)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		current := BlogPostResponse{}
+		if err := do(req, &current); err != nil {
+			fmt.Printf("Unable to get blog post: %s\n", err)
+			os.Exit(1)
+		}
+		bp := current.Data
+		pf.apply(&bp)
+
+		req, err = newRequest(http.MethodPut, "/api/post", bp)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := do(req, nil); err != nil {
+			fmt.Printf("Unable to update blog post: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Blog post %d updated\n", id)
+	},
+}
+
 func init() {
 	getBlogPostCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output to JSON")
+	getBlogPostCmd.Flags().StringVar(
+		&blogPostKind, "kind", "", "Only list posts of the kind: article, note or link",
+	)
+	getBlogPostCmd.Flags().StringVar(
+		&blogPostLanguage, "language", "", "Only list posts in the language, as in nb",
+	)
+	getBlogPostCmd.Flags().StringVar(
+		&blogPostVisibility, "visibility", "", "Only list posts of the visibility: public, unlisted or private",
+	)
+	getBlogPostCmd.Flags().StringVar(
+		&blogPostStatus, "status", "", "Only list posts of the status: draft, in_review, approved or published",
+	)
 	// TODO: Add flags for markdown output
 	// getBlogPostCmd.Flags().BoolVarP(&jsonOutput, "markdown", "md", false, "Write to Markdown file")
 	// getBlogPostCmd.Flags().BoolVarP(&jsonOutput, "read", "r", false, "Read blog post in terminal")
 	getCmd.AddCommand(getBlogPostCmd)
+
+	createBlogPostCmd.Flags().StringVarP(
+		&blogPostFile, "file", "f", "", "Markdown file with front matter",
+	)
+	createBlogPostCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output to JSON")
+	_ = createBlogPostCmd.MarkFlagRequired("file")
+	createCmd.AddCommand(createBlogPostCmd)
+
+	updateBlogPostCmd.Flags().StringVarP(
+		&blogPostFile, "file", "f", "", "Markdown file with front matter",
+	)
+	_ = updateBlogPostCmd.MarkFlagRequired("file")
+	updateCmd.AddCommand(updateBlogPostCmd)
 }
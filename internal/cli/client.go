@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/spf13/viper"
+)
+
+// newRequest creates a request against the configured Textonly host. The
+// credentials from the configuration file are attached if present, as they
+// are required by every endpoint that modifies data.
+func newRequest(method, path string, body any) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		js, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(js)
+	}
+
+	url := fmt.Sprintf("%s%s", viper.GetString("host"), path)
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("User-Agent", "toctl (Textonly API client)")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if user := viper.GetString("user"); user != "" {
+		req.SetBasicAuth(user, viper.GetString("password"))
+	}
+
+	return req, nil
+}
+
+// do sends the request and decodes the JSON response into data. Responses
+// with a status code outside the 2xx range are returned as errors, including
+// the message returned by the API.
+func do(req *http.Request, data any) error {
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("%s: %s", res.Status, bytes.TrimSpace(body))
+	}
+
+	if data == nil {
+		return nil
+	}
+
+	return json.NewDecoder(res.Body).Decode(data)
+}
+
+// printJSON writes the value to stdout as JSON.
+func printJSON(data any) error {
+	js, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(js))
+
+	return nil
+}
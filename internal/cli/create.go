@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// createCmd represents the create command
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Creates a resource in the Textonly application",
+	Long: `The create command is used to add a new resource to the Textonly
+application, for example a page. Requires the user and password of the host
+to be set in the configuration file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("need subcommand")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(createCmd)
+}
@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// deleteCmd represents the delete command
+var deleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Deletes a resource from the Textonly application",
+	Long: `The delete command is used to remove a resource from the Textonly
+application. Requires the user and password of the host to be set in the
+configuration file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("need subcommand")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(deleteCmd)
+}
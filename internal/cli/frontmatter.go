@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+	"textonly.islandwind.me/internal/data"
+)
+
+// frontMatterDelimiter starts and ends the YAML front matter of a post file.
+var frontMatterDelimiter = []byte("---")
+
+// postFile is a markdown file with the fields of a blog post in its YAML
+// front matter, as in:
+//
+//	---
+//	title: Hello
+//	kind: article
+//	meta:
+//	  license: cc-by
+//	---
+//	The text of the post.
+type postFile struct {
+	Title    string         `yaml:"title"`
+	Lead     string         `yaml:"lead"`
+	Kind     string         `yaml:"kind"`
+	Slug     string         `yaml:"slug"`
+	Featured bool           `yaml:"featured"`
+	Renderer string         `yaml:"renderer"`
+	LinkURL  string         `yaml:"link_url"`
+	Quote    string         `yaml:"quote"`
+	Meta     map[string]any `yaml:"meta"`
+	Language string         `yaml:"language"`
+	// TranslationOf is the ID of the post the post translates.
+	TranslationOf *int `yaml:"translation_of"`
+	// Visibility is public, unlisted or private.
+	Visibility string `yaml:"visibility"`
+	// Status is draft or published. It is only read when the post is
+	// created; later it changes through reviews.
+	Status string `yaml:"status"`
+	// Post is the markdown after the front matter.
+	Post string `yaml:"-"`
+	// set holds the keys given in the front matter.
+	set map[string]bool
+}
+
+// readPostFile reads the post file at the path. Files without front matter
+// only have the text of the post.
+func readPostFile(path string) (*postFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pf := &postFile{set: map[string]bool{}}
+	rest, ok := bytes.CutPrefix(content, frontMatterDelimiter)
+	if !ok || !(bytes.HasPrefix(rest, []byte("\n")) || bytes.HasPrefix(rest, []byte("\r\n"))) {
+		pf.Post = string(content)
+		return pf, nil
+	}
+
+	front, body, ok := bytes.Cut(rest, append([]byte("\n"), frontMatterDelimiter...))
+	if !ok {
+		return nil, fmt.Errorf("front matter is not closed with %s", frontMatterDelimiter)
+	}
+	if err = yaml.Unmarshal(front, pf); err != nil {
+		return nil, fmt.Errorf("unable to parse front matter: %w", err)
+	}
+
+	keys := map[string]any{}
+	if err = yaml.Unmarshal(front, &keys); err != nil {
+		return nil, fmt.Errorf("unable to parse front matter: %w", err)
+	}
+	for key := range keys {
+		pf.set[key] = true
+	}
+
+	// the rest of the closing line
+	if i := bytes.IndexByte(body, '\n'); i >= 0 {
+		body = body[i+1:]
+	} else {
+		body = nil
+	}
+	pf.Post = string(body)
+
+	return pf, nil
+}
+
+// apply sets the fields of the blog post given in the file, and the text.
+func (pf *postFile) apply(bp *data.BlogPost) {
+	if pf.set["title"] {
+		bp.Title = pf.Title
+	}
+	if pf.set["lead"] {
+		bp.Lead = pf.Lead
+	}
+	if pf.set["kind"] {
+		bp.Kind = pf.Kind
+	}
+	if pf.set["slug"] {
+		bp.Slug = pf.Slug
+	}
+	if pf.set["featured"] {
+		bp.Featured = pf.Featured
+	}
+	if pf.set["renderer"] {
+		bp.Renderer = pf.Renderer
+	}
+	if pf.set["link_url"] {
+		bp.LinkURL = pf.LinkURL
+	}
+	if pf.set["quote"] {
+		bp.Quote = pf.Quote
+	}
+	if pf.set["meta"] {
+		bp.Meta = pf.Meta
+	}
+	if pf.set["language"] {
+		bp.Language = pf.Language
+	}
+	if pf.set["translation_of"] {
+		bp.TranslationOf = pf.TranslationOf
+	}
+	if pf.set["visibility"] {
+		bp.Visibility = pf.Visibility
+	}
+	bp.Post = pf.Post
+}
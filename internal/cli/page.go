@@ -0,0 +1,275 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"textonly.islandwind.me/internal/data"
+)
+
+type PageListResponse struct {
+	Metadata data.Metadata `json:"metadata"`
+	Data     []*data.Page  `json:"data"`
+}
+
+type PageResponse struct {
+	Metadata data.Metadata `json:"metadata"`
+	Data     data.Page     `json:"data"`
+}
+
+type pageFlags struct {
+	slug        string
+	title       string
+	file        string
+	navPosition int
+	showInNav   bool
+	hidden      bool
+}
+
+var (
+	createPageFlags pageFlags
+	updatePageFlags pageFlags
+)
+
+// getPageCmd represents the get page command
+var getPageCmd = &cobra.Command{
+	Use:   "page [id]",
+	Short: "Get the pages of the configured Textonly host",
+	Long: `Gets the static pages of the Textonly host.
+
+By default, it lists all pages. If you specify the ID (integer), it only
+lists the page for that ID.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 0 {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				fmt.Println("ID must be an integer")
+				os.Exit(1)
+			}
+
+			p := PageResponse{}
+			if err := getPage(id, &p); err != nil {
+				fmt.Printf("Unable to get page: %s\n", err)
+				os.Exit(1)
+			}
+
+			if !jsonOutput {
+				printPage(&p.Data)
+				fmt.Println(p.Data.Content)
+				return
+			}
+			if err := printJSON(p); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		req, err := newRequest(http.MethodGet, "/api/page", nil)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		pages := PageListResponse{}
+		if err := do(req, &pages); err != nil {
+			fmt.Printf("Unable to get pages: %s\n", err)
+			os.Exit(1)
+		}
+
+		if !jsonOutput {
+			for _, p := range pages.Data {
+				printPage(p)
+			}
+			return
+		}
+		if err := printJSON(pages); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// createPageCmd represents the create page command
+var createPageCmd = &cobra.Command{
+	Use:   "page",
+	Short: "Create a page on the configured Textonly host",
+	Long: `Creates a static page served at /{slug} on the Textonly host. The page
+content is read from the given markdown file.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		content, err := os.ReadFile(createPageFlags.file)
+		if err != nil {
+			fmt.Printf("Unable to read page content: %s\n", err)
+			os.Exit(1)
+		}
+
+		body := data.Page{
+			Slug:        createPageFlags.slug,
+			Title:       createPageFlags.title,
+			Content:     string(content),
+			NavPosition: createPageFlags.navPosition,
+			ShowInNav:   createPageFlags.showInNav,
+			Visible:     !createPageFlags.hidden,
+		}
+
+		req, err := newRequest(http.MethodPost, "/api/page", body)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		p := data.Page{}
+		if err := do(req, &p); err != nil {
+			fmt.Printf("Unable to create page: %s\n", err)
+			os.Exit(1)
+		}
+
+		if !jsonOutput {
+			printPage(&p)
+			return
+		}
+		if err := printJSON(p); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// updatePageCmd represents the update page command
+var updatePageCmd = &cobra.Command{
+	Use:   "page <id>",
+	Short: "Update a page on the configured Textonly host",
+	Long: `Updates the page with the given ID. Only the fields given as flags
+are changed, the rest are kept as they are.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Println("ID must be an integer")
+			os.Exit(1)
+		}
+
+		current := PageResponse{}
+		if err := getPage(id, &current); err != nil {
+			fmt.Printf("Unable to get page: %s\n", err)
+			os.Exit(1)
+		}
+		p := current.Data
+
+		flags := cmd.Flags()
+		if flags.Changed("slug") {
+			p.Slug = updatePageFlags.slug
+		}
+		if flags.Changed("title") {
+			p.Title = updatePageFlags.title
+		}
+		if flags.Changed("file") {
+			content, err := os.ReadFile(updatePageFlags.file)
+			if err != nil {
+				fmt.Printf("Unable to read page content: %s\n", err)
+				os.Exit(1)
+			}
+			p.Content = string(content)
+		}
+		if flags.Changed("nav-position") {
+			p.NavPosition = updatePageFlags.navPosition
+		}
+		if flags.Changed("nav") {
+			p.ShowInNav = updatePageFlags.showInNav
+		}
+		if flags.Changed("hidden") {
+			p.Visible = !updatePageFlags.hidden
+		}
+		p.Created, p.LastUpdate = nil, nil
+
+		req, err := newRequest(http.MethodPut, "/api/page", p)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := do(req, nil); err != nil {
+			fmt.Printf("Unable to update page: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Page %d updated\n", id)
+	},
+}
+
+// deletePageCmd represents the delete page command
+var deletePageCmd = &cobra.Command{
+	Use:   "page <id>",
+	Short: "Delete a page from the configured Textonly host",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Println("ID must be an integer")
+			os.Exit(1)
+		}
+
+		req, err := newRequest(http.MethodDelete, fmt.Sprintf("/api/page/%d", id), nil)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := do(req, nil); err != nil {
+			fmt.Printf("Unable to delete page: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Page %d deleted\n", id)
+	},
+}
+
+func getPage(id int, p *PageResponse) error {
+	req, err := newRequest(http.MethodGet, fmt.Sprintf("/api/page/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	return do(req, p)
+}
+
+func printPage(p *data.Page) {
+	fmt.Printf(
+		"ID: %d, Slug: %s, Title: %s, Nav: %t (%d), Visible: %t\n",
+		p.ID,
+		p.Slug,
+		p.Title,
+		p.ShowInNav,
+		p.NavPosition,
+		p.Visible,
+	)
+}
+
+func addPageFlags(cmd *cobra.Command, f *pageFlags) {
+	cmd.Flags().StringVar(&f.slug, "slug", "", "URL slug of the page, served at /{slug}")
+	cmd.Flags().StringVar(&f.title, "title", "", "Title of the page")
+	cmd.Flags().StringVarP(&f.file, "file", "f", "", "Markdown file with the page content")
+	cmd.Flags().IntVar(&f.navPosition, "nav-position", 0, "Position in the navigation bar")
+	cmd.Flags().BoolVar(&f.showInNav, "nav", false, "Show the page in the navigation bar")
+	cmd.Flags().BoolVar(&f.hidden, "hidden", false, "Hide the page from readers")
+}
+
+func init() {
+	getPageCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output to JSON")
+	getCmd.AddCommand(getPageCmd)
+
+	addPageFlags(createPageCmd, &createPageFlags)
+	createPageCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output to JSON")
+	_ = createPageCmd.MarkFlagRequired("slug")
+	_ = createPageCmd.MarkFlagRequired("title")
+	_ = createPageCmd.MarkFlagRequired("file")
+	createCmd.AddCommand(createPageCmd)
+
+	addPageFlags(updatePageCmd, &updatePageFlags)
+	updateCmd.AddCommand(updatePageCmd)
+
+	deleteCmd.AddCommand(deletePageCmd)
+}
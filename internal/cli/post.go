@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// postCmd represents the post command
+var postCmd = &cobra.Command{
+	Use:   "post",
+	Short: "Manages the posts of the Textonly application",
+	Long: `The post commands manage what is done with a post beyond creating and
+updating it. For example, you can use the post command to share a private post
+through a link.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("need subcommand")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(postCmd)
+}
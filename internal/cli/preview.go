@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// previewFragment renders only the article, and previewOutput is the file the
+// preview is written to. It is written to stdout when previewOutput is empty.
+var (
+	previewFragment bool
+	previewOutput   string
+)
+
+// previewCmd represents the post preview command
+var previewCmd = &cobra.Command{
+	Use:   "preview",
+	Short: "Preview a blog post with the templates of the configured Textonly host",
+	Long: `Renders the given markdown file as the Textonly host would show the post,
+without storing it. The file has the same front matter as for toctl create
+blogpost. The whole page is written, or only the article with the fragment
+flag.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		pf, err := readPostFile(blogPostFile)
+		if err != nil {
+			fmt.Printf("Unable to read blog post: %s\n", err)
+			os.Exit(1)
+		}
+
+		body := blogPostRequest{
+			Kind:          pf.Kind,
+			Title:         pf.Title,
+			Lead:          pf.Lead,
+			Post:          pf.Post,
+			Featured:      pf.Featured,
+			Renderer:      pf.Renderer,
+			Slug:          pf.Slug,
+			LinkURL:       pf.LinkURL,
+			Quote:         pf.Quote,
+			Meta:          pf.Meta,
+			Language:      pf.Language,
+			TranslationOf: pf.TranslationOf,
+			Visibility:    pf.Visibility,
+			Status:        pf.Status,
+		}
+
+		path := "/api/preview"
+		if previewFragment {
+			path += "?fragment=true"
+		}
+		req, err := newRequest(http.MethodPost, path, body)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			msg, _ := io.ReadAll(res.Body)
+			fmt.Printf("Unable to preview blog post: %s: %s\n", res.Status, bytes.TrimSpace(msg))
+			os.Exit(1)
+		}
+
+		out := io.Writer(os.Stdout)
+		if previewOutput != "" {
+			f, err := os.Create(previewOutput)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			defer f.Close()
+			out = f
+		}
+
+		if _, err := io.Copy(out, res.Body); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	previewCmd.Flags().StringVarP(
+		&blogPostFile, "file", "f", "", "Markdown file with front matter",
+	)
+	previewCmd.Flags().BoolVar(&previewFragment, "fragment", false, "Only render the article")
+	previewCmd.Flags().StringVarP(&previewOutput, "output", "o", "", "File to write the HTML to")
+	_ = previewCmd.MarkFlagRequired("file")
+	postCmd.AddCommand(previewCmd)
+}
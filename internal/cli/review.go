@@ -0,0 +1,213 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"textonly.islandwind.me/internal/data"
+)
+
+type ReviewResponse struct {
+	Data data.Review `json:"data"`
+}
+
+type ReviewListResponse struct {
+	Data []*data.Review `json:"data"`
+}
+
+// reviewRequest is the body the host expects to take an action of the review
+// workflow.
+type reviewRequest struct {
+	Comment string `json:"comment,omitempty"`
+}
+
+// reviewComment is what the reviewer says about the post. The host records
+// the user of the configuration file as the reviewer.
+var reviewComment string
+
+// reviewCmd represents the review command
+var reviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Reviews the posts of the Textonly application",
+	Long: `The review commands move posts through the editorial workflow: a draft is
+sent to review, then approved or rejected by a reviewer, and approved posts
+are published with toctl post publish. When the host requires approval, only
+approved posts can be published.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("need subcommand")
+	},
+}
+
+// reviewRequestCmd represents the review request command
+var reviewRequestCmd = &cobra.Command{
+	Use:   "request <id>",
+	Short: "Send a draft on the configured Textonly host to review",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		takeReviewAction(args[0], "/api/post/%d/review", "request review")
+	},
+}
+
+// reviewApproveCmd represents the review approve command
+var reviewApproveCmd = &cobra.Command{
+	Use:   "approve <id>",
+	Short: "Approve a post in review on the configured Textonly host",
+	Long: `Approves the post in review with the given ID. The user of the configuration
+file must be someone other than who requested the review.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		takeReviewAction(args[0], "/api/post/%d/review/approve", "approve post")
+	},
+}
+
+// reviewRejectCmd represents the review reject command
+var reviewRejectCmd = &cobra.Command{
+	Use:   "reject <id>",
+	Short: "Reject a post in review on the configured Textonly host",
+	Long: `Sends the post with the given ID back to draft. The comment says what must
+change before the post is reviewed again.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		takeReviewAction(args[0], "/api/post/%d/review/reject", "reject post")
+	},
+}
+
+// reviewListCmd represents the review list command
+var reviewListCmd = &cobra.Command{
+	Use:   "list [id]",
+	Short: "List the posts in review on the configured Textonly host",
+	Long: `Lists the posts waiting for review. If you specify the ID (integer), it
+lists the reviews of that post instead, with who took each action and when.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 0 {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				fmt.Println("ID must be an integer")
+				os.Exit(1)
+			}
+
+			req, err := newRequest(http.MethodGet, fmt.Sprintf("/api/post/%d/review", id), nil)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			reviews := ReviewListResponse{}
+			if err := do(req, &reviews); err != nil {
+				fmt.Printf("Unable to get reviews: %s\n", err)
+				os.Exit(1)
+			}
+
+			if !jsonOutput {
+				for _, r := range reviews.Data {
+					printReview(r)
+				}
+				return
+			}
+			if err := printJSON(reviews); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		query := neturl.Values{}
+		query.Set("status", data.StatusInReview)
+		req, err := newRequest(http.MethodGet, "/api/post?"+query.Encode(), nil)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		posts := BlogPostListResponse{}
+		if err := do(req, &posts); err != nil {
+			fmt.Printf("Unable to get posts in review: %s\n", err)
+			os.Exit(1)
+		}
+
+		if !jsonOutput {
+			for _, bp := range posts.Data {
+				fmt.Printf("ID: %d, Kind: %s, Title: %s, Last update: %s\n",
+					bp.ID, bp.Kind, bp.DisplayTitle(), bp.LastUpdate)
+			}
+			return
+		}
+		if err := printJSON(posts); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// publishCmd represents the post publish command
+var publishCmd = &cobra.Command{
+	Use:   "publish <id>",
+	Short: "Publish a post on the configured Textonly host",
+	Long: `Publishes the post with the given ID. When the host requires approval, the
+post must have been approved in review.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		takeReviewAction(args[0], "/api/post/%d/publish", "publish post")
+	},
+}
+
+// takeReviewAction posts the comment to the path of the action
+// for the post with the ID, and prints the review.
+func takeReviewAction(rawID, path, what string) {
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		fmt.Println("ID must be an integer")
+		os.Exit(1)
+	}
+
+	body := reviewRequest{Comment: reviewComment}
+	req, err := newRequest(http.MethodPost, fmt.Sprintf(path, id), body)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	r := ReviewResponse{}
+	if err := do(req, &r); err != nil {
+		fmt.Printf("Unable to %s: %s\n", what, err)
+		os.Exit(1)
+	}
+
+	if !jsonOutput {
+		printReview(&r.Data)
+		return
+	}
+	if err := printJSON(r); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// printReview prints the review on one line, followed by its comment.
+func printReview(r *data.Review) {
+	fmt.Printf("Post: %d, Action: %s, Reviewer: %s, Status: %s, Created: %s\n",
+		r.PostID, r.Action, r.Reviewer, r.Status, r.Created)
+	if r.Comment != "" {
+		fmt.Printf("  %s\n", r.Comment)
+	}
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{reviewRequestCmd, reviewApproveCmd, reviewRejectCmd, publishCmd} {
+		cmd.Flags().StringVarP(&reviewComment, "comment", "m", "", "Comment on the post")
+		cmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output to JSON")
+	}
+	_ = reviewRejectCmd.MarkFlagRequired("comment")
+
+	reviewListCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output to JSON")
+
+	reviewCmd.AddCommand(reviewRequestCmd, reviewApproveCmd, reviewRejectCmd, reviewListCmd)
+	rootCmd.AddCommand(reviewCmd)
+
+	postCmd.AddCommand(publishCmd)
+}
@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"textonly.islandwind.me/internal/data"
+)
+
+type SeriesListResponse struct {
+	Metadata data.Metadata  `json:"metadata"`
+	Data     []*data.Series `json:"data"`
+}
+
+type SeriesResponse struct {
+	Metadata data.Metadata `json:"metadata"`
+	Data     data.Series   `json:"data"`
+}
+
+// seriesRequest is the body the host expects to create or update a series.
+type seriesRequest struct {
+	ID          int    `json:"id,omitempty"`
+	Slug        string `json:"slug"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	PostIDs     []int  `json:"post_ids"`
+}
+
+type seriesFlags struct {
+	slug        string
+	title       string
+	description string
+	posts       []int
+}
+
+var (
+	createSeriesFlags seriesFlags
+	updateSeriesFlags seriesFlags
+)
+
+// getSeriesCmd represents the get series command
+var getSeriesCmd = &cobra.Command{
+	Use:   "series [id]",
+	Short: "Get the series of the configured Textonly host",
+	Long: `Gets the series of posts of the Textonly host.
+
+By default, it lists all series. If you specify the ID (integer), it only
+lists the series for that ID, with its parts in order.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 0 {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				fmt.Println("ID must be an integer")
+				os.Exit(1)
+			}
+
+			s := SeriesResponse{}
+			if err := getSeries(id, &s); err != nil {
+				fmt.Printf("Unable to get series: %s\n", err)
+				os.Exit(1)
+			}
+
+			if !jsonOutput {
+				printSeries(&s.Data)
+				for i, p := range s.Data.Posts {
+					fmt.Printf("  %d. %s (ID: %d)\n", i+1, p.Title, p.ID)
+				}
+				return
+			}
+			if err := printJSON(s); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		req, err := newRequest(http.MethodGet, "/api/series", nil)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		series := SeriesListResponse{}
+		if err := do(req, &series); err != nil {
+			fmt.Printf("Unable to get series: %s\n", err)
+			os.Exit(1)
+		}
+
+		if !jsonOutput {
+			for _, s := range series.Data {
+				printSeries(s)
+			}
+			return
+		}
+		if err := printJSON(series); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// createSeriesCmd represents the create series command
+var createSeriesCmd = &cobra.Command{
+	Use:   "series",
+	Short: "Create a series on the configured Textonly host",
+	Long: `Creates a series of posts served at /series/{slug} on the Textonly host.
+The posts are given by ID in the order of their parts, and a post can only be
+part of one series.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		body := seriesRequest{
+			Slug:        createSeriesFlags.slug,
+			Title:       createSeriesFlags.title,
+			Description: createSeriesFlags.description,
+			PostIDs:     createSeriesFlags.posts,
+		}
+
+		req, err := newRequest(http.MethodPost, "/api/series", body)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		s := data.Series{}
+		if err := do(req, &s); err != nil {
+			fmt.Printf("Unable to create series: %s\n", err)
+			os.Exit(1)
+		}
+
+		if !jsonOutput {
+			printSeries(&s)
+			return
+		}
+		if err := printJSON(s); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// updateSeriesCmd represents the update series command
+var updateSeriesCmd = &cobra.Command{
+	Use:   "series <id>",
+	Short: "Update a series on the configured Textonly host",
+	Long: `Updates the series with the given ID. Only the fields given as flags
+are changed, the rest are kept as they are. The posts flag replaces all
+parts of the series.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Println("ID must be an integer")
+			os.Exit(1)
+		}
+
+		current := SeriesResponse{}
+		if err := getSeries(id, &current); err != nil {
+			fmt.Printf("Unable to get series: %s\n", err)
+			os.Exit(1)
+		}
+		s := seriesRequest{
+			ID:          id,
+			Slug:        current.Data.Slug,
+			Title:       current.Data.Title,
+			Description: current.Data.Description,
+			PostIDs:     current.Data.PostIDs,
+		}
+
+		flags := cmd.Flags()
+		if flags.Changed("slug") {
+			s.Slug = updateSeriesFlags.slug
+		}
+		if flags.Changed("title") {
+			s.Title = updateSeriesFlags.title
+		}
+		if flags.Changed("description") {
+			s.Description = updateSeriesFlags.description
+		}
+		if flags.Changed("posts") {
+			s.PostIDs = updateSeriesFlags.posts
+		}
+
+		req, err := newRequest(http.MethodPut, "/api/series", s)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := do(req, nil); err != nil {
+			fmt.Printf("Unable to update series: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Series %d updated\n", id)
+	},
+}
+
+// deleteSeriesCmd represents the delete series command
+var deleteSeriesCmd = &cobra.Command{
+	Use:   "series <id>",
+	Short: "Delete a series from the configured Textonly host",
+	Long:  `Deletes the series with the given ID. Its posts are kept.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Println("ID must be an integer")
+			os.Exit(1)
+		}
+
+		req, err := newRequest(http.MethodDelete, fmt.Sprintf("/api/series/%d", id), nil)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := do(req, nil); err != nil {
+			fmt.Printf("Unable to delete series: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Series %d deleted\n", id)
+	},
+}
+
+func getSeries(id int, s *SeriesResponse) error {
+	req, err := newRequest(http.MethodGet, fmt.Sprintf("/api/series/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	return do(req, s)
+}
+
+func printSeries(s *data.Series) {
+	fmt.Printf(
+		"ID: %d, Slug: %s, Title: %s, Posts: %v\n",
+		s.ID,
+		s.Slug,
+		s.Title,
+		s.PostIDs,
+	)
+}
+
+func addSeriesFlags(cmd *cobra.Command, f *seriesFlags) {
+	cmd.Flags().StringVar(&f.slug, "slug", "", "URL slug of the series, served at /series/{slug}")
+	cmd.Flags().StringVar(&f.title, "title", "", "Title of the series")
+	cmd.Flags().StringVar(&f.description, "description", "", "Description of the series")
+	cmd.Flags().IntSliceVar(&f.posts, "posts", nil, "Comma separated IDs of the posts, in order")
+}
+
+func init() {
+	getSeriesCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output to JSON")
+	getCmd.AddCommand(getSeriesCmd)
+
+	addSeriesFlags(createSeriesCmd, &createSeriesFlags)
+	createSeriesCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output to JSON")
+	_ = createSeriesCmd.MarkFlagRequired("slug")
+	_ = createSeriesCmd.MarkFlagRequired("title")
+	createCmd.AddCommand(createSeriesCmd)
+
+	addSeriesFlags(updateSeriesCmd, &updateSeriesFlags)
+	updateCmd.AddCommand(updateSeriesCmd)
+
+	deleteCmd.AddCommand(deleteSeriesCmd)
+}
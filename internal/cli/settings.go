@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"textonly.islandwind.me/internal/data"
+)
+
+type SiteSettingsResponse struct {
+	Metadata data.Metadata     `json:"metadata"`
+	Data     data.SiteSettings `json:"data"`
+}
+
+// settingKeys are the settings that can be read and changed, in the order
+// they are printed.
+var settingKeys = []string{
+	"title", "tagline", "base_url", "default_author", "language", "footer_text",
+}
+
+// settingsCmd represents the settings command
+var settingsCmd = &cobra.Command{
+	Use:   "settings",
+	Short: "Read or change the site settings of the configured Textonly host",
+	Long: `The settings commands are used to read and change the site wide settings
+of the Textonly host, such as the site title, canonical base URL and language.
+
+Available keys: ` + strings.Join(settingKeys, ", "),
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("need subcommand")
+	},
+}
+
+// getSettingsCmd represents the settings get command
+var getSettingsCmd = &cobra.Command{
+	Use:   "get [key]",
+	Short: "Print the site settings",
+	Long: `Prints all site settings. If a key is given, only the value of that
+setting is printed.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := getSettings()
+		if err != nil {
+			fmt.Printf("Unable to get site settings: %s\n", err)
+			os.Exit(1)
+		}
+
+		if len(args) > 0 {
+			if !slices.Contains(settingKeys, args[0]) {
+				fmt.Printf("Unknown setting %q\n", args[0])
+				os.Exit(1)
+			}
+			fmt.Println(settingsToMap(&s.Data)[args[0]])
+			return
+		}
+
+		if !jsonOutput {
+			values := settingsToMap(&s.Data)
+			for _, key := range settingKeys {
+				fmt.Printf("%s: %s\n", key, values[key])
+			}
+			return
+		}
+		if err := printJSON(s); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// setSettingsCmd represents the settings set command
+var setSettingsCmd = &cobra.Command{
+	Use:   "set <key> <value> [<key> <value>...]",
+	Short: "Change one or more site settings",
+	Long: `Changes the given site settings. Settings that are not given keep their
+current value.
+
+Example:
+  toctl settings set title "My blog" base_url https://blog.example.com`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 || len(args)%2 != 0 {
+			return fmt.Errorf("expected one or more key and value pairs")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := getSettings()
+		if err != nil {
+			fmt.Printf("Unable to get site settings: %s\n", err)
+			os.Exit(1)
+		}
+
+		values := settingsToMap(&s.Data)
+		for i := 0; i < len(args); i += 2 {
+			if !slices.Contains(settingKeys, args[i]) {
+				fmt.Printf("Unknown setting %q\n", args[i])
+				os.Exit(1)
+			}
+			values[args[i]] = args[i+1]
+		}
+
+		req, err := newRequest(http.MethodPut, "/api/settings", values)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := do(req, nil); err != nil {
+			fmt.Printf("Unable to update site settings: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Site settings updated")
+	},
+}
+
+func getSettings() (*SiteSettingsResponse, error) {
+	req, err := newRequest(http.MethodGet, "/api/settings", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SiteSettingsResponse{}
+	if err := do(req, s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func settingsToMap(s *data.SiteSettings) map[string]string {
+	return map[string]string{
+		"title":          s.Title,
+		"tagline":        s.Tagline,
+		"base_url":       s.BaseURL,
+		"default_author": s.DefaultAuthor,
+		"language":       s.Language,
+		"footer_text":    s.FooterText,
+	}
+}
+
+func init() {
+	getSettingsCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output to JSON")
+	settingsCmd.AddCommand(getSettingsCmd)
+	settingsCmd.AddCommand(setSettingsCmd)
+	rootCmd.AddCommand(settingsCmd)
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"textonly.islandwind.me/internal/data"
+)
+
+// sharedLink is a share link as returned by the host, with the URL of the post
+// it grants access to.
+type sharedLink struct {
+	data.ShareLink
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+type ShareLinkResponse struct {
+	Data sharedLink `json:"data"`
+}
+
+type ShareLinkListResponse struct {
+	Data []sharedLink `json:"data"`
+}
+
+// shareExpires is how long a new share link is valid for. The default of the
+// host is used when it is empty.
+var shareExpires string
+
+// shareCmd represents the post share command
+var shareCmd = &cobra.Command{
+	Use:   "share <id>",
+	Short: "Share a post of the configured Textonly host through a link",
+	Long: `Creates a signed link to the post with the given ID. Anyone with the link
+can read the post, even when it is private, until the link expires or is
+revoked. The expires flag sets how long the link is valid for, as in 48h.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Println("ID must be an integer")
+			os.Exit(1)
+		}
+
+		body := struct {
+			Expires string `json:"expires,omitempty"`
+		}{Expires: shareExpires}
+
+		req, err := newRequest(http.MethodPost, fmt.Sprintf("/api/post/%d/share", id), body)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		l := ShareLinkResponse{}
+		if err := do(req, &l); err != nil {
+			fmt.Printf("Unable to share post: %s\n", err)
+			os.Exit(1)
+		}
+
+		if !jsonOutput {
+			printShareLink(l.Data)
+			return
+		}
+		if err := printJSON(l); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// sharesCmd represents the post shares command
+var sharesCmd = &cobra.Command{
+	Use:   "shares <id>",
+	Short: "List the share links of a post of the configured Textonly host",
+	Long: `Lists the share links to the post with the given ID, including the links
+that have expired or been revoked.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Println("ID must be an integer")
+			os.Exit(1)
+		}
+
+		req, err := newRequest(http.MethodGet, fmt.Sprintf("/api/post/%d/share", id), nil)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		links := ShareLinkListResponse{}
+		if err := do(req, &links); err != nil {
+			fmt.Printf("Unable to get share links: %s\n", err)
+			os.Exit(1)
+		}
+
+		if !jsonOutput {
+			for _, l := range links.Data {
+				printShareLink(l)
+			}
+			return
+		}
+		if err := printJSON(links); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// revokeCmd represents the post revoke command
+var revokeCmd = &cobra.Command{
+	Use:   "revoke <link-id>",
+	Short: "Revoke a share link on the configured Textonly host",
+	Long: `Revokes the share link with the given ID. The post can no longer be read
+through the link, even if it has not expired.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Println("ID must be an integer")
+			os.Exit(1)
+		}
+
+		req, err := newRequest(http.MethodDelete, fmt.Sprintf("/api/share/%d", id), nil)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := do(req, nil); err != nil {
+			fmt.Printf("Unable to revoke share link: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Share link %d revoked\n", id)
+	},
+}
+
+// printShareLink prints the share link on one line, followed by its URL.
+func printShareLink(l sharedLink) {
+	status := "active"
+	switch {
+	case l.Revoked != nil:
+		status = "revoked"
+	case !l.Active(time.Now()):
+		status = "expired"
+	}
+
+	fmt.Printf("ID: %d, Post: %d, Expires: %s, Status: %s\n", l.ID, l.PostID, l.Expires, status)
+	fmt.Printf("  %s\n", l.URL)
+}
+
+func init() {
+	shareCmd.Flags().StringVar(&shareExpires, "expires", "", "How long the link is valid for, as in 48h")
+	shareCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output to JSON")
+	postCmd.AddCommand(shareCmd)
+
+	sharesCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output to JSON")
+	postCmd.AddCommand(sharesCmd)
+
+	postCmd.AddCommand(revokeCmd)
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"textonly.islandwind.me/internal/data"
+)
+
+type StatsResponse struct {
+	Data data.SiteStats `json:"data"`
+}
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Print content statistics of the configured Textonly host",
+	Long: `Prints the number of posts and words of the Textonly host, the average
+number of days between posts, and a table of the posts and words per month.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		req, err := newRequest(http.MethodGet, "/api/stats", nil)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		s := &StatsResponse{}
+		if err := do(req, s); err != nil {
+			fmt.Printf("Unable to get stats: %s\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			if err := printJSON(s); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		fmt.Printf("Posts: %d\n", s.Data.TotalPosts)
+		fmt.Printf("Words: %d\n", s.Data.TotalWords)
+		fmt.Printf("Days between posts: %.1f\n\n", s.Data.Cadence)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(w, "MONTH\tPOSTS\tWORDS\t")
+		for _, m := range s.Data.Months {
+			fmt.Fprintf(w, "%s\t%d\t%d\t\n", m.Month, m.Posts, m.Words)
+		}
+		if err := w.Flush(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	statsCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output to JSON")
+	rootCmd.AddCommand(statsCmd)
+}
@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// updateCmd represents the update command
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Updates a resource in the Textonly application",
+	Long: `The update command is used to change an existing resource in the Textonly
+application. Only the fields given as flags are changed. Requires the user and
+password of the host to be set in the configuration file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("need subcommand")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(updateCmd)
+}
@@ -1,7 +1,10 @@
 package data
 
 import (
+	"encoding/json"
+	"fmt"
 	"math"
+	"slices"
 	"strings"
 	"time"
 
@@ -22,6 +25,7 @@ type Filters struct {
 	PageSize        int        `json:"page_size,omitempty"`
 	ID              *int       `json:"id,omitempty"`
 	UserID          *int       `json:"user_id,omitempty"`
+	Slug            string     `json:"slug,omitempty"`
 	Title           string     `json:"title,omitempty"`
 	Lead            string     `json:"lead,omitempty"`
 	Post            string     `json:"post,omitempty"`
@@ -29,10 +33,18 @@ type Filters struct {
 	CreatedTo       *time.Time `json:"created_to,omitempty"`
 	LastUpdatedFrom *time.Time `json:"last_updated_from,omitempty"`
 	LastUpdatedTo   *time.Time `json:"last_updated_to,omitempty"`
-	Name            string     `json:"name,omitempty"`
-	SocialPlatform  string     `json:"social_platform,omitempty"`
-	OrderBy         []string   `json:"order_by"`
-	OrderBySafeList []string   `json:"order_by_safe_list"`
+	Featured        *bool      `json:"featured,omitempty"`
+	Visible         *bool      `json:"visible,omitempty"`
+	Kind            string     `json:"kind,omitempty"`
+	Language        string     `json:"language,omitempty"`
+	Visibility      string     `json:"visibility,omitempty"`
+	Status          string     `json:"status,omitempty"`
+	// Meta maps custom fields to the value posts must have.
+	Meta            map[string]any `json:"meta,omitempty"`
+	Name            string         `json:"name,omitempty"`
+	SocialPlatform  string         `json:"social_platform,omitempty"`
+	OrderBy         []string       `json:"order_by"`
+	OrderBySafeList []string       `json:"order_by_safe_list"`
 }
 
 func ValidateFilters(v *validator.Validator, f Filters) {
@@ -42,6 +54,45 @@ func ValidateFilters(v *validator.Validator, f Filters) {
 
 	orderByParam, isPermitted := validator.PermittedValues(f.OrderBy, f.OrderBySafeList)
 	v.Check(isPermitted, orderByParam, "invalid order_by parameter")
+
+	if f.Kind != "" {
+		v.Check(
+			slices.Contains(PostKinds, f.Kind),
+			"kind",
+			fmt.Sprintf("must be one of %s", strings.Join(PostKinds, ", ")),
+		)
+	}
+	if f.Language != "" {
+		ValidateLanguage(v, "language", f.Language)
+	}
+	if f.Visibility != "" {
+		v.Check(
+			slices.Contains(Visibilities, f.Visibility),
+			"visibility",
+			fmt.Sprintf("must be one of %s", strings.Join(Visibilities, ", ")),
+		)
+	}
+	if f.Status != "" {
+		v.Check(
+			slices.Contains(Statuses, f.Status),
+			"status",
+			fmt.Sprintf("must be one of %s", strings.Join(Statuses, ", ")),
+		)
+	}
+}
+
+// metaJSON returns the custom field filters as a JSON object, or nil if there
+// are none.
+func (f Filters) metaJSON() any {
+	if len(f.Meta) == 0 {
+		return nil
+	}
+
+	b, err := json.Marshal(f.Meta)
+	if err != nil {
+		return nil
+	}
+	return string(b)
 }
 
 func (f Filters) limit() int {
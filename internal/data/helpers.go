@@ -1,7 +1,10 @@
 package data
 
 import (
+	"errors"
 	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 func CreateOrderByClause(orderBy []string) string {
@@ -20,3 +23,17 @@ func CreateOrderByClause(orderBy []string) string {
 
 	return "ORDER BY " + strings.Join(orderClauses, ", ")
 }
+
+// isUniqueViolation reports whether err is a PostgreSQL unique constraint
+// violation.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// isForeignKeyViolation reports whether err is a PostgreSQL foreign key
+// constraint violation.
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
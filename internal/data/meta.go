@@ -0,0 +1,185 @@
+package data
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"textonly.islandwind.me/internal/validator"
+)
+
+// The types of custom fields.
+const (
+	MetaString = "string"
+	MetaNumber = "number"
+	MetaBool   = "bool"
+	MetaURL    = "url"
+)
+
+// MetaTypes are the types a custom field can be of.
+var MetaTypes = []string{MetaString, MetaNumber, MetaBool, MetaURL}
+
+// MetaKeyRX matches the keys of custom fields, which templates can access as
+// in .BlogPost.Meta.cover.
+var MetaKeyRX = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// PostMeta holds the custom fields of a post, such as a cover image or a
+// license, in its JSON column.
+type PostMeta map[string]any
+
+func (pm *PostMeta) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*pm = PostMeta{}
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("unable to scan %T into meta", src)
+	}
+
+	return json.Unmarshal(b, (*map[string]any)(pm))
+}
+
+func (pm PostMeta) Value() (driver.Value, error) {
+	if pm == nil {
+		return "{}", nil
+	}
+
+	b, err := json.Marshal(map[string]any(pm))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// MetaField describes a custom field the configuration knows about. Fields
+// not described in the configuration can still be set, but are neither
+// validated nor filterable.
+type MetaField struct {
+	// Type is one of MetaTypes; string when it is empty.
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+	// Values lists the values a string field may have; any when it is empty.
+	Values []string `json:"values"`
+	// Filterable fields can be filtered by, as in ?meta.license=cc-by.
+	Filterable bool `json:"filterable"`
+}
+
+// MetaSchema maps the keys of custom fields to their description.
+type MetaSchema map[string]MetaField
+
+// Check reports the first invalid field description in the schema.
+func (s MetaSchema) Check() error {
+	for key, f := range s {
+		if !MetaKeyRX.MatchString(key) {
+			return fmt.Errorf("meta field %q: key must match %s", key, MetaKeyRX)
+		}
+		if f.Type != "" && !slices.Contains(MetaTypes, f.Type) {
+			return fmt.Errorf(
+				"meta field %q: type must be one of %s", key, strings.Join(MetaTypes, ", "),
+			)
+		}
+		if len(f.Values) > 0 && f.Type != "" && f.Type != MetaString {
+			return fmt.Errorf("meta field %q: values are only allowed for strings", key)
+		}
+	}
+
+	return nil
+}
+
+// FilterableKeys returns the sorted keys of the fields that can be filtered
+// by.
+func (s MetaSchema) FilterableKeys() []string {
+	keys := []string{}
+	for key, f := range s {
+		if f.Filterable {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	return keys
+}
+
+// ParseFilter converts the raw value of a filter by the custom field to the
+// type of the field.
+func (s MetaSchema) ParseFilter(key, raw string) (any, error) {
+	f, ok := s[key]
+	if !ok || !f.Filterable {
+		return nil, fmt.Errorf("is not a filterable field")
+	}
+
+	switch f.Type {
+	case MetaNumber:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return n, nil
+	case MetaBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be a boolean value")
+		}
+		return b, nil
+	default:
+		return raw, nil
+	}
+}
+
+// ValidateMeta checks the keys of the custom fields and the values of the
+// fields described in the schema.
+func ValidateMeta(v *validator.Validator, meta PostMeta, schema MetaSchema) {
+	for key := range meta {
+		v.Check(
+			MetaKeyRX.MatchString(key),
+			"meta",
+			fmt.Sprintf("key %q must only contain lowercase letters, digits and underscores", key),
+		)
+	}
+
+	for key, f := range schema {
+		field := "meta." + key
+		value, ok := meta[key]
+		if !ok || value == nil {
+			v.Check(!f.Required, field, "must be provided")
+			continue
+		}
+
+		switch f.Type {
+		case MetaNumber:
+			_, ok := value.(float64)
+			v.Check(ok, field, "must be a number")
+		case MetaBool:
+			_, ok := value.(bool)
+			v.Check(ok, field, "must be a boolean value")
+		case MetaURL:
+			s, _ := value.(string)
+			u, err := url.Parse(s)
+			v.Check(
+				err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+				field,
+				"must be an absolute http or https URL",
+			)
+		default:
+			s, ok := value.(string)
+			v.Check(ok, field, "must be a string")
+			if ok && len(f.Values) > 0 {
+				v.Check(
+					slices.Contains(f.Values, s),
+					field,
+					fmt.Sprintf("must be one of %s", strings.Join(f.Values, ", ")),
+				)
+			}
+		}
+	}
+}
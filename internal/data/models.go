@@ -8,10 +8,16 @@ import (
 
 var (
 	ErrRecordNotFound = errors.New("record not found")
+	ErrDuplicateSlug  = errors.New("duplicate slug")
 )
 
 type Models struct {
 	BlogPosts BlogPostModel
+	Pages     PageModel
+	Reviews   ReviewModel
+	Series    SeriesModel
+	Settings  SiteSettingsModel
+	Shares    ShareLinkModel
 	Socials   SocialModel
 	Users     UserModel
 }
@@ -19,6 +25,11 @@ type Models struct {
 func NewModels(db *sql.DB, timeout *time.Duration) Models {
 	return Models{
 		BlogPosts: BlogPostModel{DB: db, Timeout: timeout},
+		Pages:     PageModel{DB: db, Timeout: timeout},
+		Reviews:   ReviewModel{DB: db, Timeout: timeout},
+		Series:    SeriesModel{DB: db, Timeout: timeout},
+		Settings:  SiteSettingsModel{DB: db, Timeout: timeout},
+		Shares:    ShareLinkModel{DB: db, Timeout: timeout},
 		Socials:   SocialModel{DB: db, Timeout: timeout},
 		Users:     UserModel{DB: db, Timeout: timeout},
 	}
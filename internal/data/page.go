@@ -0,0 +1,336 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"time"
+
+	"textonly.islandwind.me/internal/utils"
+	"textonly.islandwind.me/internal/validator"
+)
+
+var SlugRX = regexp.MustCompile("^[a-z0-9]+(?:-[a-z0-9]+)*$")
+
+type Page struct {
+	ID          int        `json:"id"`
+	Slug        string     `json:"slug"`
+	Title       string     `json:"title"`
+	Content     string     `json:"content"`
+	NavPosition int        `json:"nav_position"`
+	ShowInNav   bool       `json:"show_in_nav"`
+	Visible     bool       `json:"visible"`
+	LastUpdate  *time.Time `json:"last_update,omitempty"`
+	Created     *time.Time `json:"created,omitempty"`
+}
+
+func ValidatePage(v *validator.Validator, p *Page) {
+	v.Check(validator.NotBlank(p.Slug), "slug", "must be provided")
+	v.Check(validator.MaxChars(p.Slug, 100), "slug", "must not be more than 100 characters long")
+	v.Check(
+		validator.Matches(p.Slug, SlugRX),
+		"slug",
+		"must only contain lowercase letters, digits and single dashes",
+	)
+	v.Check(validator.NotBlank(p.Title), "title", "must be provided")
+	v.Check(validator.MaxChars(p.Title, 255), "title", "must not be more than 255 characters long")
+}
+
+type PageModel struct {
+	Timeout *time.Duration
+	DB      *sql.DB
+}
+
+func (m *PageModel) Get(ctx context.Context, id int) (*Page, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	if id < 1 {
+		logger.InfoContext(ctx, "invalid id", "id", id)
+		return nil, ErrRecordNotFound
+	}
+
+	stmt := `SELECT id, slug, title, content, nav_position, show_in_nav, visible, last_update, created
+        FROM pages
+        WHERE id = $1;`
+
+	rCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "querying page", "query", stmt, "id", id)
+	p := &Page{}
+	err := m.DB.QueryRowContext(rCtx, stmt, id).Scan(
+		&p.ID,
+		&p.Slug,
+		&p.Title,
+		&p.Content,
+		&p.NavPosition,
+		&p.ShowInNav,
+		&p.Visible,
+		&p.LastUpdate,
+		&p.Created,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.InfoContext(ctx, "no records found", "query", stmt, "id", id)
+			return nil, ErrRecordNotFound
+		} else {
+			logger.ErrorContext(ctx, "unable to query page", "query", stmt, "id", id, "error", err)
+			return nil, err
+		}
+	}
+	logger.InfoContext(ctx, "data retrieved")
+
+	return p, nil
+}
+
+func (m *PageModel) GetBySlug(ctx context.Context, slug string) (*Page, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	stmt := `SELECT id, slug, title, content, nav_position, show_in_nav, visible, last_update, created
+        FROM pages
+        WHERE slug = $1;`
+
+	rCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "querying page", "query", stmt, "slug", slug)
+	p := &Page{}
+	err := m.DB.QueryRowContext(rCtx, stmt, slug).Scan(
+		&p.ID,
+		&p.Slug,
+		&p.Title,
+		&p.Content,
+		&p.NavPosition,
+		&p.ShowInNav,
+		&p.Visible,
+		&p.LastUpdate,
+		&p.Created,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.InfoContext(ctx, "no records found", "query", stmt, "slug", slug)
+			return nil, ErrRecordNotFound
+		} else {
+			logger.ErrorContext(
+				ctx, "unable to query page", "query", stmt, "slug", slug, "error", err,
+			)
+			return nil, err
+		}
+	}
+	logger.InfoContext(ctx, "data retrieved")
+
+	return p, nil
+}
+
+func (m *PageModel) GetAll(ctx context.Context, filters Filters) ([]*Page, Metadata, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	stmt := `
+        SELECT COUNT(*) OVER(), id, slug, title, content, nav_position, show_in_nav, visible,
+            last_update, created
+        FROM pages
+        WHERE
+            ($1::int IS NULL OR id = $1)
+            AND ($2 = '' OR slug = $2)
+            AND ($3 = '' OR title LIKE ('%' || $3 || '%'))
+            AND ($4::boolean IS NULL OR visible = $4)
+        ` + CreateOrderByClause(filters.OrderBy) + `
+        LIMIT $5 OFFSET $6;`
+
+	rCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "querying pages", "query", stmt, "filters", filters)
+	rows, err := m.DB.QueryContext(
+		rCtx,
+		stmt,
+		filters.ID,
+		filters.Slug,
+		filters.Title,
+		filters.Visible,
+		filters.limit(),
+		filters.offset(),
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query pages", "query", stmt, "error", err)
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	pages := []*Page{}
+	for rows.Next() {
+		p := &Page{}
+		err = rows.Scan(
+			&totalRecords,
+			&p.ID,
+			&p.Slug,
+			&p.Title,
+			&p.Content,
+			&p.NavPosition,
+			&p.ShowInNav,
+			&p.Visible,
+			&p.LastUpdate,
+			&p.Created,
+		)
+		if err != nil {
+			logger.ErrorContext(ctx, "unable to query pages", "query", stmt, "error", err)
+			return nil, Metadata{}, err
+		}
+		pages = append(pages, p)
+	}
+	if err = rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "unable to query pages", "query", stmt, "error", err)
+		return nil, Metadata{}, err
+	}
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize, filters.OrderBy)
+	logger.InfoContext(ctx, "data retrieved", "metadata", metadata)
+
+	return pages, metadata, nil
+}
+
+// Navigation returns the visible pages marked for the navigation bar, ordered
+// by their navigation position. The page content is not included.
+func (m *PageModel) Navigation(ctx context.Context) ([]*Page, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	stmt := `
+        SELECT id, slug, title, nav_position
+        FROM pages
+        WHERE show_in_nav AND visible
+        ORDER BY nav_position, title;`
+
+	rCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "querying navigation pages", "query", stmt)
+	rows, err := m.DB.QueryContext(rCtx, stmt)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query navigation pages", "query", stmt, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	pages := []*Page{}
+	for rows.Next() {
+		p := &Page{ShowInNav: true, Visible: true}
+		err = rows.Scan(&p.ID, &p.Slug, &p.Title, &p.NavPosition)
+		if err != nil {
+			logger.ErrorContext(
+				ctx, "unable to query navigation pages", "query", stmt, "error", err,
+			)
+			return nil, err
+		}
+		pages = append(pages, p)
+	}
+	if err = rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "unable to query navigation pages", "query", stmt, "error", err)
+		return nil, err
+	}
+	logger.InfoContext(ctx, "data retrieved", "pages", len(pages))
+
+	return pages, nil
+}
+
+func (m *PageModel) Insert(ctx context.Context, p *Page) (Page, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	query := `INSERT INTO pages (
+        slug, title, content, nav_position, show_in_nav, visible
+        )
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, last_update, created;`
+
+	args := []any{p.Slug, p.Title, p.Content, p.NavPosition, p.ShowInNav, p.Visible}
+
+	rCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "inserting page", "query", query, "args", args)
+	err := m.DB.QueryRowContext(rCtx, query, args...).Scan(&p.ID, &p.LastUpdate, &p.Created)
+	if err != nil {
+		if isUniqueViolation(err) {
+			logger.InfoContext(ctx, "slug already exists", "slug", p.Slug)
+			return *p, ErrDuplicateSlug
+		}
+		logger.ErrorContext(
+			ctx, "unable to insert page", "query", query, "args", args, "error", err,
+		)
+		return *p, err
+	}
+	logger.InfoContext(ctx, "page inserted", "id", p.ID)
+
+	return *p, nil
+}
+
+func (m *PageModel) Update(ctx context.Context, p *Page) (rowsAffected int64, err error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	query := `UPDATE pages
+        SET slug = $2, title = $3, content = $4, nav_position = $5, show_in_nav = $6,
+            visible = $7, last_update = NOW()
+        WHERE id = $1;`
+
+	args := []any{p.ID, p.Slug, p.Title, p.Content, p.NavPosition, p.ShowInNav, p.Visible}
+
+	rCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "updating page", "query", query, "args", args)
+	result, err := m.DB.ExecContext(rCtx, query, args...)
+	if err != nil {
+		if isUniqueViolation(err) {
+			logger.InfoContext(ctx, "slug already exists", "slug", p.Slug)
+			return 0, ErrDuplicateSlug
+		}
+		logger.ErrorContext(
+			ctx, "unable to update page", "query", query, "args", args, "error", err,
+		)
+		return 0, err
+	}
+
+	rowsAffected, err = result.RowsAffected()
+	if err != nil {
+		logger.ErrorContext(
+			ctx, "unable to update page", "query", query, "args", args, "error", err,
+		)
+		return 0, err
+	}
+	if rowsAffected == 0 {
+		logger.InfoContext(ctx, "no records found", "query", query, "args", args)
+		return 0, ErrRecordNotFound
+	}
+	logger.InfoContext(ctx, "page updated", "id", p.ID)
+
+	return rowsAffected, nil
+}
+
+func (m *PageModel) Delete(ctx context.Context, id int) (rowsAffected int64, err error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	query := "DELETE FROM pages WHERE id = $1;"
+
+	rCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "deleting page", "query", query, "id", id)
+	result, err := m.DB.ExecContext(rCtx, query, id)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to delete page", "id", id, "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err = result.RowsAffected()
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to delete page", "id", id, "error", err)
+		return 0, err
+	}
+	if rowsAffected == 0 {
+		logger.InfoContext(ctx, "no records found", "id", id)
+		return 0, ErrRecordNotFound
+	}
+	logger.InfoContext(ctx, "page deleted", "id", id)
+
+	return rowsAffected, nil
+}
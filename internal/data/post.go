@@ -6,21 +6,74 @@ import (
 	"errors"
 	"time"
 
+	"textonly.islandwind.me/internal/markdown"
 	"textonly.islandwind.me/internal/utils"
 )
 
 type BlogPost struct {
-	ID         int        `json:"id"`
-	Title      string     `json:"title"`
-	Lead       string     `json:"lead"`
-	Post       string     `json:"post"`
+	ID int `json:"id"`
+	// Slug identifies the post in wiki links, as in [[post:slug]].
+	Slug string `json:"slug"`
+	// Kind is one of PostKinds.
+	Kind string `json:"kind"`
+	// Visibility is one of Visibilities. Only public posts are listed.
+	Visibility string `json:"visibility"`
+	// Status is one of Statuses. Only published posts are on the site.
+	Status string `json:"status"`
+	// Language is the language tag of the post, as in "en" or "nb".
+	Language string `json:"language"`
+	// TranslationOf is the ID of the original post this post translates.
+	// Translations always refer to the original, never to another
+	// translation.
+	TranslationOf *int   `json:"translation_of,omitempty"`
+	Title         string `json:"title"`
+	Lead          string `json:"lead"`
+	Post          string `json:"post"`
+	// LinkURL is the page a link post shares, and Quote an optional quote
+	// from it.
+	LinkURL  string `json:"link_url,omitempty"`
+	Quote    string `json:"quote,omitempty"`
+	Featured bool   `json:"featured"`
+	Renderer string `json:"renderer"`
+	// Meta holds the custom fields of the post.
+	Meta PostMeta `json:"meta,omitempty"`
+	// HTML is the sanitized HTML of the post, rendered when it was stored.
+	HTML string `json:"html,omitempty"`
+	// Excerpt is the beginning of the text of the rendered post.
+	Excerpt string `json:"excerpt,omitempty"`
+	PostStats
+	// Outline lists the headings of the rendered post.
+	Outline []markdown.Heading `json:"outline,omitempty"`
+	// Dynamic reports whether the rendered post shows other posts, as the
+	// shortcodes listing posts do, so it is rendered again when posts change.
+	Dynamic    bool       `json:"-"`
 	LastUpdate *time.Time `json:"last_update,omitempty"`
 	Created    *time.Time `json:"created,omitempty"`
 }
 
+// PostStats are counted in the rendered post.
+type PostStats struct {
+	WordCount int `json:"word_count"`
+	// ReadingTime is the estimated time it takes to read the post, in
+	// minutes.
+	ReadingTime   int `json:"reading_time"`
+	CodeBlocks    int `json:"code_blocks"`
+	OutboundLinks int `json:"outbound_links"`
+	Images        int `json:"images"`
+}
+
 type BlogPostModel struct {
 	Timeout *time.Duration
 	DB      *sql.DB
+	// Render renders posts before they are stored. Without it, posts are
+	// stored without HTML.
+	Render PostRenderer
+	// RequireApproval sends posts back to draft when their title, lead or
+	// text changes, so that only content a reviewer approved is published.
+	RequireApproval bool
+	// relate asks the goroutine started by RefreshRelatedInBackground for a
+	// refresh of the related posts.
+	relate chan struct{}
 }
 
 func (m *BlogPostModel) Get(ctx context.Context, id int) (*BlogPost, error) {
@@ -30,7 +83,7 @@ func (m *BlogPostModel) Get(ctx context.Context, id int) (*BlogPost, error) {
 		logger.InfoContext(ctx, "invalid id", "id", id)
 		return nil, ErrRecordNotFound
 	}
-	stmt := `SELECT id, title, lead, post, last_update, created
+	stmt := `SELECT id, slug, kind, visibility, status, language, translation_of, title, lead, post, link_url, quote, featured, renderer, meta, html, excerpt, word_count, reading_time, code_blocks, outbound_links, images, outline, last_update, created
 FROM posts
 WHERE id = $1;`
 
@@ -43,9 +96,28 @@ WHERE id = $1;`
 
 	err := row.Scan(
 		&blogPost.ID,
+		&blogPost.Slug,
+		&blogPost.Kind,
+		&blogPost.Visibility,
+		&blogPost.Status,
+		&blogPost.Language,
+		&blogPost.TranslationOf,
 		&blogPost.Title,
 		&blogPost.Lead,
 		&blogPost.Post,
+		&blogPost.LinkURL,
+		&blogPost.Quote,
+		&blogPost.Featured,
+		&blogPost.Renderer,
+		&blogPost.Meta,
+		&blogPost.HTML,
+		&blogPost.Excerpt,
+		&blogPost.WordCount,
+		&blogPost.ReadingTime,
+		&blogPost.CodeBlocks,
+		&blogPost.OutboundLinks,
+		&blogPost.Images,
+		(*postOutline)(&blogPost.Outline),
 		&blogPost.LastUpdate,
 		&blogPost.Created,
 	)
@@ -63,6 +135,58 @@ WHERE id = $1;`
 	return blogPost, nil
 }
 
+func (m *BlogPostModel) GetBySlug(ctx context.Context, slug string) (*BlogPost, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	stmt := `SELECT id, slug, kind, visibility, status, language, translation_of, title, lead, post, link_url, quote, featured, renderer, meta, html, excerpt, word_count, reading_time, code_blocks, outbound_links, images, outline, last_update, created
+FROM posts
+WHERE slug = $1;`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(qCtx, "querying blogpost", "query", stmt, "slug", slug)
+	blogPost := &BlogPost{}
+	err := m.DB.QueryRowContext(qCtx, stmt, slug).Scan(
+		&blogPost.ID,
+		&blogPost.Slug,
+		&blogPost.Kind,
+		&blogPost.Visibility,
+		&blogPost.Status,
+		&blogPost.Language,
+		&blogPost.TranslationOf,
+		&blogPost.Title,
+		&blogPost.Lead,
+		&blogPost.Post,
+		&blogPost.LinkURL,
+		&blogPost.Quote,
+		&blogPost.Featured,
+		&blogPost.Renderer,
+		&blogPost.Meta,
+		&blogPost.HTML,
+		&blogPost.Excerpt,
+		&blogPost.WordCount,
+		&blogPost.ReadingTime,
+		&blogPost.CodeBlocks,
+		&blogPost.OutboundLinks,
+		&blogPost.Images,
+		(*postOutline)(&blogPost.Outline),
+		&blogPost.LastUpdate,
+		&blogPost.Created,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.InfoContext(ctx, "no records found", "query", stmt, "slug", slug)
+			return nil, ErrNoRecord
+		}
+		logger.InfoContext(ctx, "unable to query blogpost", "query", stmt, "slug", slug)
+		return nil, err
+	}
+	logger.InfoContext(ctx, "data retrieved")
+
+	return blogPost, nil
+}
+
 func (m *BlogPostModel) GetAll(
 	ctx context.Context,
 	filters Filters,
@@ -70,7 +194,7 @@ func (m *BlogPostModel) GetAll(
 	logger := utils.LoggerFromContext(ctx)
 
 	stmt := `
-        SELECT COUNT(*) OVER(), id, title, lead, post, last_update, created
+        SELECT COUNT(*) OVER(), id, slug, kind, visibility, status, language, translation_of, title, lead, post, link_url, quote, featured, renderer, meta, html, excerpt, word_count, reading_time, code_blocks, outbound_links, images, outline, last_update, created
         FROM posts
         WHERE
             ($1::int IS NULL OR id = $1)
@@ -81,8 +205,14 @@ func (m *BlogPostModel) GetAll(
             AND ($6::timestamp IS NULL OR created <= $6)
             AND ($7::timestamp IS NULL OR last_update >= $7)
             AND ($8::timestamp IS NULL OR last_update <= $8)
+            AND ($9::boolean IS NULL OR featured = $9)
+            AND ($10 = '' OR kind = $10)
+            AND ($11::jsonb IS NULL OR meta @> $11::jsonb)
+            AND ($12 = '' OR language = $12)
+            AND ($13 = '' OR visibility = $13)
+            AND ($14 = '' OR status = $14)
         ` + CreateOrderByClause(filters.OrderBy) + `
-        LIMIT $9 OFFSET $10;`
+        LIMIT $15 OFFSET $16;`
 
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
@@ -99,6 +229,12 @@ func (m *BlogPostModel) GetAll(
 		filters.CreatedTo,
 		filters.LastUpdatedFrom,
 		filters.LastUpdatedTo,
+		filters.Featured,
+		filters.Kind,
+		filters.metaJSON(),
+		filters.Language,
+		filters.Visibility,
+		filters.Status,
 		filters.limit(),
 		filters.offset(),
 	)
@@ -115,9 +251,28 @@ func (m *BlogPostModel) GetAll(
 		err = rows.Scan(
 			&totalRecords,
 			&blogPost.ID,
+			&blogPost.Slug,
+			&blogPost.Kind,
+			&blogPost.Visibility,
+			&blogPost.Status,
+			&blogPost.Language,
+			&blogPost.TranslationOf,
 			&blogPost.Title,
 			&blogPost.Lead,
 			&blogPost.Post,
+			&blogPost.LinkURL,
+			&blogPost.Quote,
+			&blogPost.Featured,
+			&blogPost.Renderer,
+			&blogPost.Meta,
+			&blogPost.HTML,
+			&blogPost.Excerpt,
+			&blogPost.WordCount,
+			&blogPost.ReadingTime,
+			&blogPost.CodeBlocks,
+			&blogPost.OutboundLinks,
+			&blogPost.Images,
+			(*postOutline)(&blogPost.Outline),
 			&blogPost.LastUpdate,
 			&blogPost.Created,
 		)
@@ -137,12 +292,14 @@ func (m *BlogPostModel) GetAll(
 	return blogPosts, metadata, nil
 }
 
+// LastN returns the limit newest public, published posts.
 func (m *BlogPostModel) LastN(ctx context.Context, limit int) ([]*BlogPost, error) {
 	logger := utils.LoggerFromContext(ctx)
 
 	stmt := `
-        SELECT id, title, lead, post, last_update, created
+        SELECT id, slug, kind, visibility, status, language, translation_of, title, lead, post, link_url, quote, featured, renderer, meta, html, excerpt, word_count, reading_time, code_blocks, outbound_links, images, outline, last_update, created
         FROM posts
+        WHERE visibility = 'public' AND status = 'published'
         ORDER BY id DESC
         LIMIT $1;`
 
@@ -159,9 +316,28 @@ func (m *BlogPostModel) LastN(ctx context.Context, limit int) ([]*BlogPost, erro
 		blogPost := &BlogPost{}
 		err = rows.Scan(
 			&blogPost.ID,
+			&blogPost.Slug,
+			&blogPost.Kind,
+			&blogPost.Visibility,
+			&blogPost.Status,
+			&blogPost.Language,
+			&blogPost.TranslationOf,
 			&blogPost.Title,
 			&blogPost.Lead,
 			&blogPost.Post,
+			&blogPost.LinkURL,
+			&blogPost.Quote,
+			&blogPost.Featured,
+			&blogPost.Renderer,
+			&blogPost.Meta,
+			&blogPost.HTML,
+			&blogPost.Excerpt,
+			&blogPost.WordCount,
+			&blogPost.ReadingTime,
+			&blogPost.CodeBlocks,
+			&blogPost.OutboundLinks,
+			&blogPost.Images,
+			(*postOutline)(&blogPost.Outline),
 			&blogPost.LastUpdate,
 			&blogPost.Created,
 		)
@@ -191,25 +367,54 @@ func (m *BlogPostModel) Insert(ctx context.Context, bp *BlogPost) (BlogPost, err
 	logger := utils.LoggerFromContext(ctx)
 
 	query := `INSERT INTO posts (
-        title, lead, post
+        slug, title, lead, post, featured, renderer, kind, link_url, quote, meta, language,
+        translation_of, visibility, status
         )
-        VALUES ($1, $2, $3)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11,
+            (SELECT COALESCE(translation_of, id) FROM posts WHERE id = $12), $13,
+            COALESCE(NULLIF($14, ''), 'published'))
         RETURNING id, last_update, created;`
 
 	args := []any{
+		bp.Slug,
 		bp.Title,
 		bp.Lead,
 		bp.Post,
+		bp.Featured,
+		bp.Renderer,
+		bp.Kind,
+		bp.LinkURL,
+		bp.Quote,
+		bp.Meta,
+		bp.Language,
+		bp.TranslationOf,
+		bp.Visibility,
+		bp.Status,
+	}
+
+	if err := m.render(ctx, bp); err != nil {
+		return *bp, err
 	}
 
 	rCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
+	tx, err := m.DB.BeginTx(rCtx, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to begin transaction", "error", err)
+		return *bp, err
+	}
+	defer tx.Rollback()
+
 	logger.InfoContext(rCtx, "inserting blogpost", "query", query, "args", args)
-	err := m.DB.QueryRowContext(ctx, query, args...).Scan(
+	err = tx.QueryRowContext(rCtx, query, args...).Scan(
 		&bp.ID, &bp.LastUpdate, &bp.Created,
 	)
 	if err != nil {
+		if isUniqueViolation(err) {
+			logger.InfoContext(ctx, "slug already exists", "slug", bp.Slug)
+			return *bp, ErrDuplicateSlug
+		}
 		logger.ErrorContext(
 			ctx,
 			"unable to insert blogpost",
@@ -222,8 +427,22 @@ func (m *BlogPostModel) Insert(ctx context.Context, bp *BlogPost) (BlogPost, err
 		)
 		return *bp, err
 	}
+
+	if err = storeRendered(rCtx, tx, bp); err != nil {
+		return *bp, err
+	}
+	if err = replacePostLinks(rCtx, tx, bp.ID, bp.Post); err != nil {
+		return *bp, err
+	}
+	if err = tx.Commit(); err != nil {
+		logger.ErrorContext(ctx, "unable to commit transaction", "error", err)
+		return *bp, err
+	}
 	logger.InfoContext(ctx, "blogpost inserted", "id", bp.ID)
 
+	m.rerenderDependents(ctx, bp.ID)
+	m.refreshRelated(ctx)
+
 	return *bp, nil
 }
 
@@ -231,7 +450,16 @@ func (m *BlogPostModel) Update(ctx context.Context, bp *BlogPost) (rowsAffected
 	logger := utils.LoggerFromContext(ctx)
 
 	query := `UPDATE posts
-        SET title = $2, lead = $3, post = $4, last_update = NOW(), created = $5
+        SET title = $2, lead = $3, post = $4, featured = $5, last_update = NOW(), created = $6,
+            renderer = COALESCE(NULLIF($7, ''), renderer), slug = COALESCE(NULLIF($8, ''), slug),
+            kind = $9, link_url = $10, quote = $11, meta = $12,
+            language = COALESCE(NULLIF($13, ''), language),
+            translation_of = (SELECT COALESCE(translation_of, id) FROM posts WHERE id = $14),
+            visibility = COALESCE(NULLIF($15, ''), visibility),
+            status = CASE
+                WHEN $16::boolean AND (title, lead, post) IS DISTINCT FROM ($2, $3, $4) THEN 'draft'
+                ELSE status
+            END
         WHERE id = $1
     `
 
@@ -240,19 +468,40 @@ func (m *BlogPostModel) Update(ctx context.Context, bp *BlogPost) (rowsAffected
 		bp.Title,
 		bp.Lead,
 		bp.Post,
+		bp.Featured,
 		bp.Created,
+		bp.Renderer,
+		bp.Slug,
+		bp.Kind,
+		bp.LinkURL,
+		bp.Quote,
+		bp.Meta,
+		bp.Language,
+		bp.TranslationOf,
+		bp.Visibility,
+		m.RequireApproval,
 	}
 
 	rCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
+	tx, err := m.DB.BeginTx(rCtx, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to begin transaction", "error", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
 	logger.InfoContext(ctx, "updating blogpost", "query", query, "args", args)
-	result, err := m.DB.ExecContext(rCtx, query, args...)
+	result, err := tx.ExecContext(rCtx, query, args...)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
 			logger.InfoContext(ctx, "no records found", "query", query, "args", args)
 			return 0, ErrNoRecord
+		case isUniqueViolation(err):
+			logger.InfoContext(ctx, "slug already exists", "slug", bp.Slug)
+			return 0, ErrDuplicateSlug
 		default:
 			logger.ErrorContext(
 				ctx,
@@ -286,8 +535,50 @@ func (m *BlogPostModel) Update(ctx context.Context, bp *BlogPost) (rowsAffected
 		logger.InfoContext(ctx, "no records found", "query", query, "args", args)
 		return 0, ErrRecordNotFound
 	}
+
+	// the translations of a post that becomes a translation move to the
+	// original it translates
+	if bp.TranslationOf != nil {
+		_, err = tx.ExecContext(
+			rCtx,
+			`UPDATE posts SET translation_of = (SELECT translation_of FROM posts WHERE id = $1)
+            WHERE translation_of = $1;`,
+			bp.ID,
+		)
+		if err != nil {
+			logger.ErrorContext(ctx, "unable to move translations", "id", bp.ID, "error", err)
+			return 0, err
+		}
+	}
+
+	// an empty renderer or visibility keeps the one the post has, and the
+	// links in the post depend on who can read it, so the post is rendered
+	// with what is stored
+	err = tx.QueryRowContext(
+		rCtx, "SELECT renderer, visibility, status FROM posts WHERE id = $1;", bp.ID,
+	).Scan(&bp.Renderer, &bp.Visibility, &bp.Status)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query renderer", "id", bp.ID, "error", err)
+		return 0, err
+	}
+	if err = m.render(ctx, bp); err != nil {
+		return 0, err
+	}
+	if err = storeRendered(rCtx, tx, bp); err != nil {
+		return 0, err
+	}
+	if err = replacePostLinks(rCtx, tx, bp.ID, bp.Post); err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(); err != nil {
+		logger.ErrorContext(ctx, "unable to commit transaction", "error", err)
+		return 0, err
+	}
 	logger.InfoContext(ctx, "blogpost updated", "id", bp.ID)
 
+	m.rerenderDependents(ctx, bp.ID)
+	m.refreshRelated(ctx)
+
 	return rowsAffected, nil
 }
 
@@ -296,6 +587,16 @@ func (m *BlogPostModel) Delete(ctx context.Context, id int) (rowsAffected int64,
 
 	query := "DELETE FROM posts WHERE id = $1;"
 
+	// the posts showing the post are found while it exists, so that their
+	// links show as broken and their lists leave it out once it is gone
+	var dependents []int
+	if m.Render != nil {
+		dependents, err = m.dependents(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+	}
+
 	rCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
@@ -335,5 +636,8 @@ func (m *BlogPostModel) Delete(ctx context.Context, id int) (rowsAffected int64,
 	}
 	logger.InfoContext(ctx, "blogpost deleted", "id", id)
 
+	m.rerenderLogged(ctx, dependents)
+	m.refreshRelated(ctx)
+
 	return rowsAffected, nil
 }
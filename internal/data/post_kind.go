@@ -0,0 +1,105 @@
+package data
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"textonly.islandwind.me/internal/markdown"
+	"textonly.islandwind.me/internal/validator"
+)
+
+// The kinds of posts.
+const (
+	// KindArticle is a full post with a title.
+	KindArticle = "article"
+	// KindNote is a short post, which needs no title.
+	KindNote = "note"
+	// KindLink shares the page at its link URL, with an optional quote from
+	// it and commentary in its text.
+	KindLink = "link"
+)
+
+// PostKinds are the kinds a post can be of.
+var PostKinds = []string{KindArticle, KindNote, KindLink}
+
+// displayTitleLength is the maximum number of characters in the title shown
+// for posts without one.
+const displayTitleLength = 60
+
+// ValidateBlogPost checks the fields the kind of the post requires.
+func ValidateBlogPost(v *validator.Validator, bp *BlogPost) {
+	v.Check(
+		slices.Contains(PostKinds, bp.Kind),
+		"kind",
+		fmt.Sprintf("must be one of %s", strings.Join(PostKinds, ", ")),
+	)
+	v.Check(validator.MaxChars(bp.Title, 255), "title", "must not be more than 255 characters long")
+	if bp.Language != "" {
+		ValidateLanguage(v, "language", bp.Language)
+	}
+	if bp.Visibility != "" {
+		v.Check(
+			slices.Contains(Visibilities, bp.Visibility),
+			"visibility",
+			fmt.Sprintf("must be one of %s", strings.Join(Visibilities, ", ")),
+		)
+	}
+
+	switch bp.Kind {
+	case KindArticle:
+		v.Check(validator.NotBlank(bp.Title), "title", "must be provided")
+	case KindNote:
+		v.Check(validator.NotBlank(bp.Post), "post", "must be provided")
+	case KindLink:
+		u, err := url.Parse(bp.LinkURL)
+		v.Check(
+			err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"link_url",
+			"must be an absolute http or https URL",
+		)
+		v.Check(
+			validator.MaxChars(bp.LinkURL, 2000),
+			"link_url",
+			"must not be more than 2000 characters long",
+		)
+	}
+
+	if bp.Kind != KindLink {
+		v.Check(bp.LinkURL == "", "link_url", "must only be provided for links")
+		v.Check(bp.Quote == "", "quote", "must only be provided for links")
+	}
+}
+
+// DisplayTitle returns the title of the post or, for posts without one, the
+// host of the link or the beginning of the lead or text.
+func (bp *BlogPost) DisplayTitle() string {
+	if bp.Title != "" {
+		return bp.Title
+	}
+	if bp.Kind == KindLink {
+		if host := bp.LinkHost(); host != "" {
+			return host
+		}
+	}
+	// posts that have not been rendered yet only have their markdown
+	for _, text := range []string{bp.Lead, bp.Excerpt, bp.Post} {
+		if text != "" {
+			return markdown.Excerpt(text, displayTitleLength)
+		}
+	}
+
+	return fmt.Sprintf("Note #%d", bp.ID)
+}
+
+// LinkHost returns the host of the link URL of the post, without a leading
+// "www.".
+func (bp *BlogPost) LinkHost() string {
+	u, err := url.Parse(bp.LinkURL)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
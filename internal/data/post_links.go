@@ -0,0 +1,187 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"unicode"
+
+	"textonly.islandwind.me/internal/markdown"
+	"textonly.islandwind.me/internal/utils"
+	"textonly.islandwind.me/internal/validator"
+)
+
+// PostLink is a wiki link from a post to another post. The target is written
+// as in the markdown: a slug prefixed with post:, or an ID.
+type PostLink struct {
+	SourceID    int    `json:"source_id"`
+	SourceTitle string `json:"source_title"`
+	Target      string `json:"target"`
+}
+
+// postLinkJoin matches a link to the post it targets.
+const postLinkJoin = `(l.target = t.id::text OR l.target = 'post:' || t.slug)`
+
+// replacePostLinks stores the targets of the wiki links in the post, replacing
+// the ones stored before.
+func replacePostLinks(ctx context.Context, tx *sql.Tx, id int, post string) error {
+	logger := utils.LoggerFromContext(ctx)
+
+	_, err := tx.ExecContext(ctx, "DELETE FROM post_links WHERE source_id = $1;", id)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to delete post links", "id", id, "error", err)
+		return err
+	}
+
+	targets := markdown.WikiLinks([]byte(post))
+	for _, target := range targets {
+		_, err = tx.ExecContext(
+			ctx,
+			"INSERT INTO post_links (source_id, target) VALUES ($1, $2);",
+			id, target,
+		)
+		if err != nil {
+			logger.ErrorContext(
+				ctx, "unable to insert post link", "id", id, "target", target, "error", err,
+			)
+			return err
+		}
+	}
+	logger.InfoContext(ctx, "post links stored", "id", id, "links", len(targets))
+
+	return nil
+}
+
+// Backlinks returns the posts that link to the post, with their ID, slug,
+// kind, visibility, status and the fields their display title is made of.
+func (m *BlogPostModel) Backlinks(ctx context.Context, id int) ([]*BlogPost, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	stmt := `
+        SELECT DISTINCT p.id, p.slug, p.kind, p.visibility, p.status, p.title, p.lead, p.link_url,
+            p.excerpt
+        FROM post_links l
+        JOIN posts p ON p.id = l.source_id
+        JOIN posts t ON ` + postLinkJoin + `
+        WHERE t.id = $1 AND p.id <> $1
+        ORDER BY p.id;`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "querying backlinks", "query", stmt, "id", id)
+	rows, err := m.DB.QueryContext(qCtx, stmt, id)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query backlinks", "query", stmt, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []*BlogPost{}
+	for rows.Next() {
+		p := &BlogPost{}
+		err = rows.Scan(
+			&p.ID, &p.Slug, &p.Kind, &p.Visibility, &p.Status, &p.Title, &p.Lead, &p.LinkURL, &p.Excerpt,
+		)
+		if err != nil {
+			logger.ErrorContext(ctx, "unable to query backlinks", "query", stmt, "error", err)
+			return nil, err
+		}
+		posts = append(posts, p)
+	}
+	if err = rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "unable to query backlinks", "query", stmt, "error", err)
+		return nil, err
+	}
+	logger.InfoContext(ctx, "data retrieved", "backlinks", len(posts))
+
+	return posts, nil
+}
+
+// BrokenLinks returns the wiki links whose target does not exist, and the
+// links of posts everyone can read to restricted posts, which are shown as
+// broken too.
+func (m *BlogPostModel) BrokenLinks(ctx context.Context) ([]*PostLink, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	stmt := `
+        SELECT l.source_id, p.title, l.target
+        FROM post_links l
+        JOIN posts p ON p.id = l.source_id
+        WHERE NOT EXISTS (
+            SELECT 1 FROM posts t
+            WHERE ` + postLinkJoin + `
+                AND (p.visibility = 'private' OR p.status <> 'published'
+                    OR t.visibility <> 'private' AND t.status = 'published')
+        )
+        ORDER BY l.source_id, l.target;`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "querying broken links", "query", stmt)
+	rows, err := m.DB.QueryContext(qCtx, stmt)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query broken links", "query", stmt, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []*PostLink{}
+	for rows.Next() {
+		l := &PostLink{}
+		if err = rows.Scan(&l.SourceID, &l.SourceTitle, &l.Target); err != nil {
+			logger.ErrorContext(ctx, "unable to query broken links", "query", stmt, "error", err)
+			return nil, err
+		}
+		links = append(links, l)
+	}
+	if err = rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "unable to query broken links", "query", stmt, "error", err)
+		return nil, err
+	}
+	logger.InfoContext(ctx, "data retrieved", "broken_links", len(links))
+
+	return links, nil
+}
+
+// ValidatePostSlug checks that the slug of a post can be used in wiki links.
+func ValidatePostSlug(v *validator.Validator, slug string) {
+	v.Check(validator.MaxChars(slug, 100), "slug", "must not be more than 100 characters long")
+	v.Check(
+		validator.Matches(slug, SlugRX),
+		"slug",
+		"must only contain lowercase letters, digits and single dashes",
+	)
+}
+
+// Slugify derives a slug from a title: lowercase ASCII letters and digits
+// separated by single dashes, at most 90 characters long. Titles without any
+// of those get "post".
+func Slugify(title string) string {
+	var (
+		b    strings.Builder
+		dash bool
+	)
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+		default:
+			dash = true
+		}
+		if b.Len() >= 90 {
+			break
+		}
+	}
+
+	if b.Len() == 0 {
+		return "post"
+	}
+
+	return b.String()
+}
@@ -0,0 +1,260 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"textonly.islandwind.me/internal/markdown"
+	"textonly.islandwind.me/internal/utils"
+)
+
+// PostRenderer renders the markdown of a post and sets its HTML, excerpt,
+// outline and stats.
+type PostRenderer func(bp *BlogPost) error
+
+// postOutline scans the outline of a post from its JSON column.
+type postOutline []markdown.Heading
+
+func (o *postOutline) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*o = nil
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("unable to scan %T into outline", src)
+	}
+
+	return json.Unmarshal(b, (*[]markdown.Heading)(o))
+}
+
+// execer executes statements in a transaction or directly on the database.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// render renders the post with the renderer of the model, if it has one.
+func (m *BlogPostModel) render(ctx context.Context, bp *BlogPost) error {
+	logger := utils.LoggerFromContext(ctx)
+
+	if m.Render == nil {
+		return nil
+	}
+
+	logger.InfoContext(ctx, "rendering blogpost", "id", bp.ID, "renderer", bp.Renderer)
+	if err := m.Render(bp); err != nil {
+		logger.ErrorContext(ctx, "unable to render blogpost", "id", bp.ID, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// storeRendered stores the HTML, excerpt, outline and stats of the post, and
+// whether it is dynamic.
+func storeRendered(ctx context.Context, db execer, bp *BlogPost) error {
+	logger := utils.LoggerFromContext(ctx)
+
+	outline := bp.Outline
+	if outline == nil {
+		outline = []markdown.Heading{}
+	}
+	rawOutline, err := json.Marshal(outline)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE posts
+        SET html = $2, excerpt = $3, outline = $4, word_count = $5, reading_time = $6,
+            code_blocks = $7, outbound_links = $8, images = $9, dynamic = $10
+        WHERE id = $1;`
+
+	args := []any{
+		bp.ID,
+		bp.HTML,
+		bp.Excerpt,
+		rawOutline,
+		bp.WordCount,
+		bp.ReadingTime,
+		bp.CodeBlocks,
+		bp.OutboundLinks,
+		bp.Images,
+		bp.Dynamic,
+	}
+
+	logger.InfoContext(ctx, "storing rendered blogpost", "query", query, "id", bp.ID)
+	_, err = db.ExecContext(ctx, query, args...)
+	if err != nil {
+		logger.ErrorContext(
+			ctx, "unable to store rendered blogpost", "query", query, "id", bp.ID, "error", err,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// Rerender renders every post again and stores the result, which is needed
+// when the configuration of the renderers or the sanitizer changes, and then
+// refreshes the related posts. When the posts were last updated is left as it
+// is. It returns the number of posts rendered.
+func (m *BlogPostModel) Rerender(ctx context.Context) (int, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	stmt := "SELECT id FROM posts ORDER BY id;"
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "querying blogpost ids", "query", stmt)
+	rows, err := m.DB.QueryContext(qCtx, stmt)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query blogpost ids", "query", stmt, "error", err)
+		return 0, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			logger.ErrorContext(ctx, "unable to query blogpost ids", "query", stmt, "error", err)
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "unable to query blogpost ids", "query", stmt, "error", err)
+		return 0, err
+	}
+
+	rendered, err := m.rerenderPosts(ctx, ids)
+	if err != nil {
+		return rendered, err
+	}
+
+	return rendered, m.RefreshRelated(ctx)
+}
+
+// rerenderPosts renders the posts with the IDs again, one at a time. Posts
+// deleted in the meantime are skipped.
+func (m *BlogPostModel) rerenderPosts(ctx context.Context, ids []int) (int, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	if m.Render == nil {
+		return 0, nil
+	}
+
+	rendered := 0
+	for _, id := range ids {
+		bp, err := m.Get(ctx, id)
+		if err != nil {
+			if errors.Is(err, ErrNoRecord) {
+				continue
+			}
+			return rendered, err
+		}
+		if err = m.render(ctx, bp); err != nil {
+			return rendered, err
+		}
+
+		sCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+		err = storeRendered(sCtx, m.DB, bp)
+		cancel()
+		if err != nil {
+			return rendered, err
+		}
+		rendered++
+	}
+	logger.InfoContext(ctx, "blogposts rendered", "rendered", rendered)
+
+	return rendered, nil
+}
+
+// dependents returns the IDs of the posts whose HTML shows the post: the
+// posts linking to it, and the dynamic posts, which list posts.
+func (m *BlogPostModel) dependents(ctx context.Context, id int) ([]int, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	stmt := `
+        SELECT l.source_id
+        FROM post_links l
+        JOIN posts t ON ` + postLinkJoin + `
+        WHERE t.id = $1 AND l.source_id <> $1
+        UNION
+        SELECT id FROM posts WHERE dynamic AND id <> $1
+        ORDER BY 1;`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "querying dependent blogposts", "query", stmt, "id", id)
+	rows, err := m.DB.QueryContext(qCtx, stmt, id)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query dependent blogposts", "query", stmt, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var dependent int
+		if err = rows.Scan(&dependent); err != nil {
+			logger.ErrorContext(ctx, "unable to query dependent blogposts", "query", stmt, "error", err)
+			return nil, err
+		}
+		ids = append(ids, dependent)
+	}
+	if err = rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "unable to query dependent blogposts", "query", stmt, "error", err)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// rerenderDependents renders the posts that show the post again, so their
+// links and lists show whether it exists, who can read it and what its title
+// is. The post itself is stored by then, so errors are only logged.
+func (m *BlogPostModel) rerenderDependents(ctx context.Context, id int) {
+	if m.Render == nil {
+		return
+	}
+
+	ids, err := m.dependents(ctx, id)
+	if err != nil {
+		return
+	}
+	m.rerenderLogged(ctx, ids)
+}
+
+// RerenderPost renders the post again, with the posts that show it, after it
+// changed other than through Update, as its status does when it is
+// published. Errors are only logged.
+func (m *BlogPostModel) RerenderPost(ctx context.Context, id int) {
+	if m.Render == nil {
+		return
+	}
+
+	ids, err := m.dependents(ctx, id)
+	if err != nil {
+		return
+	}
+	m.rerenderLogged(ctx, append([]int{id}, ids...))
+}
+
+// rerenderLogged renders the posts with the IDs again, logging any error.
+func (m *BlogPostModel) rerenderLogged(ctx context.Context, ids []int) {
+	logger := utils.LoggerFromContext(ctx)
+
+	if _, err := m.rerenderPosts(ctx, ids); err != nil {
+		logger.ErrorContext(ctx, "unable to render dependent blogposts", "error", err)
+	}
+}
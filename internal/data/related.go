@@ -0,0 +1,215 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"textonly.islandwind.me/internal/markdown"
+	"textonly.islandwind.me/internal/similarity"
+	"textonly.islandwind.me/internal/utils"
+)
+
+// relatedPerPost is the number of related posts stored for each post.
+const relatedPerPost = 10
+
+// The weights of the words of the parts of a post when comparing posts.
+const (
+	relatedTitleWeight = 3
+	relatedLeadWeight  = 2
+	relatedBodyWeight  = 1
+)
+
+// relatedRefreshTimeout bounds a refresh of the related posts, which compares
+// every post to every other one.
+const relatedRefreshTimeout = time.Minute
+
+// relatedLock is the key of the advisory lock that makes refreshes of the
+// related posts wait for each other, also across instances of the
+// application.
+const relatedLock = 7309121
+
+// RefreshRelated compares every post to the others by the words of their
+// title, lead and text, and stores the most similar ones as its related
+// posts. The posts are read and their related posts replaced in one
+// transaction, one refresh at a time.
+func (m *BlogPostModel) RefreshRelated(ctx context.Context) error {
+	logger := utils.LoggerFromContext(ctx)
+
+	rCtx, cancel := context.WithTimeout(ctx, relatedRefreshTimeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(rCtx, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(rCtx, "SELECT pg_advisory_xact_lock($1);", relatedLock); err != nil {
+		logger.ErrorContext(ctx, "unable to lock related posts", "error", err)
+		return err
+	}
+
+	stmt := "SELECT id, title, lead, post, html FROM posts;"
+
+	logger.InfoContext(ctx, "querying blogposts to relate", "query", stmt)
+	rows, err := tx.QueryContext(rCtx, stmt)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query blogposts", "query", stmt, "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	docs := []similarity.Document{}
+	for rows.Next() {
+		var (
+			id                     int
+			title, lead, post, src string
+		)
+		if err = rows.Scan(&id, &title, &lead, &post, &src); err != nil {
+			logger.ErrorContext(ctx, "unable to query blogposts", "query", stmt, "error", err)
+			return err
+		}
+		// posts stored before they were rendered on save are compared by
+		// their markdown
+		body := post
+		if src != "" {
+			body = markdown.PlainText([]byte(src))
+		}
+		docs = append(docs, similarity.Document{ID: id, Fields: []similarity.Field{
+			{Text: title, Weight: relatedTitleWeight},
+			{Text: lead, Weight: relatedLeadWeight},
+			{Text: body, Weight: relatedBodyWeight},
+		}})
+	}
+	if err = rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "unable to query blogposts", "query", stmt, "error", err)
+		return err
+	}
+	rows.Close()
+
+	idx := similarity.New(docs)
+
+	var (
+		postIDs, relatedIDs []int64
+		scores              []float64
+	)
+	for _, doc := range docs {
+		for _, match := range idx.Similar(doc.ID, relatedPerPost) {
+			postIDs = append(postIDs, int64(doc.ID))
+			relatedIDs = append(relatedIDs, int64(match.ID))
+			scores = append(scores, match.Score)
+		}
+	}
+
+	if _, err = tx.ExecContext(rCtx, "DELETE FROM related_posts;"); err != nil {
+		logger.ErrorContext(ctx, "unable to delete related posts", "error", err)
+		return err
+	}
+	_, err = tx.ExecContext(
+		rCtx,
+		`INSERT INTO related_posts (post_id, related_id, score)
+        SELECT * FROM unnest($1::int8[], $2::int8[], $3::float8[]);`,
+		postIDs, relatedIDs, scores,
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to insert related posts", "error", err)
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		logger.ErrorContext(ctx, "unable to commit transaction", "error", err)
+		return err
+	}
+	logger.InfoContext(ctx, "related posts stored", "posts", len(docs), "related", len(scores))
+
+	return nil
+}
+
+// RefreshRelatedInBackground refreshes the related posts in a goroutine of its
+// own from now on, instead of in the requests that store posts, until the
+// context is done. Refreshes asked for while one runs are merged into one,
+// which runs after it.
+func (m *BlogPostModel) RefreshRelatedInBackground(ctx context.Context) {
+	m.relate = make(chan struct{}, 1)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-m.relate:
+				m.logRefreshRelated(ctx)
+			}
+		}
+	}()
+}
+
+// refreshRelated refreshes the related posts after a post has been stored or
+// deleted, in the background when it is refreshed there. The post is stored
+// by then, so errors are only logged.
+func (m *BlogPostModel) refreshRelated(ctx context.Context) {
+	if m.relate == nil {
+		m.logRefreshRelated(ctx)
+		return
+	}
+
+	select {
+	case m.relate <- struct{}{}:
+	default:
+		// a refresh is pending already
+	}
+}
+
+func (m *BlogPostModel) logRefreshRelated(ctx context.Context) {
+	logger := utils.LoggerFromContext(ctx)
+
+	if err := m.RefreshRelated(ctx); err != nil {
+		logger.ErrorContext(ctx, "unable to refresh related posts", "error", err)
+	}
+}
+
+// Related returns at most limit public posts related to the post, most
+// related first, with their ID, slug, kind, title, lead, link URL, excerpt and
+// creation time.
+func (m *BlogPostModel) Related(ctx context.Context, id, limit int) ([]*BlogPost, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	stmt := `
+        SELECT p.id, p.slug, p.kind, p.title, p.lead, p.link_url, p.excerpt, p.created
+        FROM related_posts r
+        JOIN posts p ON p.id = r.related_id
+        WHERE r.post_id = $1 AND p.visibility = 'public' AND p.status = 'published'
+        ORDER BY r.score DESC, p.id
+        LIMIT $2;`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "querying related posts", "query", stmt, "id", id, "limit", limit)
+	rows, err := m.DB.QueryContext(qCtx, stmt, id, limit)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query related posts", "query", stmt, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []*BlogPost{}
+	for rows.Next() {
+		p := &BlogPost{}
+		err = rows.Scan(
+			&p.ID, &p.Slug, &p.Kind, &p.Title, &p.Lead, &p.LinkURL, &p.Excerpt, &p.Created,
+		)
+		if err != nil {
+			logger.ErrorContext(ctx, "unable to query related posts", "query", stmt, "error", err)
+			return nil, err
+		}
+		posts = append(posts, p)
+	}
+	if err = rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "unable to query related posts", "query", stmt, "error", err)
+		return nil, err
+	}
+	logger.InfoContext(ctx, "data retrieved", "related", len(posts))
+
+	return posts, nil
+}
@@ -0,0 +1,220 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"textonly.islandwind.me/internal/utils"
+	"textonly.islandwind.me/internal/validator"
+)
+
+// The statuses a post goes through on its way to the site.
+const (
+	// StatusDraft posts are being written, or were sent back by a reviewer.
+	StatusDraft = "draft"
+	// StatusInReview posts wait for a reviewer to approve or reject them.
+	StatusInReview = "in_review"
+	// StatusApproved posts are approved by a reviewer and can be published.
+	StatusApproved = "approved"
+	// StatusPublished posts are on the site.
+	StatusPublished = "published"
+)
+
+// Statuses are the statuses a post can have.
+var Statuses = []string{StatusDraft, StatusInReview, StatusApproved, StatusPublished}
+
+// The actions of the review workflow.
+const (
+	ReviewRequest = "request"
+	ReviewApprove = "approve"
+	ReviewReject  = "reject"
+	ReviewPublish = "publish"
+)
+
+var (
+	// ErrReviewStatus is returned for actions the post does not have the
+	// status for, such as approving a post that is not in review.
+	ErrReviewStatus = errors.New("post does not have the status the action needs")
+	// ErrSelfReview is returned when a post is approved by the one who
+	// requested its review.
+	ErrSelfReview = errors.New("post approved by the one who requested the review")
+)
+
+// Published reports whether the post is on the site. Posts without a status
+// are published.
+func (bp *BlogPost) Published() bool {
+	return bp.Status == "" || bp.Status == StatusPublished
+}
+
+// Review is an action taken on a post in the review workflow, with who took
+// it and when.
+type Review struct {
+	ID     int    `json:"id"`
+	PostID int    `json:"post_id"`
+	Action string `json:"action"`
+	// Reviewer is who took the action. For review requests, it is the author.
+	Reviewer string `json:"reviewer"`
+	Comment  string `json:"comment,omitempty"`
+	// Status is the status of the post after the action.
+	Status  string     `json:"status"`
+	Created *time.Time `json:"created,omitempty"`
+}
+
+// transition returns the statuses the post must have for the action, and the
+// status the action leaves it with. Without required approval, any post that
+// is not published can be published.
+func (r *Review) transition(requireApproval bool) (from []string, to string) {
+	switch r.Action {
+	case ReviewRequest:
+		return []string{StatusDraft}, StatusInReview
+	case ReviewApprove:
+		return []string{StatusInReview}, StatusApproved
+	case ReviewReject:
+		return []string{StatusInReview, StatusApproved}, StatusDraft
+	case ReviewPublish:
+		if requireApproval {
+			return []string{StatusApproved}, StatusPublished
+		}
+		return []string{StatusDraft, StatusInReview, StatusApproved}, StatusPublished
+	}
+	return nil, ""
+}
+
+func ValidateReview(v *validator.Validator, r *Review) {
+	v.Check(
+		slices.Contains([]string{ReviewRequest, ReviewApprove, ReviewReject, ReviewPublish}, r.Action),
+		"action",
+		"must be request, approve, reject or publish",
+	)
+	v.Check(validator.NotBlank(r.Reviewer), "reviewer", "must be provided")
+	v.Check(validator.MaxChars(r.Reviewer, 100), "reviewer", "must not be more than 100 characters long")
+	if r.Action == ReviewReject {
+		v.Check(validator.NotBlank(r.Comment), "comment", "must say why the post is rejected")
+	}
+}
+
+type ReviewModel struct {
+	Timeout *time.Duration
+	DB      *sql.DB
+}
+
+// ForPost returns the reviews of the post, oldest first.
+func (m *ReviewModel) ForPost(ctx context.Context, postID int) ([]*Review, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	stmt := `SELECT id, post_id, action, reviewer, comment, created
+        FROM reviews
+        WHERE post_id = $1
+        ORDER BY id;`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "querying reviews", "query", stmt, "post_id", postID)
+	rows, err := m.DB.QueryContext(qCtx, stmt, postID)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query reviews", "query", stmt, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []*Review{}
+	for rows.Next() {
+		r := &Review{}
+		err = rows.Scan(&r.ID, &r.PostID, &r.Action, &r.Reviewer, &r.Comment, &r.Created)
+		if err != nil {
+			logger.ErrorContext(ctx, "unable to query reviews", "query", stmt, "error", err)
+			return nil, err
+		}
+		_, r.Status = r.transition(false)
+		reviews = append(reviews, r)
+	}
+	if err = rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "unable to query reviews", "query", stmt, "error", err)
+		return nil, err
+	}
+	logger.InfoContext(ctx, "data retrieved", "reviews", len(reviews))
+
+	return reviews, nil
+}
+
+// Insert moves the post on in the workflow by the action of the review, and
+// records the review. Posts that do not have the status the action needs
+// return ErrReviewStatus, and approvals by the one who requested the review
+// ErrSelfReview.
+func (m *ReviewModel) Insert(ctx context.Context, r *Review, requireApproval bool) error {
+	logger := utils.LoggerFromContext(ctx)
+
+	from, to := r.transition(requireApproval)
+
+	rCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(rCtx, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRowContext(rCtx, "SELECT status FROM posts WHERE id = $1 FOR UPDATE;", r.PostID).
+		Scan(&status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.InfoContext(ctx, "post does not exist", "post_id", r.PostID)
+			return ErrRecordNotFound
+		}
+		logger.ErrorContext(ctx, "unable to query post status", "post_id", r.PostID, "error", err)
+		return err
+	}
+	if !slices.Contains(from, status) {
+		logger.InfoContext(ctx, "post does not have the status for the action",
+			"post_id", r.PostID, "status", status, "action", r.Action)
+		return ErrReviewStatus
+	}
+
+	if r.Action == ReviewApprove {
+		var requester string
+		err = tx.QueryRowContext(rCtx, `SELECT reviewer FROM reviews
+            WHERE post_id = $1 AND action = 'request'
+            ORDER BY id DESC LIMIT 1;`, r.PostID).Scan(&requester)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			logger.ErrorContext(ctx, "unable to query review request", "post_id", r.PostID, "error", err)
+			return err
+		}
+		if requester == r.Reviewer {
+			logger.InfoContext(ctx, "post approved by its requester", "post_id", r.PostID, "reviewer", r.Reviewer)
+			return ErrSelfReview
+		}
+	}
+
+	query := "UPDATE posts SET status = $2 WHERE id = $1;"
+	if _, err = tx.ExecContext(rCtx, query, r.PostID, to); err != nil {
+		logger.ErrorContext(ctx, "unable to update post status", "query", query, "error", err)
+		return err
+	}
+
+	query = `INSERT INTO reviews (post_id, action, reviewer, comment)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, created;`
+	logger.InfoContext(ctx, "inserting review", "query", query, "post_id", r.PostID, "action", r.Action)
+	err = tx.QueryRowContext(rCtx, query, r.PostID, r.Action, r.Reviewer, r.Comment).
+		Scan(&r.ID, &r.Created)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to insert review", "query", query, "error", err)
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.ErrorContext(ctx, "unable to commit transaction", "error", err)
+		return err
+	}
+	r.Status = to
+	logger.InfoContext(ctx, "review inserted", "id", r.ID, "status", to)
+
+	return nil
+}
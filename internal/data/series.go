@@ -0,0 +1,457 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"textonly.islandwind.me/internal/utils"
+	"textonly.islandwind.me/internal/validator"
+)
+
+var (
+	ErrPostInOtherSeries = errors.New("post is part of another series")
+	ErrUnknownPost       = errors.New("unknown post")
+)
+
+// Series is a set of posts meant to be read in order, such as the parts of a
+// tutorial.
+type Series struct {
+	ID          int    `json:"id"`
+	Slug        string `json:"slug"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// PostIDs are the IDs of the parts of the series, in order.
+	PostIDs []int `json:"post_ids"`
+	// Posts are the parts of the series, in order, without their content.
+	Posts      []*BlogPost `json:"posts,omitempty"`
+	LastUpdate *time.Time  `json:"last_update,omitempty"`
+	Created    *time.Time  `json:"created,omitempty"`
+}
+
+// Part returns the position of the post in the series, starting at 1, or 0
+// if it is not part of it.
+func (s *Series) Part(postID int) int {
+	for i, p := range s.Posts {
+		if p.ID == postID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Previous returns the part before the post, or nil if it is the first.
+func (s *Series) Previous(postID int) *BlogPost {
+	if part := s.Part(postID); part > 1 {
+		return s.Posts[part-2]
+	}
+	return nil
+}
+
+// Next returns the part after the post, or nil if it is the last.
+func (s *Series) Next(postID int) *BlogPost {
+	if part := s.Part(postID); part > 0 && part < len(s.Posts) {
+		return s.Posts[part]
+	}
+	return nil
+}
+
+// HideUnlisted leaves the parts that are not listed out of the series, except
+// the post with the ID, which is the one being read.
+func (s *Series) HideUnlisted(readingID int) {
+	s.Posts = slices.DeleteFunc(s.Posts, func(p *BlogPost) bool {
+		return !p.Listed() && p.ID != readingID
+	})
+	s.PostIDs = make([]int, 0, len(s.Posts))
+	for _, p := range s.Posts {
+		s.PostIDs = append(s.PostIDs, p.ID)
+	}
+}
+
+func ValidateSeries(v *validator.Validator, s *Series) {
+	v.Check(validator.NotBlank(s.Slug), "slug", "must be provided")
+	v.Check(validator.MaxChars(s.Slug, 100), "slug", "must not be more than 100 characters long")
+	v.Check(
+		validator.Matches(s.Slug, SlugRX),
+		"slug",
+		"must only contain lowercase letters, digits and single dashes",
+	)
+	v.Check(validator.NotBlank(s.Title), "title", "must be provided")
+	v.Check(validator.MaxChars(s.Title, 255), "title", "must not be more than 255 characters long")
+
+	seen := map[int]bool{}
+	for _, id := range s.PostIDs {
+		v.Check(id > 0, "post_ids", "must only contain post IDs")
+		v.Check(!seen[id], "post_ids", fmt.Sprintf("contains post %d more than once", id))
+		seen[id] = true
+	}
+}
+
+type SeriesModel struct {
+	Timeout *time.Duration
+	DB      *sql.DB
+}
+
+func (m *SeriesModel) Get(ctx context.Context, id int) (*Series, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	if id < 1 {
+		logger.InfoContext(ctx, "invalid id", "id", id)
+		return nil, ErrRecordNotFound
+	}
+
+	stmt := `SELECT id, slug, title, description, last_update, created
+        FROM series
+        WHERE id = $1;`
+
+	return m.getOne(ctx, stmt, id)
+}
+
+func (m *SeriesModel) GetBySlug(ctx context.Context, slug string) (*Series, error) {
+	stmt := `SELECT id, slug, title, description, last_update, created
+        FROM series
+        WHERE slug = $1;`
+
+	return m.getOne(ctx, stmt, slug)
+}
+
+// ForPost returns the series the post is part of.
+func (m *SeriesModel) ForPost(ctx context.Context, postID int) (*Series, error) {
+	stmt := `SELECT s.id, s.slug, s.title, s.description, s.last_update, s.created
+        FROM series s
+        JOIN series_posts sp ON sp.series_id = s.id
+        WHERE sp.post_id = $1;`
+
+	return m.getOne(ctx, stmt, postID)
+}
+
+// getOne queries a single series by the argument and adds its posts.
+func (m *SeriesModel) getOne(ctx context.Context, stmt string, arg any) (*Series, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	rCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "querying series", "query", stmt, "arg", arg)
+	s := &Series{}
+	err := m.DB.QueryRowContext(rCtx, stmt, arg).Scan(
+		&s.ID,
+		&s.Slug,
+		&s.Title,
+		&s.Description,
+		&s.LastUpdate,
+		&s.Created,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.InfoContext(ctx, "no records found", "query", stmt, "arg", arg)
+			return nil, ErrRecordNotFound
+		}
+		logger.ErrorContext(ctx, "unable to query series", "query", stmt, "arg", arg, "error", err)
+		return nil, err
+	}
+
+	if err = m.addPosts(ctx, s); err != nil {
+		return nil, err
+	}
+	logger.InfoContext(ctx, "data retrieved")
+
+	return s, nil
+}
+
+func (m *SeriesModel) GetAll(ctx context.Context, filters Filters) ([]*Series, Metadata, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	stmt := `
+        SELECT COUNT(*) OVER(), id, slug, title, description, last_update, created
+        FROM series
+        WHERE
+            ($1::int IS NULL OR id = $1)
+            AND ($2 = '' OR slug = $2)
+            AND ($3 = '' OR title LIKE ('%' || $3 || '%'))
+        ` + CreateOrderByClause(filters.OrderBy) + `
+        LIMIT $4 OFFSET $5;`
+
+	rCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "querying series", "query", stmt, "filters", filters)
+	rows, err := m.DB.QueryContext(
+		rCtx,
+		stmt,
+		filters.ID,
+		filters.Slug,
+		filters.Title,
+		filters.limit(),
+		filters.offset(),
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query series", "query", stmt, "error", err)
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	series := []*Series{}
+	for rows.Next() {
+		s := &Series{}
+		err = rows.Scan(
+			&totalRecords,
+			&s.ID,
+			&s.Slug,
+			&s.Title,
+			&s.Description,
+			&s.LastUpdate,
+			&s.Created,
+		)
+		if err != nil {
+			logger.ErrorContext(ctx, "unable to query series", "query", stmt, "error", err)
+			return nil, Metadata{}, err
+		}
+		series = append(series, s)
+	}
+	if err = rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "unable to query series", "query", stmt, "error", err)
+		return nil, Metadata{}, err
+	}
+	rows.Close()
+
+	for _, s := range series {
+		if err = m.addPosts(ctx, s); err != nil {
+			return nil, Metadata{}, err
+		}
+	}
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize, filters.OrderBy)
+	logger.InfoContext(ctx, "data retrieved", "metadata", metadata)
+
+	return series, metadata, nil
+}
+
+// addPosts queries the parts of the series, in order.
+func (m *SeriesModel) addPosts(ctx context.Context, s *Series) error {
+	logger := utils.LoggerFromContext(ctx)
+
+	stmt := `
+        SELECT p.id, p.slug, p.kind, p.visibility, p.status, p.title, p.lead, p.link_url, p.excerpt,
+            p.featured, p.word_count, p.reading_time, p.code_blocks, p.outbound_links, p.images,
+            p.last_update, p.created
+        FROM series_posts sp
+        JOIN posts p ON p.id = sp.post_id
+        WHERE sp.series_id = $1
+        ORDER BY sp.position;`
+
+	rCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "querying series posts", "query", stmt, "id", s.ID)
+	rows, err := m.DB.QueryContext(rCtx, stmt, s.ID)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query series posts", "query", stmt, "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	s.PostIDs, s.Posts = []int{}, []*BlogPost{}
+	for rows.Next() {
+		p := &BlogPost{}
+		err = rows.Scan(
+			&p.ID,
+			&p.Slug,
+			&p.Kind,
+			&p.Visibility,
+			&p.Status,
+			&p.Title,
+			&p.Lead,
+			&p.LinkURL,
+			&p.Excerpt,
+			&p.Featured,
+			&p.WordCount,
+			&p.ReadingTime,
+			&p.CodeBlocks,
+			&p.OutboundLinks,
+			&p.Images,
+			&p.LastUpdate,
+			&p.Created,
+		)
+		if err != nil {
+			logger.ErrorContext(ctx, "unable to query series posts", "query", stmt, "error", err)
+			return err
+		}
+		s.PostIDs = append(s.PostIDs, p.ID)
+		s.Posts = append(s.Posts, p)
+	}
+	if err = rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "unable to query series posts", "query", stmt, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// setPosts replaces the parts of the series with the posts, in order.
+func setPosts(ctx context.Context, tx *sql.Tx, s *Series) error {
+	logger := utils.LoggerFromContext(ctx)
+
+	_, err := tx.ExecContext(ctx, "DELETE FROM series_posts WHERE series_id = $1;", s.ID)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to delete series posts", "id", s.ID, "error", err)
+		return err
+	}
+
+	for i, postID := range s.PostIDs {
+		_, err = tx.ExecContext(
+			ctx,
+			"INSERT INTO series_posts (series_id, post_id, position) VALUES ($1, $2, $3);",
+			s.ID, postID, i+1,
+		)
+		switch {
+		case err == nil:
+		case isUniqueViolation(err):
+			logger.InfoContext(ctx, "post is part of another series", "post_id", postID)
+			return fmt.Errorf("%w: %d", ErrPostInOtherSeries, postID)
+		case isForeignKeyViolation(err):
+			logger.InfoContext(ctx, "post does not exist", "post_id", postID)
+			return fmt.Errorf("%w: %d", ErrUnknownPost, postID)
+		default:
+			logger.ErrorContext(
+				ctx, "unable to insert series post", "id", s.ID, "post_id", postID, "error", err,
+			)
+			return err
+		}
+	}
+	logger.InfoContext(ctx, "series posts stored", "id", s.ID, "posts", len(s.PostIDs))
+
+	return nil
+}
+
+func (m *SeriesModel) Insert(ctx context.Context, s *Series) (Series, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	query := `INSERT INTO series (slug, title, description)
+        VALUES ($1, $2, $3)
+        RETURNING id, last_update, created;`
+
+	args := []any{s.Slug, s.Title, s.Description}
+
+	rCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(rCtx, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to begin transaction", "error", err)
+		return *s, err
+	}
+	defer tx.Rollback()
+
+	logger.InfoContext(ctx, "inserting series", "query", query, "args", args)
+	err = tx.QueryRowContext(rCtx, query, args...).Scan(&s.ID, &s.LastUpdate, &s.Created)
+	if err != nil {
+		if isUniqueViolation(err) {
+			logger.InfoContext(ctx, "slug already exists", "slug", s.Slug)
+			return *s, ErrDuplicateSlug
+		}
+		logger.ErrorContext(
+			ctx, "unable to insert series", "query", query, "args", args, "error", err,
+		)
+		return *s, err
+	}
+
+	if err = setPosts(rCtx, tx, s); err != nil {
+		return *s, err
+	}
+	if err = tx.Commit(); err != nil {
+		logger.ErrorContext(ctx, "unable to commit transaction", "error", err)
+		return *s, err
+	}
+	logger.InfoContext(ctx, "series inserted", "id", s.ID)
+
+	return *s, nil
+}
+
+func (m *SeriesModel) Update(ctx context.Context, s *Series) (rowsAffected int64, err error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	query := `UPDATE series
+        SET slug = $2, title = $3, description = $4, last_update = NOW()
+        WHERE id = $1;`
+
+	args := []any{s.ID, s.Slug, s.Title, s.Description}
+
+	rCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(rCtx, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to begin transaction", "error", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	logger.InfoContext(ctx, "updating series", "query", query, "args", args)
+	result, err := tx.ExecContext(rCtx, query, args...)
+	if err != nil {
+		if isUniqueViolation(err) {
+			logger.InfoContext(ctx, "slug already exists", "slug", s.Slug)
+			return 0, ErrDuplicateSlug
+		}
+		logger.ErrorContext(
+			ctx, "unable to update series", "query", query, "args", args, "error", err,
+		)
+		return 0, err
+	}
+
+	rowsAffected, err = result.RowsAffected()
+	if err != nil {
+		logger.ErrorContext(
+			ctx, "unable to update series", "query", query, "args", args, "error", err,
+		)
+		return 0, err
+	}
+	if rowsAffected == 0 {
+		logger.InfoContext(ctx, "no records found", "query", query, "args", args)
+		return 0, ErrRecordNotFound
+	}
+
+	if err = setPosts(rCtx, tx, s); err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(); err != nil {
+		logger.ErrorContext(ctx, "unable to commit transaction", "error", err)
+		return 0, err
+	}
+	logger.InfoContext(ctx, "series updated", "id", s.ID)
+
+	return rowsAffected, nil
+}
+
+func (m *SeriesModel) Delete(ctx context.Context, id int) (rowsAffected int64, err error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	query := "DELETE FROM series WHERE id = $1;"
+
+	rCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "deleting series", "query", query, "id", id)
+	result, err := m.DB.ExecContext(rCtx, query, id)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to delete series", "id", id, "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err = result.RowsAffected()
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to delete series", "id", id, "error", err)
+		return 0, err
+	}
+	if rowsAffected == 0 {
+		logger.InfoContext(ctx, "no records found", "id", id)
+		return 0, ErrRecordNotFound
+	}
+	logger.InfoContext(ctx, "series deleted", "id", id)
+
+	return rowsAffected, nil
+}
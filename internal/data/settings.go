@@ -0,0 +1,146 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"textonly.islandwind.me/internal/utils"
+	"textonly.islandwind.me/internal/validator"
+)
+
+// SiteSettings holds the instance wide settings used by the templates and
+// feeds. There is only ever a single row of settings.
+type SiteSettings struct {
+	Title         string     `json:"title"`
+	Tagline       string     `json:"tagline"`
+	BaseURL       string     `json:"base_url"`
+	DefaultAuthor string     `json:"default_author"`
+	Language      string     `json:"language"`
+	FooterText    string     `json:"footer_text"`
+	LastUpdate    *time.Time `json:"last_update,omitempty"`
+}
+
+// DefaultSiteSettings returns the settings used when none can be read from
+// the database.
+func DefaultSiteSettings() *SiteSettings {
+	return &SiteSettings{
+		Title:    "Textonly",
+		BaseURL:  "http://localhost:4000",
+		Language: "en",
+	}
+}
+
+// URL joins the path with the canonical base URL of the site.
+func (s *SiteSettings) URL(path string) string {
+	return strings.TrimSuffix(s.BaseURL, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+func ValidateSiteSettings(v *validator.Validator, s *SiteSettings) {
+	v.Check(validator.NotBlank(s.Title), "title", "must be provided")
+	v.Check(validator.MaxChars(s.Title, 255), "title", "must not be more than 255 characters long")
+	v.Check(
+		validator.MaxChars(s.Tagline, 255),
+		"tagline",
+		"must not be more than 255 characters long",
+	)
+	v.Check(
+		validator.MaxChars(s.DefaultAuthor, 100),
+		"default_author",
+		"must not be more than 100 characters long",
+	)
+	v.Check(validator.NotBlank(s.Language), "language", "must be provided")
+	if validator.NotBlank(s.Language) {
+		ValidateLanguage(v, "language", s.Language)
+	}
+
+	u, err := url.Parse(s.BaseURL)
+	v.Check(
+		err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+		"base_url",
+		"must be an absolute http or https URL",
+	)
+}
+
+type SiteSettingsModel struct {
+	Timeout *time.Duration
+	DB      *sql.DB
+}
+
+func (m *SiteSettingsModel) Get(ctx context.Context) (*SiteSettings, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	stmt := `
+        SELECT title, tagline, base_url, default_author, language, footer_text, last_update
+        FROM site_settings
+        WHERE id = 1;`
+
+	rCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "querying site settings", "query", stmt)
+	s := &SiteSettings{}
+	err := m.DB.QueryRowContext(rCtx, stmt).Scan(
+		&s.Title,
+		&s.Tagline,
+		&s.BaseURL,
+		&s.DefaultAuthor,
+		&s.Language,
+		&s.FooterText,
+		&s.LastUpdate,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.InfoContext(ctx, "no records found", "query", stmt)
+			return nil, ErrRecordNotFound
+		} else {
+			logger.ErrorContext(ctx, "unable to query site settings", "query", stmt, "error", err)
+			return nil, err
+		}
+	}
+	logger.InfoContext(ctx, "data retrieved")
+
+	return s, nil
+}
+
+func (m *SiteSettingsModel) Update(
+	ctx context.Context,
+	s *SiteSettings,
+) (rowsAffected int64, err error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	query := `
+        INSERT INTO site_settings (
+            id, title, tagline, base_url, default_author, language, footer_text
+        )
+        VALUES (1, $1, $2, $3, $4, $5, $6)
+        ON CONFLICT (id) DO UPDATE
+        SET title = $1, tagline = $2, base_url = $3, default_author = $4, language = $5,
+            footer_text = $6, last_update = NOW();`
+
+	args := []any{s.Title, s.Tagline, s.BaseURL, s.DefaultAuthor, s.Language, s.FooterText}
+
+	rCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "updating site settings", "query", query, "args", args)
+	result, err := m.DB.ExecContext(rCtx, query, args...)
+	if err != nil {
+		logger.ErrorContext(
+			ctx, "unable to update site settings", "query", query, "args", args, "error", err,
+		)
+		return 0, err
+	}
+
+	rowsAffected, err = result.RowsAffected()
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to update site settings", "error", err)
+		return 0, err
+	}
+	logger.InfoContext(ctx, "site settings updated", "rows_affected", rowsAffected)
+
+	return rowsAffected, nil
+}
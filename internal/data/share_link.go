@@ -0,0 +1,151 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"textonly.islandwind.me/internal/utils"
+)
+
+// ShareLink grants access to a post through a signed URL until it expires or
+// is revoked.
+type ShareLink struct {
+	ID      int        `json:"id"`
+	PostID  int        `json:"post_id"`
+	Expires time.Time  `json:"expires"`
+	Revoked *time.Time `json:"revoked,omitempty"`
+	Created *time.Time `json:"created,omitempty"`
+}
+
+// Active reports whether the link grants access at the time.
+func (l *ShareLink) Active(now time.Time) bool {
+	return l.Revoked == nil && now.Before(l.Expires)
+}
+
+type ShareLinkModel struct {
+	Timeout *time.Duration
+	DB      *sql.DB
+}
+
+func (m *ShareLinkModel) Get(ctx context.Context, id int) (*ShareLink, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	stmt := `SELECT id, post_id, expires, revoked, created
+        FROM share_links
+        WHERE id = $1;`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "querying share link", "query", stmt, "id", id)
+	l := &ShareLink{}
+	err := m.DB.QueryRowContext(qCtx, stmt, id).
+		Scan(&l.ID, &l.PostID, &l.Expires, &l.Revoked, &l.Created)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.InfoContext(ctx, "no records found", "query", stmt, "id", id)
+			return nil, ErrRecordNotFound
+		}
+		logger.ErrorContext(ctx, "unable to query share link", "query", stmt, "error", err)
+		return nil, err
+	}
+	logger.InfoContext(ctx, "data retrieved")
+
+	return l, nil
+}
+
+// ForPost returns the share links of the post, newest first.
+func (m *ShareLinkModel) ForPost(ctx context.Context, postID int) ([]*ShareLink, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	stmt := `SELECT id, post_id, expires, revoked, created
+        FROM share_links
+        WHERE post_id = $1
+        ORDER BY id DESC;`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "querying share links", "query", stmt, "post_id", postID)
+	rows, err := m.DB.QueryContext(qCtx, stmt, postID)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query share links", "query", stmt, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []*ShareLink{}
+	for rows.Next() {
+		l := &ShareLink{}
+		if err = rows.Scan(&l.ID, &l.PostID, &l.Expires, &l.Revoked, &l.Created); err != nil {
+			logger.ErrorContext(ctx, "unable to query share links", "query", stmt, "error", err)
+			return nil, err
+		}
+		links = append(links, l)
+	}
+	if err = rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "unable to query share links", "query", stmt, "error", err)
+		return nil, err
+	}
+	logger.InfoContext(ctx, "data retrieved", "share_links", len(links))
+
+	return links, nil
+}
+
+func (m *ShareLinkModel) Insert(ctx context.Context, l *ShareLink) error {
+	logger := utils.LoggerFromContext(ctx)
+
+	query := `INSERT INTO share_links (post_id, expires)
+        VALUES ($1, $2)
+        RETURNING id, created;`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "inserting share link", "query", query, "post_id", l.PostID)
+	err := m.DB.QueryRowContext(qCtx, query, l.PostID, l.Expires).Scan(&l.ID, &l.Created)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			logger.InfoContext(ctx, "post does not exist", "post_id", l.PostID)
+			return ErrRecordNotFound
+		}
+		logger.ErrorContext(ctx, "unable to insert share link", "query", query, "error", err)
+		return err
+	}
+	logger.InfoContext(ctx, "share link inserted", "id", l.ID)
+
+	return nil
+}
+
+// Revoke revokes the link, which stops granting access at once. Links that
+// are revoked already are not found.
+func (m *ShareLinkModel) Revoke(ctx context.Context, id int) (rowsAffected int64, err error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	query := "UPDATE share_links SET revoked = NOW() WHERE id = $1 AND revoked IS NULL;"
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "revoking share link", "query", query, "id", id)
+	result, err := m.DB.ExecContext(qCtx, query, id)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to revoke share link", "id", id, "error", err)
+		return 0, err
+	}
+
+	rowsAffected, err = result.RowsAffected()
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to revoke share link", "id", id, "error", err)
+		return 0, err
+	}
+	if rowsAffected == 0 {
+		logger.InfoContext(ctx, "no records found", "id", id)
+		return 0, ErrRecordNotFound
+	}
+	logger.InfoContext(ctx, "share link revoked", "id", id)
+
+	return rowsAffected, nil
+}
@@ -0,0 +1,86 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"textonly.islandwind.me/internal/utils"
+)
+
+// SiteStats are aggregated over all posts.
+type SiteStats struct {
+	TotalPosts int `json:"total_posts"`
+	TotalWords int `json:"total_words"`
+	// Cadence is the average number of days between posts.
+	Cadence   float64    `json:"cadence_days"`
+	FirstPost *time.Time `json:"first_post,omitempty"`
+	LastPost  *time.Time `json:"last_post,omitempty"`
+	// Months lists the months with posts, newest first.
+	Months []MonthStats `json:"months"`
+}
+
+// MonthStats are aggregated over the posts created in a month.
+type MonthStats struct {
+	// Month is written as YYYY-MM.
+	Month string `json:"month"`
+	Posts int    `json:"posts"`
+	Words int    `json:"words"`
+}
+
+// Stats aggregates the stats of all public posts.
+func (m *BlogPostModel) Stats(ctx context.Context) (*SiteStats, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	stmt := `
+        SELECT to_char(date_trunc('month', created), 'YYYY-MM'), COUNT(*), SUM(word_count),
+            MIN(created), MAX(created)
+        FROM posts
+        WHERE visibility = 'public' AND status = 'published'
+        GROUP BY 1
+        ORDER BY 1 DESC;`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "querying post stats", "query", stmt)
+	rows, err := m.DB.QueryContext(qCtx, stmt)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query post stats", "query", stmt, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := &SiteStats{Months: []MonthStats{}}
+	for rows.Next() {
+		var (
+			month       MonthStats
+			first, last *time.Time
+		)
+		if err = rows.Scan(&month.Month, &month.Posts, &month.Words, &first, &last); err != nil {
+			logger.ErrorContext(ctx, "unable to query post stats", "query", stmt, "error", err)
+			return nil, err
+		}
+		stats.Months = append(stats.Months, month)
+		stats.TotalPosts += month.Posts
+		stats.TotalWords += month.Words
+
+		if stats.FirstPost == nil || first.Before(*stats.FirstPost) {
+			stats.FirstPost = first
+		}
+		if stats.LastPost == nil || last.After(*stats.LastPost) {
+			stats.LastPost = last
+		}
+	}
+	if err = rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "unable to query post stats", "query", stmt, "error", err)
+		return nil, err
+	}
+
+	if stats.TotalPosts > 1 {
+		days := stats.LastPost.Sub(*stats.FirstPost).Hours() / 24
+		stats.Cadence = days / float64(stats.TotalPosts-1)
+	}
+	logger.InfoContext(ctx, "data retrieved", "posts", stats.TotalPosts)
+
+	return stats, nil
+}
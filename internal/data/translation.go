@@ -0,0 +1,108 @@
+package data
+
+import (
+	"context"
+	"regexp"
+
+	"textonly.islandwind.me/internal/utils"
+	"textonly.islandwind.me/internal/validator"
+)
+
+// LanguageRX matches language tags, such as "en", "nb" or "en-GB".
+var LanguageRX = regexp.MustCompile(`^[a-zA-Z]{2,8}(-[a-zA-Z0-9]{1,8})*$`)
+
+// ValidateLanguage checks the language tag in the field.
+func ValidateLanguage(v *validator.Validator, field, language string) {
+	v.Check(
+		validator.MaxChars(language, 35),
+		field,
+		"must not be more than 35 characters long",
+	)
+	v.Check(
+		validator.Matches(language, LanguageRX),
+		field,
+		"must be a language tag, such as en or nb",
+	)
+}
+
+// Translations returns the other public posts in the translation group of
+// the post, which are the original and all of its translations, with their ID, slug,
+// kind, language, title, lead, link URL and excerpt, ordered by language.
+func (m *BlogPostModel) Translations(ctx context.Context, id int) ([]*BlogPost, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	stmt := `
+        SELECT p.id, p.slug, p.kind, p.language, p.title, p.lead, p.link_url, p.excerpt
+        FROM posts p, posts t
+        WHERE t.id = $1
+            AND p.id <> t.id
+            AND p.visibility = 'public' AND p.status = 'published'
+            AND COALESCE(p.translation_of, p.id) = COALESCE(t.translation_of, t.id)
+        ORDER BY p.language, p.id;`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "querying translations", "query", stmt, "id", id)
+	rows, err := m.DB.QueryContext(qCtx, stmt, id)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query translations", "query", stmt, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []*BlogPost{}
+	for rows.Next() {
+		p := &BlogPost{}
+		err = rows.Scan(
+			&p.ID, &p.Slug, &p.Kind, &p.Language, &p.Title, &p.Lead, &p.LinkURL, &p.Excerpt,
+		)
+		if err != nil {
+			logger.ErrorContext(ctx, "unable to query translations", "query", stmt, "error", err)
+			return nil, err
+		}
+		posts = append(posts, p)
+	}
+	if err = rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "unable to query translations", "query", stmt, "error", err)
+		return nil, err
+	}
+	logger.InfoContext(ctx, "data retrieved", "translations", len(posts))
+
+	return posts, nil
+}
+
+// Languages returns the languages public posts are written in.
+func (m *BlogPostModel) Languages(ctx context.Context) ([]string, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	stmt := "SELECT DISTINCT language FROM posts WHERE language <> '' AND visibility = 'public' AND status = 'published' ORDER BY language;"
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "querying languages", "query", stmt)
+	rows, err := m.DB.QueryContext(qCtx, stmt)
+	if err != nil {
+		logger.ErrorContext(ctx, "unable to query languages", "query", stmt, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	languages := []string{}
+	for rows.Next() {
+		var language string
+		if err = rows.Scan(&language); err != nil {
+			logger.ErrorContext(ctx, "unable to query languages", "query", stmt, "error", err)
+			return nil, err
+		}
+		languages = append(languages, language)
+	}
+	if err = rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "unable to query languages", "query", stmt, "error", err)
+		return nil, err
+	}
+	logger.InfoContext(ctx, "data retrieved", "languages", len(languages))
+
+	return languages, nil
+}
@@ -0,0 +1,39 @@
+package data
+
+// The visibility levels of posts.
+const (
+	// VisibilityPublic posts are listed everywhere.
+	VisibilityPublic = "public"
+	// VisibilityUnlisted posts are left out of listings and feeds, but anyone
+	// with the URL can read them.
+	VisibilityUnlisted = "unlisted"
+	// VisibilityPrivate posts can only be read through a share link.
+	VisibilityPrivate = "private"
+)
+
+// Visibilities are the visibility levels a post can have.
+var Visibilities = []string{VisibilityPublic, VisibilityUnlisted, VisibilityPrivate}
+
+// Listed reports whether the post is shown in listings and feeds: published
+// and public. Posts without a visibility are public.
+func (bp *BlogPost) Listed() bool {
+	return bp.Published() && (bp.Visibility == "" || bp.Visibility == VisibilityPublic)
+}
+
+// Restricted reports whether the post can only be read by the author or
+// through a share link: private posts, and posts that are not published.
+func (bp *BlogPost) Restricted() bool {
+	return bp.Visibility == VisibilityPrivate || !bp.Published()
+}
+
+// ListedPosts returns the posts that are shown in listings and feeds.
+func ListedPosts(posts []*BlogPost) []*BlogPost {
+	listed := make([]*BlogPost, 0, len(posts))
+	for _, p := range posts {
+		if p.Listed() {
+			listed = append(listed, p)
+		}
+	}
+
+	return listed
+}
@@ -0,0 +1,185 @@
+// Package i18n translates the strings of the user interface with message
+// catalogs. A catalog is a JSON object mapping message keys to the messages
+// in one language, stored as i18n/<language>.json, as in i18n/nb.json.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fallback is the language of the catalog used for messages missing from
+// the others. Its catalog must exist.
+const Fallback = "en"
+
+// The keys of the messages that format dates. The date format replaces
+// {day}, {month} and {year}, and month.1 to month.12 name the months.
+const (
+	dateFormatKey   = "date.format"
+	monthKeyPrefix  = "month."
+	languageNameKey = "language.name"
+)
+
+// Bundle holds the message catalogs by language.
+type Bundle struct {
+	catalogs map[string]map[string]string
+}
+
+// Load reads the catalogs in the i18n directory of the file system.
+func Load(files fs.FS) (*Bundle, error) {
+	paths, err := fs.Glob(files, "i18n/*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Bundle{catalogs: map[string]map[string]string{}}
+	for _, p := range paths {
+		content, err := fs.ReadFile(files, p)
+		if err != nil {
+			return nil, err
+		}
+
+		messages := map[string]string{}
+		if err = json.Unmarshal(content, &messages); err != nil {
+			return nil, fmt.Errorf("unable to parse the catalog %s: %w", p, err)
+		}
+		b.catalogs[strings.ToLower(strings.TrimSuffix(path.Base(p), ".json"))] = messages
+	}
+
+	if _, ok := b.catalogs[Fallback]; !ok {
+		return nil, fmt.Errorf("the catalog i18n/%s.json does not exist", Fallback)
+	}
+
+	return b, nil
+}
+
+// Languages returns the languages there are catalogs for.
+func (b *Bundle) Languages() []string {
+	languages := make([]string, 0, len(b.catalogs))
+	for language := range b.catalogs {
+		languages = append(languages, language)
+	}
+	slices.Sort(languages)
+
+	return languages
+}
+
+// Match returns the language of the first of the language tags there is a
+// catalog for, trying the tag without its region, as in "nb" for "nb-NO",
+// before moving on to the next. It reports whether there was a match.
+func (b *Bundle) Match(tags ...string) (string, bool) {
+	for _, tag := range tags {
+		if language, ok := b.catalog(tag); ok {
+			return language, true
+		}
+	}
+
+	return "", false
+}
+
+// catalog returns the language of the catalog for the language tag.
+func (b *Bundle) catalog(tag string) (string, bool) {
+	tag = strings.ToLower(tag)
+	if _, ok := b.catalogs[tag]; ok {
+		return tag, true
+	}
+
+	base, _, _ := strings.Cut(tag, "-")
+	if _, ok := b.catalogs[base]; ok {
+		return base, true
+	}
+
+	return "", false
+}
+
+// T returns the message with the key in the language, falling back to the
+// Fallback catalog and then to the key itself. The message is formatted with
+// the arguments, if there are any.
+func (b *Bundle) T(language, key string, args ...any) string {
+	message, ok := "", false
+	if language, found := b.catalog(language); found {
+		message, ok = b.catalogs[language][key]
+	}
+	if !ok {
+		message, ok = b.catalogs[Fallback][key]
+	}
+	if !ok {
+		message = key
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Date formats the date of the time in the language.
+func (b *Bundle) Date(language string, t time.Time) string {
+	return strings.NewReplacer(
+		"{day}", strconv.Itoa(t.Day()),
+		"{month}", b.T(language, monthKeyPrefix+strconv.Itoa(int(t.Month()))),
+		"{year}", strconv.Itoa(t.Year()),
+	).Replace(b.T(language, dateFormatKey))
+}
+
+// LanguageName returns the name of the language in the language itself, as
+// given by its catalog, or the tag if there is no catalog for it.
+func (b *Bundle) LanguageName(tag string) string {
+	language, ok := b.catalog(tag)
+	if !ok {
+		return tag
+	}
+	if name, ok := b.catalogs[language][languageNameKey]; ok {
+		return name
+	}
+
+	return tag
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language
+// header, the most preferred first. Tags with a quality of zero and the
+// wildcard are left out.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag, q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+
+	return result
+}
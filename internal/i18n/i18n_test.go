@@ -0,0 +1,123 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"textonly.islandwind.me/internal/assert"
+)
+
+func testBundle(t *testing.T) *Bundle {
+	t.Helper()
+
+	b, err := Load(fstest.MapFS{
+		"i18n/en.json": {Data: []byte(`{
+			"language.name": "English",
+			"date.format": "{month} {day}, {year}",
+			"month.3": "March",
+			"post.read": "Read",
+			"post.reading_time": "%d min read"
+		}`)},
+		"i18n/nb.json": {Data: []byte(`{
+			"language.name": "Norsk bokmål",
+			"date.format": "{day}. {month} {year}",
+			"month.3": "mars",
+			"post.read": "Les"
+		}`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func TestLoad(t *testing.T) {
+	b := testBundle(t)
+	assert.Equal(t, strings.Join(b.Languages(), ","), "en,nb")
+
+	_, err := Load(fstest.MapFS{"i18n/nb.json": {Data: []byte(`{}`)}})
+	if err == nil {
+		t.Error("Expected an error without the fallback catalog")
+	}
+
+	_, err = Load(fstest.MapFS{"i18n/en.json": {Data: []byte(`{`)}})
+	if err == nil {
+		t.Error("Expected an error for an invalid catalog")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	b := testBundle(t)
+
+	tests := []struct {
+		name string
+		tags []string
+		want string
+		ok   bool
+	}{
+		{"Exact", []string{"nb"}, "nb", true},
+		{"Region", []string{"nb-NO"}, "nb", true},
+		{"Case", []string{"NB"}, "nb", true},
+		{"First known", []string{"de", "nb", "en"}, "nb", true},
+		{"Unknown", []string{"de", "sv"}, "", false},
+		{"None", nil, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			language, ok := b.Match(tt.tags...)
+			assert.Equal(t, language, tt.want)
+			assert.Equal(t, ok, tt.ok)
+		})
+	}
+}
+
+func TestT(t *testing.T) {
+	b := testBundle(t)
+
+	assert.Equal(t, b.T("nb", "post.read"), "Les")
+	assert.Equal(t, b.T("nb-NO", "post.read"), "Les")
+	assert.Equal(t, b.T("en", "post.read"), "Read")
+	assert.Equal(t, b.T("de", "post.read"), "Read")
+	assert.Equal(t, b.T("nb", "post.reading_time", 4), "4 min read")
+	assert.Equal(t, b.T("nb", "post.missing"), "post.missing")
+}
+
+func TestDate(t *testing.T) {
+	b := testBundle(t)
+	date := time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, b.Date("en", date), "March 5, 2024")
+	assert.Equal(t, b.Date("nb", date), "5. mars 2024")
+}
+
+func TestLanguageName(t *testing.T) {
+	b := testBundle(t)
+
+	assert.Equal(t, b.LanguageName("nb"), "Norsk bokmål")
+	assert.Equal(t, b.LanguageName("en-GB"), "English")
+	assert.Equal(t, b.LanguageName("de"), "de")
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"Empty", "", ""},
+		{"Single", "nb", "nb"},
+		{"Ordered by quality", "en;q=0.5, nb-NO, nb;q=0.9", "nb-NO,nb,en"},
+		{"Wildcard and zero quality", "*, de;q=0, en;q=0.1", "en"},
+		{"Invalid quality", "en;q=x, nb", "nb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, strings.Join(ParseAcceptLanguage(tt.header), ","), tt.want)
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// diagramLanguage is the language of fenced code blocks drawn as diagrams.
+const diagramLanguage = "diagram"
+
+// The size of a character cell of a diagram, in SVG units.
+const (
+	diagramCellWidth  = 8
+	diagramCellHeight = 16
+)
+
+// Characters lines connect to. Corners and junctions connect both ways.
+const (
+	diagramConnectsH = "-+*.'<>"
+	diagramConnectsV = "|+*.'^vV"
+	diagramConnectsD = "/\\+*.'|_"
+)
+
+// diagram is the grid of characters of a text diagram.
+type diagram struct {
+	grid [][]rune
+	cols int
+}
+
+// newDiagram splits the text into a grid of characters. Tabs are expanded to
+// four spaces and trailing blank lines are dropped.
+func newDiagram(text string) *diagram {
+	d := &diagram{}
+
+	text = strings.TrimRight(strings.ReplaceAll(text, "\t", "    "), "\n ")
+	for _, line := range strings.Split(text, "\n") {
+		row := []rune(strings.TrimRight(line, " \r"))
+		d.grid = append(d.grid, row)
+		d.cols = max(d.cols, len(row))
+	}
+
+	return d
+}
+
+// at returns the character at the row and column, or a space outside the
+// grid.
+func (d *diagram) at(row, col int) rune {
+	if row < 0 || row >= len(d.grid) || col < 0 || col >= len(d.grid[row]) {
+		return ' '
+	}
+	return d.grid[row][col]
+}
+
+// writeDiagram converts box-and-arrow ASCII art to inline SVG, in the style of
+// svgbob. Lines, corners, junctions and arrowheads are drawn; anything else is
+// kept as text. The drawing has presentation attributes, so that it is drawn
+// in feed readers and other places without the stylesheet. The source follows
+// the drawing in a closed details element, so it remains for feed readers
+// that drop SVG and for plain-text output.
+func writeDiagram(w io.Writer, src []byte) error {
+	d := newDiagram(string(src))
+
+	var b strings.Builder
+	width, height := d.cols*diagramCellWidth, len(d.grid)*diagramCellHeight
+	fmt.Fprintf(
+		&b,
+		`<figure class="diagram"><svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" `+
+			`width="%d" height="%d" role="img" aria-label="Diagram" `+
+			`fill="none" stroke="currentColor" stroke-width="1.5">`,
+		width, height, width, height,
+	)
+	for row := range d.grid {
+		d.writeRow(&b, row)
+	}
+	b.WriteString(`</svg><details><pre class="diagram-source"><code class="language-diagram">`)
+	b.WriteString(html.EscapeString(string(src)))
+	b.WriteString("</code></pre></details></figure>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeRow draws the lines of a row and writes its text. Adjacent text
+// characters are written as one text element.
+func (d *diagram) writeRow(b *strings.Builder, row int) {
+	var (
+		text  []rune
+		start int
+	)
+	flush := func() {
+		if len(text) == 0 {
+			return
+		}
+		fmt.Fprintf(
+			b,
+			`<text x="%d" y="%d" fill="currentColor" stroke="none" font-family="monospace" `+
+				`font-size="13">%s</text>`,
+			start*diagramCellWidth,
+			row*diagramCellHeight+diagramCellHeight*3/4,
+			html.EscapeString(string(text)),
+		)
+		text = nil
+	}
+
+	for col, c := range d.grid[row] {
+		if c == ' ' || d.draw(b, row, col) {
+			flush()
+			continue
+		}
+		if len(text) == 0 {
+			start = col
+		}
+		text = append(text, c)
+	}
+	flush()
+}
+
+// draw draws the character at the row and column if it is part of a line,
+// and reports whether it was.
+func (d *diagram) draw(b *strings.Builder, row, col int) bool {
+	var (
+		left   = strings.ContainsRune(diagramConnectsH, d.at(row, col-1))
+		right  = strings.ContainsRune(diagramConnectsH, d.at(row, col+1))
+		up     = strings.ContainsRune(diagramConnectsV, d.at(row-1, col))
+		down   = strings.ContainsRune(diagramConnectsV, d.at(row+1, col))
+		x0, y0 = col * diagramCellWidth, row * diagramCellHeight
+		x1, y1 = x0 + diagramCellWidth, y0 + diagramCellHeight
+		cx, cy = x0 + diagramCellWidth/2, y0 + diagramCellHeight/2
+	)
+
+	line := func(ax, ay, bx, by int) {
+		fmt.Fprintf(b, `<line x1="%d" y1="%d" x2="%d" y2="%d"></line>`, ax, ay, bx, by)
+	}
+	arrow := func(tx, ty, ax, ay, bx, by int) {
+		fmt.Fprintf(b, `<polygon points="%d,%d %d,%d %d,%d" fill="currentColor"></polygon>`, tx, ty, ax, ay, bx, by)
+	}
+	// a slash between words, as in and/or, is text
+	diagonal := func(dr, dc int) bool {
+		if isWordRune(d.at(row, col-1)) || isWordRune(d.at(row, col+1)) {
+			return false
+		}
+		return strings.ContainsRune(diagramConnectsD, d.at(row+dr, col+dc)) ||
+			strings.ContainsRune(diagramConnectsD, d.at(row-dr, col-dc))
+	}
+
+	switch c := d.at(row, col); c {
+	case '-':
+		if !left && !right {
+			return false
+		}
+		line(x0, cy, x1, cy)
+	case '_':
+		if !strings.ContainsRune("_|", d.at(row, col-1)) &&
+			!strings.ContainsRune("_|", d.at(row, col+1)) {
+			return false
+		}
+		line(x0, y1, x1, y1)
+	case '|':
+		line(cx, y0, cx, y1)
+	case '/':
+		if !diagonal(-1, 1) {
+			return false
+		}
+		line(x0, y1, x1, y0)
+	case '\\':
+		if !diagonal(-1, -1) {
+			return false
+		}
+		line(x0, y0, x1, y1)
+	case '+', '*':
+		if !left && !right && !up && !down {
+			return false
+		}
+		if left {
+			line(x0, cy, cx, cy)
+		}
+		if right {
+			line(cx, cy, x1, cy)
+		}
+		if up {
+			line(cx, y0, cx, cy)
+		}
+		if down {
+			line(cx, cy, cx, y1)
+		}
+		if c == '*' {
+			fmt.Fprintf(b, `<circle cx="%d" cy="%d" r="%d" fill="currentColor"></circle>`, cx, cy, diagramCellWidth/3)
+		}
+	case '.', '\'':
+		// a rounded corner joins a horizontal line to a vertical one
+		vertical, vy := down, y1
+		if c == '\'' {
+			vertical, vy = up, y0
+		}
+		if !vertical || !left && !right {
+			return false
+		}
+		if left {
+			fmt.Fprintf(b, `<path d="M %d %d Q %d %d %d %d"></path>`, x0, cy, cx, cy, cx, vy)
+		}
+		if right {
+			fmt.Fprintf(b, `<path d="M %d %d Q %d %d %d %d"></path>`, x1, cy, cx, cy, cx, vy)
+		}
+	case '>':
+		if !left {
+			return false
+		}
+		line(x0, cy, cx, cy)
+		arrow(x1, cy, x0, cy-4, x0, cy+4)
+	case '<':
+		if !right {
+			return false
+		}
+		line(cx, cy, x1, cy)
+		arrow(x0, cy, x1, cy-4, x1, cy+4)
+	case '^':
+		if !down {
+			return false
+		}
+		line(cx, cy, cx, y1)
+		arrow(cx, y0, cx-4, cy, cx+4, cy)
+	case 'v', 'V':
+		if !up || left || right {
+			return false
+		}
+		line(cx, y0, cx, cy)
+		arrow(cx, y1, cx-4, cy, cx+4, cy)
+	default:
+		return false
+	}
+
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,65 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteDiagram(t *testing.T) {
+	src := `+-----+     .----.
+| web |---->| db |
++-----+     '----'
+well-known a/b   /
+                /`
+
+	var buf bytes.Buffer
+	if err := writeDiagram(&buf, []byte(src)); err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 144 80" width="144" height="80"`,
+		`fill="none" stroke="currentColor" stroke-width="1.5">`,
+		`<text x="16" y="28" fill="currentColor" stroke="none" font-family="monospace" font-size="13">web</text>`,
+		`<text x="112" y="28" fill="currentColor" stroke="none" font-family="monospace" font-size="13">db</text>`,
+		`<polygon points="96,24 88,20 88,28" fill="currentColor"></polygon>`,
+		`<path d="M 104 8 Q 100 8 100 16"></path>`,
+		`>well-known</text>`,
+		`<text x="88" y="60"`,
+		`<line x1="136" y1="64" x2="144" y2="48"></line>`,
+		`</svg><details><pre class="diagram-source"><code class="language-diagram">+-----+`,
+		`well-known a/b   /`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected '%s' in '%s'", want, out)
+		}
+	}
+}
+
+func TestDiagramFence(t *testing.T) {
+	src := "```diagram\n+--+\n```\n\n```go\nx := 1\n```\n"
+
+	for _, engine := range []string{EngineBlackfriday, EngineCommonMark} {
+		t.Run(engine, func(t *testing.T) {
+			r, err := New(Options{Engine: engine, Extensions: []string{ExtDiagrams}})
+			if err != nil {
+				t.Fatalf("Expected nil but got '%v'", err)
+			}
+
+			html, err := r.Render([]byte(src))
+			if err != nil {
+				t.Fatalf("Expected nil but got '%v'", err)
+			}
+			for _, want := range []string{
+				`<figure class="diagram"><svg`,
+				`<pre><code class="language-go">x := 1`,
+			} {
+				if !strings.Contains(string(html), want) {
+					t.Errorf("Expected '%s' in '%s'", want, html)
+				}
+			}
+		})
+	}
+}
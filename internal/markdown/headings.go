@@ -0,0 +1,206 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Heading is an entry in the outline of a document.
+type Heading struct {
+	Level int    `json:"level"`
+	ID    string `json:"id"`
+	Text  string `json:"text"`
+}
+
+// Document is the result of rendering markdown.
+type Document struct {
+	HTML    []byte
+	Outline []Heading
+	// Dynamic reports whether the document expands a dynamic shortcode, so
+	// its HTML changes with the content the shortcode shows.
+	Dynamic bool
+}
+
+var headingLevels = map[atom.Atom]int{
+	atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6,
+}
+
+// processHeadings gives every heading in the HTML an ID and returns the
+// outline of the document. IDs set by the engine are kept; the others are
+// derived from the heading text, so they stay the same as long as the text
+// does. With anchors set, a link to the heading is added to each of them.
+// Everything but the headings is copied as it is.
+func processHeadings(src []byte, anchors bool) ([]byte, []Heading) {
+	var (
+		out     bytes.Buffer
+		outline []Heading
+		ids     = map[string]int{}
+		z       = nethtml.NewTokenizer(bytes.NewReader(src))
+	)
+
+	// IDs set by the engine are reserved first, so the derived ones do not
+	// collide with them.
+	for _, h := range scanHeadings(src) {
+		if h.ID != "" {
+			ids[h.ID]++
+		}
+	}
+
+	for {
+		tt := z.Next()
+		if tt == nethtml.ErrorToken {
+			break
+		}
+
+		// Token unescapes and lower cases the buffer Raw points to, so the raw
+		// bytes are copied first.
+		raw := bytes.Clone(z.Raw())
+		if tt != nethtml.StartTagToken {
+			out.Write(raw)
+			continue
+		}
+
+		tok := z.Token()
+		level, ok := headingLevels[tok.DataAtom]
+		if !ok {
+			out.Write(raw)
+			continue
+		}
+
+		// Collect the content of the heading up to its end tag. The TeX
+		// source of formulas is left out of the text.
+		var (
+			inner, text bytes.Buffer
+			annotation  bool
+		)
+	content:
+		for {
+			tt := z.Next()
+			switch tt {
+			case nethtml.ErrorToken:
+				break content
+			case nethtml.TextToken:
+				if !annotation {
+					text.WriteString(html.UnescapeString(string(z.Raw())))
+				}
+			case nethtml.StartTagToken, nethtml.EndTagToken:
+				raw := bytes.Clone(z.Raw())
+				name, _ := z.TagName()
+				switch atom.Lookup(name) {
+				case tok.DataAtom:
+					if tt == nethtml.EndTagToken {
+						break content
+					}
+				case atom.Annotation:
+					annotation = tt == nethtml.StartTagToken
+				}
+				inner.Write(raw)
+				continue
+			}
+			inner.Write(z.Raw())
+		}
+
+		h := Heading{Level: level, Text: strings.Join(strings.Fields(text.String()), " ")}
+		for _, attr := range tok.Attr {
+			if attr.Key == "id" {
+				h.ID = attr.Val
+			}
+		}
+		if h.ID == "" {
+			h.ID = uniqueID(slugify(h.Text), ids)
+			tok.Attr = append(tok.Attr, nethtml.Attribute{Key: "id", Val: h.ID})
+		}
+		outline = append(outline, h)
+
+		out.WriteString(tok.String())
+		out.Write(inner.Bytes())
+		if anchors {
+			fmt.Fprintf(
+				&out,
+				` <a class="heading-anchor" href="#%s" title="Link to this section">#</a>`,
+				html.EscapeString(h.ID),
+			)
+		}
+		fmt.Fprintf(&out, "</%s>", tok.Data)
+	}
+
+	return out.Bytes(), outline
+}
+
+// scanHeadings returns the headings of the HTML with the IDs they already
+// have.
+func scanHeadings(src []byte) []Heading {
+	var headings []Heading
+
+	z := nethtml.NewTokenizer(bytes.NewReader(src))
+	for {
+		tt := z.Next()
+		if tt == nethtml.ErrorToken {
+			return headings
+		}
+
+		tok := z.Token()
+		level, ok := headingLevels[tok.DataAtom]
+		if tt != nethtml.StartTagToken || !ok {
+			continue
+		}
+
+		h := Heading{Level: level}
+		for _, attr := range tok.Attr {
+			if attr.Key == "id" {
+				h.ID = attr.Val
+			}
+		}
+		headings = append(headings, h)
+	}
+}
+
+// slugify turns heading text into an ID: lower case letters and digits
+// separated by dashes.
+func slugify(text string) string {
+	var b strings.Builder
+
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		default:
+			dash = true
+		}
+	}
+
+	if b.Len() == 0 {
+		return "section"
+	}
+
+	return b.String()
+}
+
+// uniqueID returns the ID, or the ID with a number appended if it is taken.
+func uniqueID(id string, ids map[string]int) string {
+	n := ids[id]
+	ids[id]++
+	if n == 0 {
+		return id
+	}
+
+	for {
+		candidate := fmt.Sprintf("%s-%d", id, n)
+		if ids[candidate] == 0 {
+			ids[candidate]++
+			return candidate
+		}
+		n++
+	}
+}
@@ -0,0 +1,64 @@
+package markdown
+
+import (
+	"testing"
+
+	"textonly.islandwind.me/internal/assert"
+)
+
+func TestProcessHeadings(t *testing.T) {
+	src := `<h2>Getting started</h2>
+<p><A HREF="/a?b=1&amp;c=2">Text</A></p>
+<h2 id="custom">Custom &amp; <code>code</code></h2>
+<h3>Getting started</h3>
+<pre><code>&lt;h2&gt;not a heading&lt;/h2&gt;</code></pre>`
+
+	html, outline := processHeadings([]byte(src), false)
+
+	want := `<h2 id="getting-started">Getting started</h2>
+<p><A HREF="/a?b=1&amp;c=2">Text</A></p>
+<h2 id="custom">Custom &amp; <code>code</code></h2>
+<h3 id="getting-started-1">Getting started</h3>
+<pre><code>&lt;h2&gt;not a heading&lt;/h2&gt;</code></pre>`
+	assert.Equal(t, string(html), want)
+
+	wantOutline := []Heading{
+		{Level: 2, ID: "getting-started", Text: "Getting started"},
+		{Level: 2, ID: "custom", Text: "Custom & code"},
+		{Level: 3, ID: "getting-started-1", Text: "Getting started"},
+	}
+	assert.Equal(t, len(outline), len(wantOutline))
+	for i := range outline {
+		if i < len(wantOutline) {
+			assert.Equal(t, outline[i], wantOutline[i])
+		}
+	}
+}
+
+func TestProcessHeadingsAnchors(t *testing.T) {
+	html, _ := processHeadings([]byte(`<h2>Intro</h2>`), true)
+	assert.Equal(
+		t,
+		string(html),
+		`<h2 id="intro">Intro <a class="heading-anchor" href="#intro" `+
+			`title="Link to this section">#</a></h2>`,
+	)
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "Hello, World!", want: "hello-world"},
+		{text: "Blåbær og øl", want: "blåbær-og-øl"},
+		{text: "  Go 1.22  ", want: "go-1-22"},
+		{text: "!!!", want: "section"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, slugify(tt.text), tt.want)
+		})
+	}
+}
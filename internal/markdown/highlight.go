@@ -0,0 +1,259 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// codeOptions are the options of a fenced code block, given in braces after
+// the language in the info string, such as ```go {3-5,8 linenos}.
+type codeOptions struct {
+	lineNumbers bool
+	highlight   [][2]int
+}
+
+// parseInfo splits the info string of a fenced code block into the language
+// and the options. Line numbers are shown when lineNumbers is set, unless
+// the block turns them off with nolinenos.
+func parseInfo(info string, lineNumbers bool) (string, codeOptions) {
+	opts := codeOptions{lineNumbers: lineNumbers}
+
+	lang, rest, _ := strings.Cut(strings.TrimSpace(info), " ")
+	if strings.HasPrefix(lang, "{") {
+		lang, rest = "", info
+	}
+
+	start, end := strings.Index(rest, "{"), strings.LastIndex(rest, "}")
+	if start < 0 || end < start {
+		return lang, opts
+	}
+
+	fields := strings.FieldsFunc(rest[start+1:end], func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	for _, field := range fields {
+		switch field {
+		case "linenos":
+			opts.lineNumbers = true
+		case "nolinenos":
+			opts.lineNumbers = false
+		default:
+			if r, ok := parseRange(field); ok {
+				opts.highlight = append(opts.highlight, r)
+			}
+		}
+	}
+
+	return lang, opts
+}
+
+// parseRange parses a line number or a range of line numbers, such as 3 or
+// 3-5.
+func parseRange(s string) ([2]int, bool) {
+	from, to, isRange := strings.Cut(s, "-")
+
+	start, err := strconv.Atoi(from)
+	if err != nil || start < 1 {
+		return [2]int{}, false
+	}
+	if !isRange {
+		return [2]int{start, start}, true
+	}
+
+	end, err := strconv.Atoi(to)
+	if err != nil || end < start {
+		return [2]int{}, false
+	}
+
+	return [2]int{start, end}, true
+}
+
+// highlighter renders fenced code blocks with syntax highlighting. The tokens
+// get class names, so the colours come from a stylesheet, which can be
+// generated with WriteHighlightCSS.
+type highlighter struct {
+	lineNumbers bool
+}
+
+// render writes the highlighted code block. Blocks in an unknown language are
+// still wrapped the same way, without highlighting.
+func (h *highlighter) render(w io.Writer, info string, code []byte) error {
+	lang, opts := parseInfo(info, h.lineNumbers)
+
+	lexer := lexers.Get(lang)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	iterator, err := lexer.Tokenise(nil, string(code))
+	if err != nil {
+		return err
+	}
+
+	formatter := chromahtml.New(
+		chromahtml.WithClasses(true),
+		chromahtml.WithLineNumbers(opts.lineNumbers),
+		chromahtml.HighlightLines(opts.highlight),
+		chromahtml.WithPreWrapper(codeWrapper{lang: lang}),
+	)
+
+	return formatter.Format(w, styles.Fallback, iterator)
+}
+
+// codeWrapper wraps the highlighted code in pre and code elements, keeping the
+// language class the engines add to code blocks.
+type codeWrapper struct {
+	lang string
+}
+
+func (c codeWrapper) Start(code bool, styleAttr string) string {
+	if !code {
+		return fmt.Sprintf(`<pre%s>`, styleAttr)
+	}
+	if c.lang == "" {
+		return fmt.Sprintf(`<pre%s><code>`, styleAttr)
+	}
+
+	return fmt.Sprintf(`<pre%s><code class="language-%s">`, styleAttr, html.EscapeString(c.lang))
+}
+
+func (c codeWrapper) End(code bool) string {
+	if code {
+		return `</code></pre>`
+	}
+
+	return `</pre>`
+}
+
+// HighlightStyles returns the names of the styles WriteHighlightCSS accepts.
+func HighlightStyles() []string {
+	return styles.Names()
+}
+
+// WriteHighlightCSS writes the stylesheet of the named style for highlighted
+// code blocks.
+func WriteHighlightCSS(w io.Writer, style string) error {
+	s, ok := styles.Registry[style]
+	if !ok {
+		return fmt.Errorf("unknown highlight style %q", style)
+	}
+
+	var buf bytes.Buffer
+	formatter := chromahtml.New(chromahtml.WithClasses(true), chromahtml.WithLineNumbers(true))
+	if err := formatter.WriteCSS(&buf, s); err != nil {
+		return err
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// newHighlighter returns a highlighter if the extensions enable highlighting.
+func newHighlighter(extensions []string) *highlighter {
+	if !slices.Contains(extensions, ExtHighlighting) {
+		return nil
+	}
+
+	return &highlighter{lineNumbers: slices.Contains(extensions, ExtLineNumbers)}
+}
+
+// codeBlocks renders fenced code blocks as highlighted code or diagrams.
+type codeBlocks struct {
+	highlighter *highlighter
+	diagrams    bool
+}
+
+// newCodeBlocks returns a code block renderer if the extensions enable
+// highlighting or diagrams.
+func newCodeBlocks(extensions []string) *codeBlocks {
+	c := &codeBlocks{
+		highlighter: newHighlighter(extensions),
+		diagrams:    slices.Contains(extensions, ExtDiagrams),
+	}
+	if c.highlighter == nil && !c.diagrams {
+		return nil
+	}
+
+	return c
+}
+
+// render writes the code block followed by a newline. It reports false when
+// the block is left to the engine.
+func (c *codeBlocks) render(w io.Writer, info string, code []byte) (bool, error) {
+	lang, _ := parseInfo(info, false)
+
+	switch {
+	case c.diagrams && lang == diagramLanguage:
+		return true, writeDiagram(w, code)
+	case c.highlighter != nil:
+		if err := c.highlighter.render(w, info, code); err != nil {
+			return true, err
+		}
+		_, err := io.WriteString(w, "\n")
+		return true, err
+	}
+
+	return false, nil
+}
+
+// goldmarkCodeRenderer renders fenced code blocks. Blocks the code block
+// renderer leaves alone are written the way goldmark writes them.
+type goldmarkCodeRenderer struct {
+	code *codeBlocks
+}
+
+func (r *goldmarkCodeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+}
+
+func (r *goldmarkCodeRenderer) renderFencedCodeBlock(
+	w util.BufWriter,
+	source []byte,
+	node ast.Node,
+	entering bool,
+) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*ast.FencedCodeBlock)
+
+	var info string
+	if n.Info != nil {
+		info = string(n.Info.Segment.Value(source))
+	}
+
+	var code bytes.Buffer
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		code.Write(line.Value(source))
+	}
+
+	ok, err := r.code.render(w, info, code.Bytes())
+	if err != nil {
+		return ast.WalkStop, err
+	}
+	if !ok {
+		lang, _ := parseInfo(info, false)
+		_, _ = io.WriteString(w, codeWrapper{lang: lang}.Start(true, ""))
+		_, _ = w.Write(util.EscapeHTML(code.Bytes()))
+		_, _ = io.WriteString(w, codeWrapper{lang: lang}.End(true)+"\n")
+	}
+
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,74 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"textonly.islandwind.me/internal/assert"
+)
+
+func TestParseInfo(t *testing.T) {
+	tests := []struct {
+		name        string
+		info        string
+		lineNumbers bool
+		lang        string
+		numbered    bool
+		highlight   [][2]int
+	}{
+		{name: "language only", info: "go", lang: "go"},
+		{name: "range", info: "go {3-5}", lang: "go", highlight: [][2]int{{3, 5}}},
+		{
+			name:      "lines and numbers",
+			info:      "go {1,3-4 linenos}",
+			lang:      "go",
+			numbered:  true,
+			highlight: [][2]int{{1, 1}, {3, 4}},
+		},
+		{name: "numbers off", info: "sh {nolinenos}", lineNumbers: true, lang: "sh"},
+		{name: "no language", info: "{2}", highlight: [][2]int{{2, 2}}},
+		{name: "invalid range", info: "go {5-3}", lang: "go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lang, opts := parseInfo(tt.info, tt.lineNumbers)
+			assert.Equal(t, lang, tt.lang)
+			assert.Equal(t, opts.lineNumbers, tt.numbered)
+			assert.Equal(t, len(opts.highlight), len(tt.highlight))
+			for i := range opts.highlight {
+				if i < len(tt.highlight) {
+					assert.Equal(t, opts.highlight[i], tt.highlight[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHighlighting(t *testing.T) {
+	src := []byte("```go {2}\npackage main\nfunc main() {}\n```\n")
+
+	for _, engine := range []string{EngineBlackfriday, EngineCommonMark} {
+		t.Run(engine, func(t *testing.T) {
+			r, err := New(Options{Engine: engine, Extensions: []string{ExtHighlighting}})
+			if err != nil {
+				t.Fatalf("Expected nil but got '%v'", err)
+			}
+
+			html, err := r.Render(src)
+			if err != nil {
+				t.Fatalf("Expected nil but got '%v'", err)
+			}
+
+			for _, want := range []string{
+				`<pre class="chroma"><code class="language-go">`,
+				`<span class="kn">package</span>`,
+				`<span class="line hl">`,
+			} {
+				if !strings.Contains(string(html), want) {
+					t.Errorf("Expected '%s' in '%s'", want, html)
+				}
+			}
+		})
+	}
+}
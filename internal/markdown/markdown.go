@@ -0,0 +1,409 @@
+// Package markdown converts markdown to HTML through named renderers. Each
+// renderer combines an engine with a set of extensions, and posts store the
+// name of the renderer that produced them, so that changing the configuration
+// of new renderers does not change how existing posts look.
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/russross/blackfriday/v2"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// Engines.
+const (
+	// EngineBlackfriday is the markdown engine used since the first post.
+	EngineBlackfriday = "blackfriday"
+	// EngineCommonMark is a CommonMark compliant engine.
+	EngineCommonMark = "commonmark"
+)
+
+// Extensions.
+const (
+	ExtAutolinks      = "autolinks"
+	ExtDiagrams       = "diagrams"
+	ExtFootnotes      = "footnotes"
+	ExtHeadingAnchors = "heading_anchors"
+	ExtHighlighting   = "highlighting"
+	ExtLineNumbers    = "line_numbers"
+	ExtMath           = "math"
+	ExtShortcodes     = "shortcodes"
+	ExtStrikethrough  = "strikethrough"
+	ExtTables         = "tables"
+	ExtTaskLists      = "task_lists"
+	ExtTypography     = "typography"
+	ExtWikiLinks      = "wikilinks"
+)
+
+// Legacy is the name of the renderer posts were rendered with before
+// renderers could be configured: blackfriday with its default options. It is
+// always available.
+const Legacy = "legacy"
+
+var (
+	ErrUnknownRenderer  = errors.New("unknown renderer")
+	ErrUnknownEngine    = errors.New("unknown markdown engine")
+	ErrUnknownExtension = errors.New("unknown markdown extension")
+)
+
+// Renderer converts markdown to HTML. The HTML is not sanitized.
+type Renderer interface {
+	Render(src []byte) ([]byte, error)
+}
+
+// Options selects the engine and extensions of a renderer.
+type Options struct {
+	Engine     string   `json:"engine"`
+	Extensions []string `json:"extensions"`
+}
+
+// New creates a renderer from the options.
+func New(opts Options) (Renderer, error) {
+	var (
+		r   Renderer
+		err error
+	)
+
+	switch opts.Engine {
+	case EngineBlackfriday:
+		r, err = newBlackfriday(opts.Extensions)
+	case EngineCommonMark:
+		r, err = newCommonMark(opts.Extensions)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownEngine, opts.Engine)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if slices.Contains(opts.Extensions, ExtMath) {
+		r = mathRenderer{Renderer: r}
+	}
+
+	return r, nil
+}
+
+// blackfridayRenderer renders markdown with blackfriday. The HTML renderer
+// keeps state between calls, so a new one is created for every document.
+type blackfridayRenderer struct {
+	extensions blackfriday.Extensions
+	params     blackfriday.HTMLRendererParameters
+	code       *codeBlocks
+}
+
+// blackfridayExtensions maps the extensions to their blackfriday equivalents.
+// Task lists are not supported by blackfriday.
+var blackfridayExtensions = map[string]blackfriday.Extensions{
+	ExtAutolinks:      blackfriday.Autolink,
+	ExtDiagrams:       0,
+	ExtFootnotes:      blackfriday.Footnotes,
+	ExtHeadingAnchors: blackfriday.AutoHeadingIDs,
+	ExtHighlighting:   0,
+	ExtLineNumbers:    0,
+	ExtMath:           0,
+	ExtShortcodes:     0,
+	ExtStrikethrough:  blackfriday.Strikethrough,
+	ExtTables:         blackfriday.Tables,
+	ExtTypography:     0,
+	ExtWikiLinks:      0,
+}
+
+func newBlackfriday(extensions []string) (*blackfridayRenderer, error) {
+	r := &blackfridayRenderer{
+		extensions: blackfriday.NoIntraEmphasis |
+			blackfriday.FencedCode |
+			blackfriday.HeadingIDs |
+			blackfriday.SpaceHeadings |
+			blackfriday.BackslashLineBreak,
+		params: blackfriday.HTMLRendererParameters{Flags: blackfriday.UseXHTML},
+	}
+
+	for _, name := range extensions {
+		ext, ok := blackfridayExtensions[name]
+		if !ok {
+			return nil, fmt.Errorf(
+				"%w: %q is not supported by %s", ErrUnknownExtension, name, EngineBlackfriday,
+			)
+		}
+		r.extensions |= ext
+
+		if name == ExtTypography {
+			r.params.Flags |= blackfriday.Smartypants | blackfriday.SmartypantsFractions |
+				blackfriday.SmartypantsDashes | blackfriday.SmartypantsLatexDashes
+		}
+	}
+	r.code = newCodeBlocks(extensions)
+
+	return r, nil
+}
+
+func (r *blackfridayRenderer) Render(src []byte) ([]byte, error) {
+	var renderer blackfriday.Renderer = blackfriday.NewHTMLRenderer(r.params)
+	if r.code != nil {
+		renderer = &blackfridayCodeRenderer{
+			HTMLRenderer: renderer.(*blackfriday.HTMLRenderer),
+			code:         r.code,
+		}
+	}
+
+	return blackfriday.Run(
+		src,
+		blackfriday.WithExtensions(r.extensions),
+		blackfriday.WithRenderer(renderer),
+	), nil
+}
+
+// blackfridayCodeRenderer renders fenced code blocks and leaves everything
+// else to the HTML renderer.
+type blackfridayCodeRenderer struct {
+	*blackfriday.HTMLRenderer
+	code *codeBlocks
+}
+
+func (r *blackfridayCodeRenderer) RenderNode(
+	w io.Writer,
+	node *blackfriday.Node,
+	entering bool,
+) blackfriday.WalkStatus {
+	if node.Type != blackfriday.CodeBlock || !node.IsFenced {
+		return r.HTMLRenderer.RenderNode(w, node, entering)
+	}
+
+	var buf bytes.Buffer
+	ok, err := r.code.render(&buf, string(node.Info), node.Literal)
+	if !ok || err != nil {
+		return r.HTMLRenderer.RenderNode(w, node, entering)
+	}
+	_, _ = buf.WriteTo(w)
+
+	return blackfriday.GoToNext
+}
+
+// legacyRenderer renders markdown with blackfriday and its default options.
+type legacyRenderer struct{}
+
+func (legacyRenderer) Render(src []byte) ([]byte, error) {
+	return blackfriday.Run(src), nil
+}
+
+// commonMarkRenderer renders markdown with goldmark.
+type commonMarkRenderer struct {
+	md goldmark.Markdown
+}
+
+// goldmarkExtensions maps the extensions to their goldmark equivalents.
+// Heading anchors are a parser option, highlighting and diagrams are a node
+// renderer and math, shortcodes and wiki links are handled around the engine,
+// so they are handled separately.
+var goldmarkExtensions = map[string]goldmark.Extender{
+	ExtAutolinks:      extension.Linkify,
+	ExtDiagrams:       nil,
+	ExtFootnotes:      extension.Footnote,
+	ExtHeadingAnchors: nil,
+	ExtHighlighting:   nil,
+	ExtLineNumbers:    nil,
+	ExtMath:           nil,
+	ExtShortcodes:     nil,
+	ExtStrikethrough:  extension.Strikethrough,
+	ExtTables:         extension.Table,
+	ExtTaskLists:      extension.TaskList,
+	ExtTypography:     extension.Typographer,
+	ExtWikiLinks:      nil,
+}
+
+func newCommonMark(extensions []string) (*commonMarkRenderer, error) {
+	var (
+		exts            []goldmark.Extender
+		parserOptions   []parser.Option
+		rendererOptions = []renderer.Option{html.WithUnsafe(), html.WithXHTML()}
+	)
+
+	for _, name := range extensions {
+		ext, ok := goldmarkExtensions[name]
+		if !ok {
+			return nil, fmt.Errorf(
+				"%w: %q is not supported by %s", ErrUnknownExtension, name, EngineCommonMark,
+			)
+		}
+		if ext != nil {
+			exts = append(exts, ext)
+		}
+
+		if name == ExtHeadingAnchors {
+			parserOptions = append(parserOptions, parser.WithAutoHeadingID())
+		}
+	}
+
+	if code := newCodeBlocks(extensions); code != nil {
+		rendererOptions = append(rendererOptions, renderer.WithNodeRenderers(
+			util.Prioritized(&goldmarkCodeRenderer{code: code}, 100),
+		))
+	}
+
+	// Raw HTML is rendered as it is, like blackfriday does, and left to the
+	// sanitizer.
+	md := goldmark.New(
+		goldmark.WithExtensions(exts...),
+		goldmark.WithParserOptions(parserOptions...),
+		goldmark.WithRendererOptions(rendererOptions...),
+	)
+
+	return &commonMarkRenderer{md: md}, nil
+}
+
+func (r *commonMarkRenderer) Render(src []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := r.md.Convert(src, &buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Registry holds the named renderers, the name of the one used for new
+// content, the shortcode handlers and the resolver of wiki links.
+type Registry struct {
+	renderers  map[string]registered
+	def        string
+	shortcodes map[string]ShortcodeFunc
+	// params are the names of the parameters each shortcode takes.
+	params map[string][]string
+	// dynamic are the names of the shortcodes that show other content.
+	dynamic map[string]bool
+	resolve LinkResolver
+}
+
+// registered is a renderer in the registry.
+type registered struct {
+	Renderer
+	// anchors adds links to the headings.
+	anchors bool
+	// shortcodes expands the shortcodes in the markdown.
+	shortcodes bool
+	// wikilinks resolves the wiki links in the markdown.
+	wikilinks bool
+}
+
+// NewRegistry creates the renderers and checks that the default renderer
+// exists. The legacy renderer is always added, as are the built-in
+// shortcodes.
+func NewRegistry(renderers map[string]Options, def string) (*Registry, error) {
+	reg := &Registry{
+		renderers:  map[string]registered{Legacy: {Renderer: legacyRenderer{}}},
+		def:        def,
+		shortcodes: map[string]ShortcodeFunc{},
+		params:     map[string][]string{},
+		dynamic:    map[string]bool{},
+	}
+	reg.registerBuiltinShortcodes()
+
+	for name, opts := range renderers {
+		if name == Legacy {
+			return nil, fmt.Errorf("renderer name %q is reserved", Legacy)
+		}
+
+		r, err := New(opts)
+		if err != nil {
+			return nil, fmt.Errorf("renderer %s: %w", name, err)
+		}
+		reg.renderers[name] = registered{
+			Renderer:   r,
+			anchors:    slices.Contains(opts.Extensions, ExtHeadingAnchors),
+			shortcodes: slices.Contains(opts.Extensions, ExtShortcodes),
+			wikilinks:  slices.Contains(opts.Extensions, ExtWikiLinks),
+		}
+	}
+
+	if reg.def == "" {
+		reg.def = Legacy
+	}
+	if !reg.Has(reg.def) {
+		return nil, fmt.Errorf("default renderer: %w: %q", ErrUnknownRenderer, reg.def)
+	}
+
+	return reg, nil
+}
+
+// Default returns the name of the renderer used for new content.
+func (reg *Registry) Default() string {
+	return reg.def
+}
+
+// Has reports whether a renderer with the name exists.
+func (reg *Registry) Has(name string) bool {
+	_, ok := reg.renderers[name]
+	return ok
+}
+
+// Names returns the sorted names of the renderers.
+func (reg *Registry) Names() []string {
+	names := make([]string, 0, len(reg.renderers))
+	for name := range reg.renderers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// Render converts the markdown with the named renderer, gives its headings
+// IDs and collects the outline of the result. The output of the legacy
+// renderer is left as it is, without an outline. An empty name selects the
+// default renderer.
+func (reg *Registry) Render(name string, src []byte) (*Document, error) {
+	return reg.RenderWithResolver(name, src, reg.resolve)
+}
+
+// RenderWithResolver is Render with the wiki links resolved by resolve
+// instead of the resolver of the registry, for documents whose links depend
+// on who can read them.
+func (reg *Registry) RenderWithResolver(name string, src []byte, resolve LinkResolver) (*Document, error) {
+	if name == "" {
+		name = reg.def
+	}
+
+	r, ok := reg.renderers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownRenderer, name)
+	}
+
+	// wiki links are taken out first, so that links in the inner content
+	// of shortcodes are resolved too
+	var links []wikiLink
+	if r.wikilinks {
+		src, links = extractWikiLinks(src)
+	}
+
+	var (
+		html []byte
+		err  error
+	)
+	if r.shortcodes {
+		html, err = reg.expandShortcodes(r.Renderer, src)
+	} else {
+		html, err = r.Render(src)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(links) > 0 {
+		html = replaceWikiLinks(html, links, resolve)
+	}
+
+	doc := &Document{HTML: html, Dynamic: r.shortcodes && reg.hasDynamicShortcode(src)}
+	if name != Legacy {
+		doc.HTML, doc.Outline = processHeadings(html, r.anchors)
+	}
+
+	return doc, nil
+}
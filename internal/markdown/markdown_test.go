@@ -0,0 +1,112 @@
+package markdown
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"textonly.islandwind.me/internal/assert"
+)
+
+func TestRegistryRender(t *testing.T) {
+	reg, err := NewRegistry(map[string]Options{
+		"commonmark": {
+			Engine:     EngineCommonMark,
+			Extensions: []string{ExtTables, ExtTaskLists, ExtFootnotes, ExtHeadingAnchors},
+		},
+		"blackfriday": {
+			Engine:     EngineBlackfriday,
+			Extensions: []string{ExtTables, ExtHeadingAnchors},
+		},
+	}, "commonmark")
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+
+	tests := []struct {
+		name     string
+		renderer string
+		src      string
+		want     string
+	}{
+		{
+			name:     "legacy",
+			renderer: Legacy,
+			src:      "# Title",
+			want:     `<h1>Title</h1>`,
+		},
+		{
+			name:     "default",
+			renderer: "",
+			src:      "# Title",
+			want:     `<h1 id="title">Title <a class="heading-anchor" href="#title"`,
+		},
+		{
+			name:     "task list",
+			renderer: "commonmark",
+			src:      "- [x] done",
+			want:     `<input checked="" disabled="" type="checkbox" /> done`,
+		},
+		{
+			name:     "footnote",
+			renderer: "commonmark",
+			src:      "Text[^1]\n\n[^1]: Note",
+			want:     `class="footnotes"`,
+		},
+		{
+			name:     "table",
+			renderer: "blackfriday",
+			src:      "a | b\n--- | ---\n1 | 2\n",
+			want:     `<table>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := reg.Render(tt.renderer, []byte(tt.src))
+			if err != nil {
+				t.Fatalf("Expected nil but got '%v'", err)
+			}
+			if !strings.Contains(string(doc.HTML), tt.want) {
+				t.Errorf("Expected '%s' in '%s'", tt.want, doc.HTML)
+			}
+		})
+	}
+
+	_, err = reg.Render("missing", []byte("text"))
+	assert.Equal(t, errors.Is(err, ErrUnknownRenderer), true)
+}
+
+func TestNewRegistryErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		renderers map[string]Options
+		def       string
+		want      error
+	}{
+		{
+			name:      "unknown engine",
+			renderers: map[string]Options{"new": {Engine: "markdown.pl"}},
+			want:      ErrUnknownEngine,
+		},
+		{
+			name: "unsupported extension",
+			renderers: map[string]Options{
+				"new": {Engine: EngineBlackfriday, Extensions: []string{ExtTaskLists}},
+			},
+			want: ErrUnknownExtension,
+		},
+		{
+			name: "unknown default",
+			def:  "missing",
+			want: ErrUnknownRenderer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRegistry(tt.renderers, tt.def)
+			assert.Equal(t, errors.Is(err, tt.want), true)
+		})
+	}
+}
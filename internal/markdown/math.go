@@ -0,0 +1,212 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// mathSpan is a formula taken out of the markdown source.
+type mathSpan struct {
+	tex     string
+	display bool
+}
+
+// mathPlaceholder marks the position of a formula in the markdown while it is
+// rendered. It is made of letters and digits only, so no engine changes it.
+const mathPlaceholder = "TEXTONLYMATH%dX"
+
+// mathRenderer converts $...$ and $$...$$ formulas to MathML. The formulas
+// are replaced by placeholders before the markdown is rendered, so that the
+// engine does not treat underscores and backslashes in them as markdown, and
+// the MathML is put in their place afterwards.
+type mathRenderer struct {
+	Renderer
+}
+
+func (r mathRenderer) Render(src []byte) ([]byte, error) {
+	src, spans := extractMath(src)
+
+	out, err := r.Renderer.Render(src)
+	if err != nil || len(spans) == 0 {
+		return out, err
+	}
+
+	for i, span := range spans {
+		placeholder := []byte(fmt.Sprintf(mathPlaceholder, i))
+
+		mathML, err := TeXToMathML(span.tex, span.display)
+		if err != nil {
+			mathML = mathFallback(span)
+		}
+
+		// a formula on its own line is a paragraph of its own
+		if span.display {
+			paragraph := append(append([]byte("<p>"), placeholder...), "</p>"...)
+			out = bytes.Replace(out, paragraph, []byte(mathML), 1)
+		}
+		out = bytes.Replace(out, placeholder, []byte(mathML), 1)
+
+		// heading IDs generated by the engine contain the placeholder
+		out = bytes.ReplaceAll(out, bytes.ToLower(placeholder), []byte(slugify(span.tex)))
+	}
+
+	return out, nil
+}
+
+// mathFallback shows a formula that cannot be converted as code.
+func mathFallback(span mathSpan) string {
+	if span.display {
+		return `<pre><code class="language-tex">` + html.EscapeString(span.tex) + `</code></pre>`
+	}
+	return `<code class="language-tex">` + html.EscapeString(span.tex) + `</code>`
+}
+
+// extractMath replaces the formulas in the markdown with placeholders. Code
+// blocks and code spans are left alone, as are escaped dollar signs. An
+// inline formula has to start with a non-space character after the opening
+// dollar, end with one before the closing dollar, and must not be followed by
+// a digit, so amounts like $5 and $10 are not taken for a formula.
+func extractMath(src []byte) ([]byte, []mathSpan) {
+	var (
+		out   bytes.Buffer
+		spans []mathSpan
+		fence string
+	)
+
+	s := string(src)
+	for len(s) > 0 {
+		line, rest, found := strings.Cut(s, "\n")
+		if found {
+			line += "\n"
+		}
+
+		// fenced code blocks are copied as they are
+		trimmed := strings.TrimLeft(line, " ")
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) &&
+				strings.TrimSpace(strings.TrimLeft(trimmed, fence[:1])) == "" {
+				fence = ""
+			}
+			out.WriteString(line)
+			s = rest
+			continue
+		}
+		if f := codeFence(trimmed); f != "" {
+			fence = f
+			out.WriteString(line)
+			s = rest
+			continue
+		}
+
+		// the text up to the next fence is scanned as one block, so that
+		// display formulas can span lines
+		end := len(line)
+		for end < len(s) {
+			next, _, _ := strings.Cut(s[end:], "\n")
+			if codeFence(strings.TrimLeft(next, " ")) != "" {
+				break
+			}
+			end += len(next) + 1
+		}
+		if end > len(s) {
+			end = len(s)
+		}
+
+		spans = scanMath(&out, s[:end], spans)
+		s = s[end:]
+	}
+
+	return out.Bytes(), spans
+}
+
+// codeFence returns the fence a line opens, or an empty string.
+func codeFence(line string) string {
+	for _, c := range []string{"`", "~"} {
+		n := len(line) - len(strings.TrimLeft(line, c))
+		if n >= 3 {
+			return strings.Repeat(c, n)
+		}
+	}
+	return ""
+}
+
+// scanMath copies text to out, replacing the formulas in it with
+// placeholders.
+func scanMath(out *bytes.Buffer, text string, spans []mathSpan) []mathSpan {
+	for i := 0; i < len(text); {
+		c := text[i]
+
+		switch {
+		case c == '\\' && i+1 < len(text):
+			out.WriteString(text[i : i+2])
+			i += 2
+			continue
+		case c == '`':
+			n := len(text[i:]) - len(strings.TrimLeft(text[i:], "`"))
+			run := text[i : i+n]
+			end := strings.Index(text[i+n:], run)
+			if end < 0 {
+				out.WriteString(run)
+				i += n
+				continue
+			}
+			out.WriteString(text[i : i+n+end+n])
+			i += n + end + n
+			continue
+		case c == '$' && strings.HasPrefix(text[i:], "$$"):
+			end := strings.Index(text[i+2:], "$$")
+			if end < 0 || strings.TrimSpace(text[i+2:i+2+end]) == "" {
+				break
+			}
+			tex := text[i+2 : i+2+end]
+			fmt.Fprintf(out, mathPlaceholder, len(spans))
+			spans = append(spans, mathSpan{tex: strings.TrimSpace(tex), display: true})
+			i += 2 + end + 2
+			continue
+		case c == '$':
+			if end := inlineMathEnd(text, i); end > 0 {
+				fmt.Fprintf(out, mathPlaceholder, len(spans))
+				spans = append(spans, mathSpan{tex: text[i+1 : end]})
+				i = end + 1
+				continue
+			}
+		}
+
+		out.WriteByte(c)
+		i++
+	}
+
+	return spans
+}
+
+// inlineMathEnd returns the index of the dollar closing the inline formula
+// opened at start, or -1.
+func inlineMathEnd(text string, start int) int {
+	if start+1 >= len(text) || strings.ContainsRune(" \t\n$", rune(text[start+1])) {
+		return -1
+	}
+
+	for i := start + 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '\n':
+			// formulas do not continue past a blank line
+			if strings.HasPrefix(strings.TrimLeft(text[i+1:], " \t"), "\n") {
+				return -1
+			}
+		case '$':
+			if strings.ContainsRune(" \t\n", rune(text[i-1])) {
+				continue
+			}
+			if i+1 < len(text) && text[i+1] >= '0' && text[i+1] <= '9' {
+				continue
+			}
+			return i
+		}
+	}
+
+	return -1
+}
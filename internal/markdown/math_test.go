@@ -0,0 +1,149 @@
+package markdown
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"textonly.islandwind.me/internal/assert"
+)
+
+func TestTeXToMathML(t *testing.T) {
+	tests := []struct {
+		name    string
+		tex     string
+		display bool
+		want    string
+	}{
+		{
+			name: "fraction",
+			tex:  `\frac{a}{b}`,
+			want: `<mfrac><mi>a</mi><mi>b</mi></mfrac>`,
+		},
+		{
+			name: "scripts",
+			tex:  `x_i^2`,
+			want: `<msubsup><mi>x</mi><mi>i</mi><mn>2</mn></msubsup>`,
+		},
+		{
+			name: "greek",
+			tex:  `\alpha + \Omega`,
+			want: `<mi>α</mi><mo>+</mo><mi mathvariant="normal">Ω</mi>`,
+		},
+		{
+			name:    "sum with limits",
+			tex:     `\sum_{i=1}^n i`,
+			display: true,
+			want:    `<munderover><mo largeop="true">∑</mo>`,
+		},
+		{
+			name: "inline sum",
+			tex:  `\sum_{i=1}^n i`,
+			want: `<msubsup><mo largeop="true">∑</mo>`,
+		},
+		{
+			name:    "integral",
+			tex:     `\int_0^1 f(x)\,dx`,
+			display: true,
+			want:    `<msubsup><mo largeop="true">∫</mo><mn>0</mn><mn>1</mn></msubsup>`,
+		},
+		{
+			name: "matrix",
+			tex:  `\begin{pmatrix} a & b \\ c & d \end{pmatrix}`,
+			want: `<mtable><mtr><mtd><mi>a</mi></mtd><mtd><mi>b</mi></mtd></mtr>` +
+				`<mtr><mtd><mi>c</mi></mtd><mtd><mi>d</mi></mtd></mtr></mtable>`,
+		},
+		{
+			name: "full-width digit",
+			tex:  `x = １`,
+			want: `<mi>x</mi><mo>=</mo><mn>１</mn>`,
+		},
+		{
+			name: "source annotation",
+			tex:  `a < b`,
+			want: `<annotation encoding="application/x-tex">a &lt; b</annotation>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TeXToMathML(tt.tex, tt.display)
+			if err != nil {
+				t.Fatalf("Expected nil but got '%v'", err)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("Expected '%s' in '%s'", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTeXToMathMLUnsupported(t *testing.T) {
+	for _, tex := range []string{`\unknown{x}`, `\frac{a}`, `{a`, `\begin{align} a \end{align}`} {
+		t.Run(tex, func(t *testing.T) {
+			_, err := TeXToMathML(tex, false)
+			assert.Equal(t, errors.Is(err, ErrUnsupportedTeX), true)
+		})
+	}
+}
+
+func TestMathRenderer(t *testing.T) {
+	r, err := New(Options{Engine: EngineCommonMark, Extensions: []string{ExtMath}})
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "inline",
+			src:  `The value $x_1 + x_2$ is used.`,
+			want: `<p>The value <math xmlns="http://www.w3.org/1998/Math/MathML"><semantics>`,
+		},
+		{
+			name: "display",
+			src:  "Text\n\n$$\n\\frac{1}{2}\n$$\n",
+			want: `<math xmlns="http://www.w3.org/1998/Math/MathML" display="block">`,
+		},
+		{
+			name: "amounts",
+			src:  `It costs $5 or $10.`,
+			want: `<p>It costs $5 or $10.</p>`,
+		},
+		{
+			name: "escaped dollar",
+			src:  `\$x$ stays`,
+			want: `<p>$x$ stays</p>`,
+		},
+		{
+			name: "code span",
+			src:  "Run `echo $HOME$` now",
+			want: `<code>echo $HOME$</code>`,
+		},
+		{
+			name: "code block",
+			src:  "```sh\necho $a_b$\n```\n",
+			want: `echo $a_b$`,
+		},
+		{
+			name: "unsupported",
+			src:  `See $\foo{x}$ here.`,
+			want: `<code class="language-tex">\foo{x}</code>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html, err := r.Render([]byte(tt.src))
+			if err != nil {
+				t.Fatalf("Expected nil but got '%v'", err)
+			}
+			if !strings.Contains(string(html), tt.want) {
+				t.Errorf("Expected '%s' in '%s'", tt.want, html)
+			}
+		})
+	}
+}
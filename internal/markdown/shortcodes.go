@@ -0,0 +1,513 @@
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+)
+
+// ErrUnknownShortcode is returned for shortcodes without a handler.
+var ErrUnknownShortcode = errors.New("unknown shortcode")
+
+// ErrUnknownShortcodeParam is returned for named parameters a shortcode does
+// not take.
+var ErrUnknownShortcodeParam = errors.New("unknown shortcode parameter")
+
+// Shortcode is a shortcode in the markdown, such as
+// {{< figure src="/static/a.png" caption="A figure" >}}. Paired shortcodes,
+// such as {{< note >}}...{{< /note >}}, have inner content, which is rendered
+// with the same renderer before the handler gets it.
+type Shortcode struct {
+	Name string
+	// Args are the values given without a name.
+	Args   []string
+	Params map[string]string
+	// Inner is the rendered HTML between the opening and the closing tag.
+	Inner string
+}
+
+// Param returns the named parameter, or def if it is not given.
+func (sc Shortcode) Param(name, def string) string {
+	if v, ok := sc.Params[name]; ok {
+		return v
+	}
+	return def
+}
+
+// ShortcodeFunc expands a shortcode to HTML. The HTML is sanitized with the
+// rest of the document.
+type ShortcodeFunc func(sc Shortcode) (string, error)
+
+// shortcodePlaceholder marks the position of a shortcode in the markdown while
+// it is rendered.
+const shortcodePlaceholder = "TEXTONLYSHORTCODE%dX"
+
+// shortcodeTag is an opening or closing tag in the markdown.
+type shortcodeTag struct {
+	start, end int
+	closing    bool
+	sc         Shortcode
+	err        error
+}
+
+// shortcodeCall is a shortcode with its inner markdown.
+type shortcodeCall struct {
+	sc    Shortcode
+	inner []byte
+	err   error
+}
+
+// registerBuiltinShortcodes adds the shortcodes every registry has.
+func (reg *Registry) registerBuiltinShortcodes() {
+	reg.RegisterShortcode("note", noteShortcode, "type", "title")
+	reg.RegisterShortcode("figure", figureShortcode, "src", "alt", "caption")
+}
+
+// RegisterShortcode adds a shortcode handler to the registry, replacing any
+// handler with the same name. Params are the names of the parameters the
+// shortcode takes; other parameters are reported as errors. Without params,
+// every parameter is given to the handler.
+func (reg *Registry) RegisterShortcode(name string, fn ShortcodeFunc, params ...string) {
+	reg.shortcodes[name] = fn
+	reg.params[name] = params
+	delete(reg.dynamic, name)
+}
+
+// RegisterDynamicShortcode adds a handler of a shortcode that shows other
+// content, such as a list of posts, as RegisterShortcode does. Documents
+// expanding it are marked as dynamic, so they can be rendered again when that
+// content changes.
+func (reg *Registry) RegisterDynamicShortcode(name string, fn ShortcodeFunc, params ...string) {
+	reg.RegisterShortcode(name, fn, params...)
+	reg.dynamic[name] = true
+}
+
+// checkParams returns an error for the first named parameter of the shortcode
+// that it does not take.
+func (reg *Registry) checkParams(sc Shortcode) error {
+	params := reg.params[sc.Name]
+	if len(params) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(sc.Params))
+	for name := range sc.Params {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		if !slices.Contains(params, name) {
+			return fmt.Errorf(
+				"%w %q of %s; it takes %s",
+				ErrUnknownShortcodeParam, name, sc.Name, strings.Join(params, ", "),
+			)
+		}
+	}
+
+	return nil
+}
+
+// hasDynamicShortcode reports whether the markdown has a dynamic shortcode.
+func (reg *Registry) hasDynamicShortcode(src []byte) bool {
+	for _, tag := range scanShortcodes(src) {
+		if tag.err == nil && !tag.closing && reg.dynamic[tag.sc.Name] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Shortcodes returns the sorted names of the registered shortcodes.
+func (reg *Registry) Shortcodes() []string {
+	names := make([]string, 0, len(reg.shortcodes))
+	for name := range reg.shortcodes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// CheckShortcodes returns an error for the first shortcode in the markdown
+// that has no handler, cannot be parsed or has a parameter it does not take,
+// if the named renderer expands shortcodes.
+func (reg *Registry) CheckShortcodes(name string, src []byte) error {
+	if name == "" {
+		name = reg.def
+	}
+	if r, ok := reg.renderers[name]; !ok || !r.shortcodes {
+		return nil
+	}
+
+	for _, tag := range scanShortcodes(src) {
+		if tag.err != nil {
+			return tag.err
+		}
+		if tag.closing {
+			continue
+		}
+		if _, ok := reg.shortcodes[tag.sc.Name]; !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownShortcode, tag.sc.Name)
+		}
+		if err := reg.checkParams(tag.sc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// expandShortcodes renders the markdown with the renderer, expanding the
+// shortcodes in it. The shortcodes are replaced by placeholders before the
+// markdown is rendered, and the output of their handlers is put in their
+// place afterwards. Shortcodes that cannot be expanded are replaced by an
+// error message.
+func (reg *Registry) expandShortcodes(r Renderer, src []byte) ([]byte, error) {
+	src, calls := extractShortcodes(src)
+
+	out, err := r.Render(src)
+	if err != nil || len(calls) == 0 {
+		return out, err
+	}
+
+	for i, call := range calls {
+		placeholder := []byte(fmt.Sprintf(shortcodePlaceholder, i))
+
+		expanded, err := reg.expand(r, call)
+		block := bytes.Contains(out, []byte("<p>"+string(placeholder)+"</p>"))
+		if err != nil {
+			expanded = shortcodeError(err, block)
+		}
+
+		// a shortcode on its own line is a paragraph of its own
+		if block {
+			paragraph := append(append([]byte("<p>"), placeholder...), "</p>"...)
+			out = bytes.Replace(out, paragraph, []byte(expanded), 1)
+		}
+		out = bytes.Replace(out, placeholder, []byte(expanded), 1)
+	}
+
+	return out, nil
+}
+
+// expand renders the inner markdown of the shortcode and calls its handler.
+func (reg *Registry) expand(r Renderer, call shortcodeCall) (string, error) {
+	if call.err != nil {
+		return "", call.err
+	}
+
+	fn, ok := reg.shortcodes[call.sc.Name]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownShortcode, call.sc.Name)
+	}
+	if err := reg.checkParams(call.sc); err != nil {
+		return "", err
+	}
+
+	sc := call.sc
+	if call.inner != nil {
+		inner, err := reg.expandShortcodes(r, call.inner)
+		if err != nil {
+			return "", err
+		}
+		sc.Inner = string(bytes.TrimSpace(inner))
+	}
+
+	out, err := fn(sc)
+	if err != nil {
+		return "", fmt.Errorf("shortcode %s: %w", sc.Name, err)
+	}
+
+	return out, nil
+}
+
+// shortcodeError shows why a shortcode could not be expanded.
+func shortcodeError(err error, block bool) string {
+	if block {
+		return `<p class="shortcode-error" role="note">` + html.EscapeString(err.Error()) + `</p>`
+	}
+	return `<code class="shortcode-error">` + html.EscapeString(err.Error()) + `</code>`
+}
+
+// extractShortcodes replaces the shortcodes in the markdown with placeholders.
+// An opening tag followed by a closing tag with the same name is a paired
+// shortcode, and everything between the tags is its inner markdown.
+func extractShortcodes(src []byte) ([]byte, []shortcodeCall) {
+	tags := scanShortcodes(src)
+	if len(tags) == 0 {
+		return src, nil
+	}
+
+	var (
+		out   bytes.Buffer
+		calls []shortcodeCall
+		pos   int
+	)
+	for i := 0; i < len(tags); i++ {
+		tag := tags[i]
+		out.Write(src[pos:tag.start])
+		pos = tag.end
+
+		call := shortcodeCall{sc: tag.sc, err: tag.err}
+		switch {
+		case tag.err != nil:
+		case tag.closing:
+			call.err = fmt.Errorf("closing shortcode %q without an opening one", tag.sc.Name)
+		default:
+			if j := closingTag(tags, i); j > 0 {
+				call.inner = bytes.Clone(src[tag.end:tags[j].start])
+				pos = tags[j].end
+				i = j
+			}
+		}
+
+		fmt.Fprintf(&out, shortcodePlaceholder, len(calls))
+		calls = append(calls, call)
+	}
+	out.Write(src[pos:])
+
+	return out.Bytes(), calls
+}
+
+// closingTag returns the index of the tag closing the one at open, taking
+// nested shortcodes with the same name into account, or -1.
+func closingTag(tags []shortcodeTag, open int) int {
+	depth := 0
+	for i := open + 1; i < len(tags); i++ {
+		if tags[i].err != nil || tags[i].sc.Name != tags[open].sc.Name {
+			continue
+		}
+		if !tags[i].closing {
+			depth++
+			continue
+		}
+		if depth == 0 {
+			return i
+		}
+		depth--
+	}
+
+	return -1
+}
+
+// scanShortcodes finds the shortcode tags in the markdown, skipping code
+// blocks and code spans, so shortcodes can be shown in code.
+func scanShortcodes(src []byte) []shortcodeTag {
+	var tags []shortcodeTag
+
+	s := string(src)
+	scanOutsideCode(s, func(i int) int {
+		if !strings.HasPrefix(s[i:], "{{<") {
+			return -1
+		}
+		end := strings.Index(s[i:], ">}}")
+		if end < 0 {
+			return -1
+		}
+
+		tag := parseShortcodeTag(s[i+3 : i+end])
+		tag.start, tag.end = i, i+end+3
+		tags = append(tags, tag)
+
+		return tag.end
+	})
+
+	return tags
+}
+
+// scanOutsideCode calls match at every position of the markdown outside code
+// blocks and code spans. Match returns the end of the construct found at the
+// position, or -1 if there is none.
+func scanOutsideCode(s string, match func(i int) int) {
+	var fence string
+
+	for pos := 0; pos < len(s); {
+		line, _, _ := strings.Cut(s[pos:], "\n")
+		lineEnd := min(pos+len(line)+1, len(s))
+
+		trimmed := strings.TrimLeft(line, " ")
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) &&
+				strings.TrimSpace(strings.TrimLeft(trimmed, fence[:1])) == "" {
+				fence = ""
+			}
+			pos = lineEnd
+			continue
+		}
+		if f := codeFence(trimmed); f != "" {
+			fence = f
+			pos = lineEnd
+			continue
+		}
+
+		i := pos
+		for i < lineEnd {
+			if s[i] == '`' {
+				n := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+				end := strings.Index(s[i+n:], s[i:i+n])
+				if end < 0 {
+					i += n
+				} else {
+					i += n + end + n
+				}
+				continue
+			}
+			if end := match(i); end > i {
+				i = end
+				continue
+			}
+			i++
+		}
+		// a code span or a construct may continue past the line
+		pos = max(lineEnd, i)
+	}
+}
+
+// parseShortcodeTag parses the content of a tag: a name, or a slash and a name
+// for closing tags, followed by arguments and key=value parameters. Values
+// with spaces are quoted.
+func parseShortcodeTag(content string) shortcodeTag {
+	var tag shortcodeTag
+
+	fields, err := splitShortcodeFields(strings.TrimSpace(content))
+	if err != nil {
+		tag.err = err
+		return tag
+	}
+	if len(fields) == 0 {
+		tag.err = errors.New("shortcode without a name")
+		return tag
+	}
+
+	name := fields[0]
+	if strings.HasPrefix(name, "/") {
+		tag.closing = true
+		name = strings.TrimSpace(name[1:])
+		if name == "" && len(fields) > 1 {
+			name, fields = fields[1], fields[1:]
+		}
+	}
+	tag.sc.Name = name
+
+	for _, field := range fields[1:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			tag.sc.Args = append(tag.sc.Args, unquote(field))
+			continue
+		}
+		if tag.sc.Params == nil {
+			tag.sc.Params = map[string]string{}
+		}
+		tag.sc.Params[key] = unquote(value)
+	}
+
+	return tag
+}
+
+// splitShortcodeFields splits the content of a tag at spaces outside quotes.
+func splitShortcodeFields(s string) ([]string, error) {
+	var (
+		fields []string
+		field  strings.Builder
+		quote  rune
+	)
+
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+			field.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			field.WriteRune(r)
+		case r == ' ' || r == '\t' || r == '\n':
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		default:
+			field.WriteRune(r)
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unclosed quote in shortcode %q", s)
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+
+	return fields, nil
+}
+
+// unquote removes the quotes around a value.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// noteKinds are the kinds of notes and their default titles.
+var noteKinds = map[string]string{
+	"note":    "Note",
+	"tip":     "Tip",
+	"info":    "Info",
+	"warning": "Warning",
+}
+
+// noteShortcode shows the inner content in a callout box. The kind is given
+// as an argument or with type, as in {{< note warning >}}, and the title
+// with title.
+func noteShortcode(sc Shortcode) (string, error) {
+	kind := "note"
+	if len(sc.Args) > 0 {
+		kind = sc.Args[0]
+	}
+	kind = sc.Param("type", kind)
+
+	title, ok := noteKinds[kind]
+	if !ok {
+		return "", fmt.Errorf("unknown note type %q", kind)
+	}
+	title = sc.Param("title", title)
+
+	return fmt.Sprintf(
+		`<aside class="note note-%s" role="note"><p class="note-title">%s</p>%s</aside>`,
+		kind, html.EscapeString(title), sc.Inner,
+	), nil
+}
+
+// figureShortcode shows an image with a caption. The inner content, if any,
+// is used as the caption when caption is not given.
+func figureShortcode(sc Shortcode) (string, error) {
+	src := sc.Param("src", "")
+	if src == "" {
+		return "", errors.New("src is required")
+	}
+
+	caption := html.EscapeString(sc.Param("caption", ""))
+	if caption == "" {
+		caption = sc.Inner
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(
+		&b,
+		`<figure><img src="%s" alt="%s">`,
+		html.EscapeString(src),
+		html.EscapeString(sc.Param("alt", "")),
+	)
+	if caption != "" {
+		fmt.Fprintf(&b, `<figcaption>%s</figcaption>`, caption)
+	}
+	b.WriteString(`</figure>`)
+
+	return b.String(), nil
+}
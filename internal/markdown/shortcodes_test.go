@@ -0,0 +1,126 @@
+package markdown
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"textonly.islandwind.me/internal/assert"
+)
+
+func TestParseShortcodeTag(t *testing.T) {
+	tag := parseShortcodeTag(` figure src="/static/a b.png" caption='A "figure"' wide `)
+	assert.Equal(t, tag.err, nil)
+	assert.Equal(t, tag.sc.Name, "figure")
+	assert.Equal(t, tag.sc.Param("src", ""), "/static/a b.png")
+	assert.Equal(t, tag.sc.Param("caption", ""), `A "figure"`)
+	assert.Equal(t, len(tag.sc.Args), 1)
+
+	tag = parseShortcodeTag(" /note ")
+	assert.Equal(t, tag.closing, true)
+	assert.Equal(t, tag.sc.Name, "note")
+
+	tag = parseShortcodeTag(`note title="unclosed`)
+	assert.Equal(t, tag.err != nil, true)
+}
+
+func TestRegistryShortcodes(t *testing.T) {
+	reg, err := NewRegistry(map[string]Options{
+		"shortcodes": {Engine: EngineCommonMark, Extensions: []string{ExtShortcodes}},
+	}, "shortcodes")
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+	reg.RegisterShortcode("greet", func(sc Shortcode) (string, error) {
+		return "<em>hello " + sc.Param("name", "world") + "</em>", nil
+	})
+
+	tests := []struct {
+		name     string
+		renderer string
+		src      string
+		want     string
+	}{
+		{
+			name: "paired",
+			src:  "{{< note warning >}}\nBe **careful**.\n{{< /note >}}",
+			want: `<aside class="note note-warning" role="note"><p class="note-title">Warning</p>` +
+				`<p>Be <strong>careful</strong>.</p></aside>`,
+		},
+		{
+			name: "nested",
+			src:  "{{< note >}}\nSay {{< greet name=you >}}.\n{{< /note >}}",
+			want: `<p>Say <em>hello you</em>.</p></aside>`,
+		},
+		{
+			name: "figure",
+			src:  `{{< figure src="/static/a.png" alt="A" caption="An image" >}}`,
+			want: `<figure><img src="/static/a.png" alt="A"><figcaption>An image</figcaption></figure>`,
+		},
+		{
+			name: "unknown",
+			src:  "Text {{< nope >}} here",
+			want: `<p>Text <code class="shortcode-error">unknown shortcode: &#34;nope&#34;</code> here</p>`,
+		},
+		{
+			name: "handler error",
+			src:  "{{< figure >}}",
+			want: `<p class="shortcode-error" role="note">shortcode figure: src is required</p>`,
+		},
+		{
+			name: "unknown parameter",
+			src:  `{{< figure src="/static/a.png" width="10" >}}`,
+			want: `<p class="shortcode-error" role="note">unknown shortcode parameter &#34;width&#34; of figure; it takes src, alt, caption</p>`,
+		},
+		{
+			name: "code span",
+			src:  "Use `{{< greet >}}` to greet",
+			want: `<code>{{&lt; greet &gt;}}</code>`,
+		},
+		{
+			name:     "legacy",
+			renderer: Legacy,
+			src:      "{{< greet >}}",
+			want:     `<p>{{&lt; greet &gt;}}</p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := reg.Render(tt.renderer, []byte(tt.src))
+			if err != nil {
+				t.Fatalf("Expected nil but got '%v'", err)
+			}
+			if !strings.Contains(string(doc.HTML), tt.want) {
+				t.Errorf("Expected '%s' in '%s'", tt.want, doc.HTML)
+			}
+		})
+	}
+
+	err = reg.CheckShortcodes("", []byte("{{< greet >}} {{< nope >}}"))
+	assert.Equal(t, errors.Is(err, ErrUnknownShortcode), true)
+	assert.Equal(t, reg.CheckShortcodes(Legacy, []byte("{{< nope >}}")), nil)
+	err = reg.CheckShortcodes("", []byte(`{{< note tag="go" >}}x{{< /note >}}`))
+	assert.Equal(t, errors.Is(err, ErrUnknownShortcodeParam), true)
+
+	// documents expanding a dynamic shortcode are marked
+	reg.RegisterDynamicShortcode("latest", func(sc Shortcode) (string, error) {
+		return "<ul></ul>", nil
+	})
+	for src, want := range map[string]bool{
+		"{{< latest >}}": true,
+		"{{< note >}}\n{{< latest >}}\n{{< /note >}}": true,
+		"{{< greet >}} `{{< latest >}}`":              false,
+	} {
+		doc, err := reg.Render("", []byte(src))
+		if err != nil {
+			t.Fatalf("Expected nil but got '%v'", err)
+		}
+		assert.Equal(t, doc.Dynamic, want)
+	}
+	doc, err := reg.Render(Legacy, []byte("{{< latest >}}"))
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+	assert.Equal(t, doc.Dynamic, false)
+}
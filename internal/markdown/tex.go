@@ -0,0 +1,671 @@
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrUnsupportedTeX is returned for TeX the MathML converter does not handle.
+var ErrUnsupportedTeX = errors.New("unsupported TeX")
+
+// TeXToMathML converts a practical subset of TeX math to MathML: fractions,
+// roots, sub- and superscripts, Greek letters and common symbols, big
+// operators such as sums and integrals, delimiters, text and matrices. The
+// source is kept as an annotation. Anything else returns ErrUnsupportedTeX.
+func TeXToMathML(tex string, display bool) (string, error) {
+	p := &texParser{toks: tokenizeTeX(tex), display: display}
+
+	row, err := p.parseExpr()
+	if err != nil {
+		return "", err
+	}
+	if !p.done() {
+		return "", fmt.Errorf("%w: unexpected %q", ErrUnsupportedTeX, p.peek().val)
+	}
+
+	var b strings.Builder
+	b.WriteString(`<math xmlns="http://www.w3.org/1998/Math/MathML"`)
+	if display {
+		b.WriteString(` display="block"`)
+	}
+	b.WriteString(`><semantics>`)
+	b.WriteString(mrow(row))
+	b.WriteString(`<annotation encoding="application/x-tex">`)
+	b.WriteString(html.EscapeString(strings.TrimSpace(tex)))
+	b.WriteString(`</annotation></semantics></math>`)
+
+	return b.String(), nil
+}
+
+type texTokenKind int
+
+const (
+	texEOF texTokenKind = iota
+	texCommand
+	texOpen
+	texClose
+	texSup
+	texSub
+	texAlign
+	texNumber
+	texLetter
+	texOther
+)
+
+type texToken struct {
+	kind texTokenKind
+	val  string
+}
+
+// tokenizeTeX splits TeX into commands, braces, scripts, numbers and single
+// characters. Whitespace becomes a single space token, which the parser skips
+// everywhere but in text arguments.
+func tokenizeTeX(tex string) []texToken {
+	var toks []texToken
+
+	for i := 0; i < len(tex); {
+		r, size := utf8.DecodeRuneInString(tex[i:])
+
+		switch {
+		case r == '\\':
+			j := i + 1
+			for j < len(tex) && isASCIILetter(tex[j]) {
+				j++
+			}
+			if j == i+1 && j < len(tex) {
+				_, size := utf8.DecodeRuneInString(tex[j:])
+				j += size
+			}
+			toks = append(toks, texToken{kind: texCommand, val: tex[i+1 : j]})
+			i = j
+			continue
+		case r == '{':
+			toks = append(toks, texToken{kind: texOpen, val: "{"})
+		case r == '}':
+			toks = append(toks, texToken{kind: texClose, val: "}"})
+		case r == '^':
+			toks = append(toks, texToken{kind: texSup, val: "^"})
+		case r == '_':
+			toks = append(toks, texToken{kind: texSub, val: "_"})
+		case r == '&':
+			toks = append(toks, texToken{kind: texAlign, val: "&"})
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(tex) && (isASCIIDigit(tex[j]) ||
+				tex[j] == '.' && j+1 < len(tex) && isASCIIDigit(tex[j+1])) {
+				j++
+			}
+			// digits of other scripts are numbers of their own
+			if j == i {
+				j += size
+			}
+			toks = append(toks, texToken{kind: texNumber, val: tex[i:j]})
+			i = j
+			continue
+		case unicode.IsLetter(r):
+			toks = append(toks, texToken{kind: texLetter, val: string(r)})
+		case unicode.IsSpace(r):
+			toks = append(toks, texToken{kind: texOther, val: " "})
+		default:
+			toks = append(toks, texToken{kind: texOther, val: string(r)})
+		}
+		i += size
+	}
+
+	return toks
+}
+
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isASCIIDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+var texGreek = map[string]string{
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ϵ",
+	"varepsilon": "ε", "zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "ϑ",
+	"iota": "ι", "kappa": "κ", "lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ",
+	"pi": "π", "varpi": "ϖ", "rho": "ρ", "varrho": "ϱ", "sigma": "σ",
+	"varsigma": "ς", "tau": "τ", "upsilon": "υ", "phi": "ϕ", "varphi": "φ",
+	"chi": "χ", "psi": "ψ", "omega": "ω",
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ", "Pi": "Π",
+	"Sigma": "Σ", "Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+}
+
+// texIdentifiers are symbols rendered as identifiers.
+var texIdentifiers = map[string]string{
+	"infty": "∞", "partial": "∂", "nabla": "∇", "emptyset": "∅", "varnothing": "∅",
+	"ell": "ℓ", "hbar": "ℏ", "aleph": "ℵ", "Re": "ℜ", "Im": "ℑ",
+}
+
+// texOperators are symbols rendered as operators.
+var texOperators = map[string]string{
+	"pm": "±", "mp": "∓", "times": "×", "div": "÷", "cdot": "⋅", "ast": "∗",
+	"star": "⋆", "circ": "∘", "bullet": "∙", "oplus": "⊕", "otimes": "⊗",
+	"leq": "≤", "le": "≤", "geq": "≥", "ge": "≥", "neq": "≠", "ne": "≠",
+	"ll": "≪", "gg": "≫", "approx": "≈", "equiv": "≡", "sim": "∼", "simeq": "≃",
+	"cong": "≅", "propto": "∝", "to": "→", "rightarrow": "→", "leftarrow": "←",
+	"Rightarrow": "⇒", "Leftarrow": "⇐", "Leftrightarrow": "⇔", "iff": "⇔",
+	"leftrightarrow": "↔", "implies": "⟹", "mapsto": "↦", "in": "∈",
+	"notin": "∉", "ni": "∋", "subset": "⊂", "supset": "⊃", "subseteq": "⊆",
+	"supseteq": "⊇", "cup": "∪", "cap": "∩", "setminus": "∖", "forall": "∀",
+	"exists": "∃", "neg": "¬", "lnot": "¬", "land": "∧", "wedge": "∧",
+	"lor": "∨", "vee": "∨", "ldots": "…", "dots": "…", "cdots": "⋯",
+	"vdots": "⋮", "ddots": "⋱", "mid": "∣", "parallel": "∥", "perp": "⊥",
+	"angle": "∠", "langle": "⟨", "rangle": "⟩", "lfloor": "⌊", "rfloor": "⌋",
+	"lceil": "⌈", "rceil": "⌉", "{": "{", "}": "}", "|": "‖", "%": "%",
+	"#": "#", "&": "&", "_": "_", "$": "$", "prime": "′",
+}
+
+// texLargeOperators are the big operators. Those marked true take their
+// limits above and below in display mode; the others, the integrals, always
+// take them as scripts.
+var texLargeOperators = map[string]struct {
+	symbol string
+	limits bool
+}{
+	"sum": {"∑", true}, "prod": {"∏", true}, "coprod": {"∐", true},
+	"bigcup": {"⋃", true}, "bigcap": {"⋂", true}, "bigoplus": {"⨁", true},
+	"bigotimes": {"⨂", true}, "bigvee": {"⋁", true}, "bigwedge": {"⋀", true},
+	"int": {"∫", false}, "iint": {"∬", false}, "iiint": {"∭", false},
+	"oint": {"∮", false},
+}
+
+// texFunctions are function names set upright. Those marked true take their
+// limits below in display mode.
+var texFunctions = map[string]bool{
+	"sin": false, "cos": false, "tan": false, "cot": false, "sec": false,
+	"csc": false, "arcsin": false, "arccos": false, "arctan": false,
+	"sinh": false, "cosh": false, "tanh": false, "log": false, "ln": false,
+	"lg": false, "exp": false, "det": true, "dim": false, "gcd": true,
+	"deg": false, "arg": false, "ker": false, "hom": false, "lim": true,
+	"liminf": true, "limsup": true, "max": true, "min": true, "sup": true,
+	"inf": true, "Pr": true,
+}
+
+var texSpaces = map[string]string{
+	",": "0.167em", ":": "0.222em", ">": "0.222em", ";": "0.278em",
+	" ": "0.333em", "quad": "1em", "qquad": "2em", "!": "-0.167em",
+}
+
+var texAccents = map[string]string{
+	"hat": "^", "widehat": "^", "bar": "¯", "overline": "¯", "vec": "→",
+	"dot": "˙", "ddot": "¨", "tilde": "~", "widetilde": "~",
+}
+
+var texFonts = map[string]string{
+	"mathbf": "bold", "mathit": "italic", "mathrm": "normal",
+	"mathbb": "double-struck", "mathcal": "script", "mathfrak": "fraktur",
+	"mathsf": "sans-serif", "mathtt": "monospace", "boldsymbol": "bold",
+}
+
+// texMatrices maps the matrix environments to their delimiters.
+var texMatrices = map[string][2]string{
+	"matrix": {"", ""}, "pmatrix": {"(", ")"}, "bmatrix": {"[", "]"},
+	"Bmatrix": {"{", "}"}, "vmatrix": {"|", "|"}, "Vmatrix": {"‖", "‖"},
+	"cases": {"{", ""},
+}
+
+type texParser struct {
+	toks    []texToken
+	pos     int
+	display bool
+}
+
+func (p *texParser) done() bool {
+	return p.pos >= len(p.toks)
+}
+
+func (p *texParser) peek() texToken {
+	if p.done() {
+		return texToken{}
+	}
+	return p.toks[p.pos]
+}
+
+func (p *texParser) next() texToken {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *texParser) skipSpace() {
+	for !p.done() && p.peek().kind == texOther && p.peek().val == " " {
+		p.pos++
+	}
+}
+
+// atEnd reports whether the current token ends an expression: a closing
+// brace, an alignment tab, a row break or the end of an environment or
+// delimited group.
+func (p *texParser) atEnd() bool {
+	p.skipSpace()
+	if p.done() {
+		return true
+	}
+
+	t := p.peek()
+	switch t.kind {
+	case texClose, texAlign:
+		return true
+	case texCommand:
+		return t.val == "\\" || t.val == "end" || t.val == "right"
+	}
+	return false
+}
+
+// parseExpr parses atoms up to the end of the expression and returns them as
+// a list of MathML elements.
+func (p *texParser) parseExpr() ([]string, error) {
+	var row []string
+	for !p.atEnd() {
+		atom, err := p.parseAtom()
+		if err != nil {
+			return nil, err
+		}
+		row = append(row, atom)
+	}
+	return row, nil
+}
+
+// parseAtom parses a base with its sub- and superscripts.
+func (p *texParser) parseAtom() (string, error) {
+	var (
+		base   string
+		limits bool
+		err    error
+	)
+
+	p.skipSpace()
+	if t := p.peek(); t.kind == texSub || t.kind == texSup {
+		base = "<mrow></mrow>"
+	} else {
+		base, limits, err = p.parseBase()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var (
+		sub string
+		sup []string
+	)
+	for {
+		p.skipSpace()
+		t := p.peek()
+		if t.kind == texCommand && (t.val == "limits" || t.val == "nolimits") {
+			p.next()
+			limits = t.val == "limits"
+			continue
+		}
+		if t.kind == texCommand && t.val == "prime" || t.kind == texOther && t.val == "'" {
+			p.next()
+			sup = append(sup, "<mo>′</mo>")
+			continue
+		}
+		if t.kind != texSub && t.kind != texSup {
+			break
+		}
+		p.next()
+
+		arg, err := p.parseArg()
+		if err != nil {
+			return "", err
+		}
+		if t.kind == texSub {
+			sub = arg
+		} else {
+			sup = append(sup, arg)
+		}
+	}
+
+	under, over := "msub", "msup"
+	both := "msubsup"
+	if limits && p.display {
+		under, over, both = "munder", "mover", "munderover"
+	}
+
+	switch {
+	case sub != "" && len(sup) > 0:
+		return fmt.Sprintf("<%s>%s%s%s</%s>", both, base, sub, mrow(sup), both), nil
+	case sub != "":
+		return fmt.Sprintf("<%s>%s%s</%s>", under, base, sub, under), nil
+	case len(sup) > 0:
+		return fmt.Sprintf("<%s>%s%s</%s>", over, base, mrow(sup), over), nil
+	default:
+		return base, nil
+	}
+}
+
+// parseArg parses a required argument: a group in braces or a single base.
+func (p *texParser) parseArg() (string, error) {
+	p.skipSpace()
+	if p.done() {
+		return "", fmt.Errorf("%w: missing argument", ErrUnsupportedTeX)
+	}
+	if p.peek().kind == texOpen {
+		return p.parseGroup()
+	}
+
+	base, _, err := p.parseBase()
+	return base, err
+}
+
+// parseGroup parses an expression in braces.
+func (p *texParser) parseGroup() (string, error) {
+	p.skipSpace()
+	if p.next().kind != texOpen {
+		return "", fmt.Errorf("%w: expected {", ErrUnsupportedTeX)
+	}
+
+	row, err := p.parseExpr()
+	if err != nil {
+		return "", err
+	}
+	if p.next().kind != texClose {
+		return "", fmt.Errorf("%w: unbalanced braces", ErrUnsupportedTeX)
+	}
+
+	return mrow(row), nil
+}
+
+// rawGroup returns the source of the tokens in braces, for arguments read as
+// text.
+func (p *texParser) rawGroup() (string, error) {
+	p.skipSpace()
+	if p.next().kind != texOpen {
+		return "", fmt.Errorf("%w: expected {", ErrUnsupportedTeX)
+	}
+
+	var b strings.Builder
+	for depth := 1; ; {
+		if p.done() {
+			return "", fmt.Errorf("%w: unbalanced braces", ErrUnsupportedTeX)
+		}
+
+		t := p.next()
+		switch t.kind {
+		case texOpen:
+			depth++
+		case texClose:
+			depth--
+			if depth == 0 {
+				return b.String(), nil
+			}
+		case texCommand:
+			b.WriteString("\\")
+		}
+		b.WriteString(t.val)
+	}
+}
+
+// parseBase parses a single base element. It reports whether the element
+// takes limits above and below in display mode.
+func (p *texParser) parseBase() (string, bool, error) {
+	p.skipSpace()
+	t := p.next()
+
+	switch t.kind {
+	case texOpen:
+		p.pos--
+		group, err := p.parseGroup()
+		return group, false, err
+	case texNumber:
+		return mathElement("mn", t.val), false, nil
+	case texLetter:
+		return mathElement("mi", t.val), false, nil
+	case texOther:
+		switch t.val {
+		case "-":
+			return mathElement("mo", "−"), false, nil
+		case "~":
+			return `<mspace width="0.333em"></mspace>`, false, nil
+		}
+		return mathElement("mo", t.val), false, nil
+	case texCommand:
+		return p.parseCommand(t.val)
+	}
+
+	return "", false, fmt.Errorf("%w: unexpected %q", ErrUnsupportedTeX, t.val)
+}
+
+func (p *texParser) parseCommand(name string) (string, bool, error) {
+	if s, ok := texGreek[name]; ok {
+		if unicode.IsUpper([]rune(name)[0]) {
+			return `<mi mathvariant="normal">` + s + `</mi>`, false, nil
+		}
+		return mathElement("mi", s), false, nil
+	}
+	if s, ok := texIdentifiers[name]; ok {
+		return mathElement("mi", s), false, nil
+	}
+	if s, ok := texOperators[name]; ok {
+		return mathElement("mo", s), false, nil
+	}
+	if op, ok := texLargeOperators[name]; ok {
+		return `<mo largeop="true">` + op.symbol + `</mo>`, op.limits, nil
+	}
+	if limits, ok := texFunctions[name]; ok {
+		return mathElement("mi", name), limits, nil
+	}
+	if width, ok := texSpaces[name]; ok {
+		return `<mspace width="` + width + `"></mspace>`, false, nil
+	}
+	if accent, ok := texAccents[name]; ok {
+		arg, err := p.parseArg()
+		if err != nil {
+			return "", false, err
+		}
+		return `<mover accent="true">` + arg + "<mo>" + accent + "</mo></mover>", false, nil
+	}
+	if variant, ok := texFonts[name]; ok {
+		text, err := p.rawGroup()
+		if err != nil {
+			return "", false, err
+		}
+		return `<mi mathvariant="` + variant + `">` + html.EscapeString(text) + `</mi>`, false, nil
+	}
+
+	switch name {
+	case "frac", "dfrac", "tfrac", "binom":
+		num, err := p.parseArg()
+		if err != nil {
+			return "", false, err
+		}
+		den, err := p.parseArg()
+		if err != nil {
+			return "", false, err
+		}
+		if name == "binom" {
+			return `<mrow><mo>(</mo><mfrac linethickness="0">` + num + den +
+				`</mfrac><mo>)</mo></mrow>`, false, nil
+		}
+		return "<mfrac>" + num + den + "</mfrac>", false, nil
+	case "sqrt":
+		var index string
+		p.skipSpace()
+		if t := p.peek(); t.kind == texOther && t.val == "[" {
+			p.next()
+			var row []string
+			for !p.done() && !(p.peek().kind == texOther && p.peek().val == "]") {
+				atom, err := p.parseAtom()
+				if err != nil {
+					return "", false, err
+				}
+				row = append(row, atom)
+			}
+			if p.done() {
+				return "", false, fmt.Errorf("%w: unclosed root index", ErrUnsupportedTeX)
+			}
+			p.next()
+			index = mrow(row)
+		}
+		arg, err := p.parseArg()
+		if err != nil {
+			return "", false, err
+		}
+		if index != "" {
+			return "<mroot>" + arg + index + "</mroot>", false, nil
+		}
+		return "<msqrt>" + arg + "</msqrt>", false, nil
+	case "text", "textrm", "textit", "textbf", "mbox":
+		text, err := p.rawGroup()
+		if err != nil {
+			return "", false, err
+		}
+		return mathElement("mtext", text), false, nil
+	case "operatorname":
+		text, err := p.rawGroup()
+		if err != nil {
+			return "", false, err
+		}
+		return mathElement("mi", text), false, nil
+	case "left":
+		return p.parseDelimited()
+	case "begin":
+		return p.parseEnvironment()
+	}
+
+	return "", false, fmt.Errorf("%w: \\%s", ErrUnsupportedTeX, name)
+}
+
+// parseDelimiter parses the delimiter after \left or \right. A period is an
+// empty delimiter.
+func (p *texParser) parseDelimiter() (string, error) {
+	p.skipSpace()
+	t := p.next()
+
+	switch {
+	case t.kind == texOther && t.val == ".":
+		return "", nil
+	case t.kind == texOther && strings.Contains("()[]|/", t.val):
+		return `<mo fence="true" stretchy="true">` + t.val + `</mo>`, nil
+	case t.kind == texCommand:
+		if s, ok := texOperators[t.val]; ok {
+			return `<mo fence="true" stretchy="true">` + html.EscapeString(s) + `</mo>`, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: delimiter %q", ErrUnsupportedTeX, t.val)
+}
+
+func (p *texParser) parseDelimited() (string, bool, error) {
+	open, err := p.parseDelimiter()
+	if err != nil {
+		return "", false, err
+	}
+
+	row, err := p.parseExpr()
+	if err != nil {
+		return "", false, err
+	}
+	if t := p.next(); t.kind != texCommand || t.val != "right" {
+		return "", false, fmt.Errorf("%w: \\left without \\right", ErrUnsupportedTeX)
+	}
+
+	closing, err := p.parseDelimiter()
+	if err != nil {
+		return "", false, err
+	}
+
+	return "<mrow>" + open + strings.Join(row, "") + closing + "</mrow>", false, nil
+}
+
+func (p *texParser) parseEnvironment() (string, bool, error) {
+	env, err := p.rawGroup()
+	if err != nil {
+		return "", false, err
+	}
+	delims, ok := texMatrices[env]
+	if !ok {
+		return "", false, fmt.Errorf("%w: environment %s", ErrUnsupportedTeX, env)
+	}
+
+	var (
+		rows  []string
+		cells []string
+	)
+	for {
+		row, err := p.parseExpr()
+		if err != nil {
+			return "", false, err
+		}
+		cells = append(cells, "<mtd>"+mrow(row)+"</mtd>")
+
+		t := p.next()
+		if t.kind == texAlign {
+			continue
+		}
+		if t.kind == texCommand && t.val == "\\" {
+			rows = append(rows, "<mtr>"+strings.Join(cells, "")+"</mtr>")
+			cells = nil
+
+			// a row break before \end does not start another row
+			p.skipSpace()
+			if u := p.peek(); u.kind != texCommand || u.val != "end" {
+				continue
+			}
+			t = p.next()
+		}
+		if t.kind != texCommand || t.val != "end" {
+			return "", false, fmt.Errorf("%w: unclosed environment %s", ErrUnsupportedTeX, env)
+		}
+
+		end, err := p.rawGroup()
+		if err != nil {
+			return "", false, err
+		}
+		if end != env {
+			return "", false, fmt.Errorf(
+				"%w: \\begin{%s} ended by \\end{%s}", ErrUnsupportedTeX, env, end,
+			)
+		}
+		break
+	}
+	if cells != nil {
+		rows = append(rows, "<mtr>"+strings.Join(cells, "")+"</mtr>")
+	}
+
+	var b strings.Builder
+	b.WriteString("<mtable")
+	if env == "cases" {
+		b.WriteString(` columnalign="left"`)
+	}
+	b.WriteString(">")
+	b.WriteString(strings.Join(rows, ""))
+	b.WriteString("</mtable>")
+
+	table := b.String()
+	if delims[0] == "" && delims[1] == "" {
+		return table, false, nil
+	}
+
+	var open, closing string
+	if delims[0] != "" {
+		open = `<mo fence="true" stretchy="true">` + html.EscapeString(delims[0]) + `</mo>`
+	}
+	if delims[1] != "" {
+		closing = `<mo fence="true" stretchy="true">` + html.EscapeString(delims[1]) + `</mo>`
+	}
+
+	return "<mrow>" + open + table + closing + "</mrow>", false, nil
+}
+
+// mathElement returns a token element with escaped content.
+func mathElement(name, content string) string {
+	return "<" + name + ">" + html.EscapeString(content) + "</" + name + ">"
+}
+
+// mrow groups the elements of a row, unless there is just one.
+func mrow(row []string) string {
+	if len(row) == 1 {
+		return row[0]
+	}
+	return "<mrow>" + strings.Join(row, "") + "</mrow>"
+}
@@ -0,0 +1,168 @@
+package markdown
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// inlineElements do not separate words, so no space is put in their place
+// when the text of the HTML is extracted.
+var inlineElements = map[atom.Atom]bool{
+	atom.A: true, atom.Abbr: true, atom.B: true, atom.Cite: true, atom.Code: true,
+	atom.Del: true, atom.Em: true, atom.I: true, atom.Ins: true, atom.Kbd: true,
+	atom.Mark: true, atom.Q: true, atom.S: true, atom.Small: true, atom.Span: true,
+	atom.Strong: true, atom.Sub: true, atom.Sup: true, atom.U: true,
+}
+
+// skippedElements have content that is not part of the text: drawings,
+// scripts and the TeX source of formulas.
+var skippedElements = map[atom.Atom]bool{
+	atom.Annotation: true, atom.Script: true, atom.Style: true, atom.Svg: true,
+}
+
+// PlainText returns the text of the HTML with the whitespace collapsed. The
+// links to headings, drawings and the TeX source of formulas are left out;
+// the source of diagrams is kept.
+func PlainText(src []byte) string {
+	var (
+		b    strings.Builder
+		skip []atom.Atom
+		z    = nethtml.NewTokenizer(bytes.NewReader(src))
+	)
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case nethtml.ErrorToken:
+			return strings.Join(strings.Fields(b.String()), " ")
+		case nethtml.TextToken:
+			if len(skip) == 0 {
+				b.Write(z.Text())
+			}
+		case nethtml.StartTagToken, nethtml.EndTagToken, nethtml.SelfClosingTagToken:
+			tok := z.Token()
+			if len(skip) > 0 {
+				// only the element that started the skip ends it
+				if tok.DataAtom == skip[len(skip)-1] {
+					switch tt {
+					case nethtml.StartTagToken:
+						skip = append(skip, tok.DataAtom)
+					case nethtml.EndTagToken:
+						skip = skip[:len(skip)-1]
+					}
+				}
+				continue
+			}
+			if tt == nethtml.StartTagToken && (skippedElements[tok.DataAtom] || isHeadingAnchor(tok)) {
+				skip = append(skip, tok.DataAtom)
+				continue
+			}
+			if !inlineElements[tok.DataAtom] {
+				b.WriteByte(' ')
+			}
+		}
+	}
+}
+
+// isHeadingAnchor reports whether the token starts a link added to a heading
+// by processHeadings.
+func isHeadingAnchor(tok nethtml.Token) bool {
+	return tok.DataAtom == atom.A && hasClass(tok, "heading-anchor")
+}
+
+// WordCount returns the number of words in the text.
+func WordCount(text string) int {
+	return len(strings.Fields(text))
+}
+
+// Excerpt shortens the text to at most n characters, cutting it at the end of
+// a word, and marks the cut with an ellipsis.
+func Excerpt(text string, n int) string {
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+
+	runes := []rune(text)
+	cut := strings.TrimRight(string(runes[:n]), " ")
+	if runes[n] != ' ' && runes[n-1] != ' ' {
+		if i := strings.LastIndexByte(cut, ' '); i > 0 {
+			cut = cut[:i]
+		}
+	}
+
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
+
+// wordsPerMinute is the reading speed reading times are estimated with.
+const wordsPerMinute = 200
+
+// ReadingTime estimates the time it takes to read a number of words, in whole
+// minutes. Any text takes at least a minute.
+func ReadingTime(words int) int {
+	if words == 0 {
+		return 0
+	}
+	return max(1, (words+wordsPerMinute/2)/wordsPerMinute)
+}
+
+// Stats counts elements of rendered HTML.
+type Stats struct {
+	CodeBlocks    int
+	OutboundLinks int
+	Images        int
+}
+
+// CountContent counts the code blocks, the links to other sites and the
+// images in the HTML. The source kept with diagrams is not a code block.
+func CountContent(src []byte) Stats {
+	var (
+		stats Stats
+		z     = nethtml.NewTokenizer(bytes.NewReader(src))
+	)
+
+	for {
+		tt := z.Next()
+		if tt == nethtml.ErrorToken {
+			return stats
+		}
+		if tt != nethtml.StartTagToken && tt != nethtml.SelfClosingTagToken {
+			continue
+		}
+
+		tok := z.Token()
+		switch tok.DataAtom {
+		case atom.Pre:
+			if !hasClass(tok, "diagram-source") {
+				stats.CodeBlocks++
+			}
+		case atom.A:
+			href := attr(tok, "href")
+			if strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://") ||
+				strings.HasPrefix(href, "//") {
+				stats.OutboundLinks++
+			}
+		case atom.Img:
+			stats.Images++
+		}
+	}
+}
+
+// attr returns the value of the named attribute of the token.
+func attr(tok nethtml.Token, key string) string {
+	for _, a := range tok.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// hasClass reports whether the token has the class.
+func hasClass(tok nethtml.Token, class string) bool {
+	return slices.Contains(strings.Fields(attr(tok, "class")), class)
+}
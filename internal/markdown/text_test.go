@@ -0,0 +1,98 @@
+package markdown
+
+import (
+	"testing"
+
+	"textonly.islandwind.me/internal/assert"
+)
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "blocks",
+			src:  "<h2 id=\"a\">Intro <a class=\"heading-anchor\" href=\"#a\">#</a></h2><p>Some <em>emph</em>asis.</p><ul><li>one</li><li>two</li></ul>",
+			want: "Intro Some emphasis. one two",
+		},
+		{
+			name: "entities",
+			src:  "<p>Fish &amp; chips</p>",
+			want: "Fish & chips",
+		},
+		{
+			name: "math",
+			src:  `<p>Let <math><semantics><mi>x</mi><annotation encoding="application/x-tex">x</annotation></semantics></math> be.</p>`,
+			want: "Let x be.",
+		},
+		{
+			name: "diagram",
+			src: `<figure class="diagram"><svg><text x="0" y="12">box</text><text x="0" y="28">db</text></svg>` +
+				`<details><pre class="diagram-source"><code>[box] --> [db]</code></pre></details></figure>`,
+			want: "[box] --> [db]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, PlainText([]byte(tt.src)), tt.want)
+		})
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		n    int
+		want string
+	}{
+		{name: "short", text: "A short text.", n: 20, want: "A short text."},
+		{name: "word boundary", text: "one two three", n: 7, want: "one two…"},
+		{name: "inside word", text: "one two three", n: 10, want: "one two…"},
+		{name: "punctuation", text: "one, two three", n: 5, want: "one…"},
+		{name: "runes", text: "æøå æøå æøå", n: 8, want: "æøå æøå…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, Excerpt(tt.text, tt.n), tt.want)
+		})
+	}
+}
+
+func TestWordCount(t *testing.T) {
+	assert.Equal(t, WordCount("  one two\nthree "), 3)
+}
+
+func TestReadingTime(t *testing.T) {
+	tests := []struct {
+		words int
+		want  int
+	}{
+		{words: 0, want: 0},
+		{words: 20, want: 1},
+		{words: 299, want: 1},
+		{words: 300, want: 2},
+		{words: 2000, want: 10},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, ReadingTime(tt.words), tt.want)
+	}
+}
+
+func TestCountContent(t *testing.T) {
+	src := `<p><a href="https://example.com">out</a> <a href="/post/read/2">in</a> ` +
+		`<a class="heading-anchor" href="#a">#</a> <img src="/a.png" alt="a"></p>` +
+		`<pre class="chroma"><code>x := 1</code></pre><pre><code>y</code></pre>` +
+		`<figure class="diagram"><svg><text x="0" y="12">a</text></svg>` +
+		`<details><pre class="diagram-source"><code>[a]</code></pre></details></figure>`
+
+	stats := CountContent([]byte(src))
+	assert.Equal(t, stats.CodeBlocks, 2)
+	assert.Equal(t, stats.OutboundLinks, 1)
+	assert.Equal(t, stats.Images, 1)
+}
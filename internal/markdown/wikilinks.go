@@ -0,0 +1,164 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PostLinkPrefix starts the target of a wiki link to a post by its slug, as
+// in [[post:my-first-post]]. A target that is a number links to the post with
+// that ID, as in [[12]].
+const PostLinkPrefix = "post:"
+
+// LinkResolver returns the URL and the title of the target of a wiki link.
+// It reports false if the target does not exist.
+type LinkResolver func(target string) (url, title string, ok bool)
+
+// wikiLinkPlaceholder marks the position of a wiki link in the markdown while
+// it is rendered.
+const wikiLinkPlaceholder = "TEXTONLYLINK%dX"
+
+var wikiLinkSlugRX = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// wikiLink is a wiki link in the markdown, such as [[post:slug|some text]].
+type wikiLink struct {
+	target string
+	text   string
+}
+
+// SetLinkResolver sets the function that resolves the targets of wiki links.
+// Without one, every wiki link is shown as broken.
+func (reg *Registry) SetLinkResolver(fn LinkResolver) {
+	reg.resolve = fn
+}
+
+// WikiLinks returns the targets of the wiki links in the markdown, without
+// duplicates, in the order they first appear.
+func WikiLinks(src []byte) []string {
+	var targets []string
+	for _, link := range scanWikiLinks(string(src)) {
+		if !slices.Contains(targets, link.target) {
+			targets = append(targets, link.target)
+		}
+	}
+
+	return targets
+}
+
+// parseWikiLink parses the content between the brackets of a wiki link: a
+// target, optionally followed by a bar and the text of the link. It reports
+// false if the target is neither a post slug nor a post ID.
+func parseWikiLink(content string) (wikiLink, bool) {
+	target, text, _ := strings.Cut(content, "|")
+	link := wikiLink{target: strings.TrimSpace(target), text: strings.TrimSpace(text)}
+
+	if slug, ok := strings.CutPrefix(link.target, PostLinkPrefix); ok {
+		return link, wikiLinkSlugRX.MatchString(slug)
+	}
+	id, err := strconv.Atoi(link.target)
+
+	return link, err == nil && id > 0 && strconv.Itoa(id) == link.target
+}
+
+// wikiLinkMatch is a wiki link and its position in the markdown.
+type wikiLinkMatch struct {
+	wikiLink
+	start, end int
+}
+
+// scanWikiLinks finds the wiki links in the markdown, skipping code blocks and
+// code spans.
+func scanWikiLinks(s string) []wikiLinkMatch {
+	var links []wikiLinkMatch
+
+	scanOutsideCode(s, func(i int) int {
+		if !strings.HasPrefix(s[i:], "[[") {
+			return -1
+		}
+		end := strings.Index(s[i+2:], "]]")
+		if end < 0 || strings.ContainsAny(s[i+2:i+2+end], "\n[") {
+			return -1
+		}
+
+		link, ok := parseWikiLink(s[i+2 : i+2+end])
+		if !ok {
+			return -1
+		}
+		links = append(links, wikiLinkMatch{wikiLink: link, start: i, end: i + 2 + end + 2})
+
+		return i + 2 + end + 2
+	})
+
+	return links
+}
+
+// extractWikiLinks replaces the wiki links in the markdown with placeholders.
+func extractWikiLinks(src []byte) ([]byte, []wikiLink) {
+	matches := scanWikiLinks(string(src))
+	if len(matches) == 0 {
+		return src, nil
+	}
+
+	var (
+		out   bytes.Buffer
+		links []wikiLink
+		pos   int
+	)
+	for _, m := range matches {
+		out.Write(src[pos:m.start])
+		fmt.Fprintf(&out, wikiLinkPlaceholder, len(links))
+		links = append(links, m.wikiLink)
+		pos = m.end
+	}
+	out.Write(src[pos:])
+
+	return out.Bytes(), links
+}
+
+// replaceWikiLinks puts the resolved links in the place of their placeholders.
+// Links that cannot be resolved are shown as text marked as broken.
+func replaceWikiLinks(out []byte, links []wikiLink, resolve LinkResolver) []byte {
+	for i, link := range links {
+		placeholder := []byte(fmt.Sprintf(wikiLinkPlaceholder, i))
+
+		var (
+			url, title string
+			ok         bool
+		)
+		if resolve != nil {
+			url, title, ok = resolve(link.target)
+		}
+
+		text := link.text
+		if text == "" {
+			text = title
+		}
+		if text == "" {
+			text = link.target
+		}
+
+		var a string
+		if ok {
+			a = fmt.Sprintf(
+				`<a class="wikilink" href="%s">%s</a>`,
+				html.EscapeString(url), html.EscapeString(text),
+			)
+		} else {
+			a = fmt.Sprintf(
+				`<span class="wikilink-broken" title="Broken link to %s">%s</span>`,
+				html.EscapeString(link.target), html.EscapeString(text),
+			)
+		}
+		out = bytes.ReplaceAll(out, placeholder, []byte(a))
+
+		// heading IDs generated by the engine contain the placeholder
+		out = bytes.ReplaceAll(out, bytes.ToLower(placeholder), []byte(slugify(text)))
+	}
+
+	return out
+}
@@ -0,0 +1,91 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"textonly.islandwind.me/internal/assert"
+)
+
+func TestWikiLinks(t *testing.T) {
+	src := "See [[post:first-post]], [[12|this one]] and [[post:first-post]].\n\n" +
+		"Not links: [[Some Page]], [[post:Bad Slug]], [[012]], `[[13]]`.\n\n" +
+		"```\n[[14]]\n```\n"
+
+	targets := WikiLinks([]byte(src))
+	assert.Equal(t, strings.Join(targets, ","), "post:first-post,12")
+}
+
+func TestRegistryWikiLinks(t *testing.T) {
+	reg, err := NewRegistry(map[string]Options{
+		"wiki": {
+			Engine:     EngineCommonMark,
+			Extensions: []string{ExtHeadingAnchors, ExtShortcodes, ExtWikiLinks},
+		},
+	}, "wiki")
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+	reg.SetLinkResolver(func(target string) (string, string, bool) {
+		if target == "post:first-post" {
+			return "/post/read/1", "First <post>", true
+		}
+		return "", "", false
+	})
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "resolved",
+			src:  "Read [[post:first-post]] first.",
+			want: `<p>Read <a class="wikilink" href="/post/read/1">First &lt;post&gt;</a> first.</p>`,
+		},
+		{
+			name: "text",
+			src:  "Read [[post:first-post|the first post]].",
+			want: `<a class="wikilink" href="/post/read/1">the first post</a>`,
+		},
+		{
+			name: "broken",
+			src:  "Read [[7]].",
+			want: `<span class="wikilink-broken" title="Broken link to 7">7</span>`,
+		},
+		{
+			name: "in shortcode",
+			src:  "{{< note >}}\nSee [[post:first-post]].\n{{< /note >}}",
+			want: `<p>See <a class="wikilink" href="/post/read/1">First &lt;post&gt;</a>.</p></aside>`,
+		},
+		{
+			name: "in heading",
+			src:  "## About [[post:first-post|intro]]",
+			want: `<h2 id="about-intro">About <a class="wikilink" href="/post/read/1">intro</a>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := reg.Render("", []byte(tt.src))
+			if err != nil {
+				t.Fatalf("Expected nil but got '%v'", err)
+			}
+			if !strings.Contains(string(doc.HTML), tt.want) {
+				t.Errorf("Expected '%s' in '%s'", tt.want, doc.HTML)
+			}
+		})
+	}
+
+	// a resolver given with the document replaces the one of the registry
+	doc, err := reg.RenderWithResolver("", []byte("Read [[post:first-post]]."), func(string) (string, string, bool) {
+		return "", "", false
+	})
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+	want := `<span class="wikilink-broken" title="Broken link to post:first-post">post:first-post</span>`
+	if !strings.Contains(string(doc.HTML), want) {
+		t.Errorf("Expected '%s' in '%s'", want, doc.HTML)
+	}
+}
@@ -0,0 +1,128 @@
+// Package rss writes RSS 2.0 feeds, with the full content of items in
+// content:encoded, the URL of the feed itself in atom:link and the statistics
+// of posts in the stats namespace of Textonly.
+package rss
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// The namespaces of the extensions the feeds use.
+const (
+	ContentNS = "http://purl.org/rss/1.0/modules/content/"
+	AtomNS    = "http://www.w3.org/2005/Atom"
+	DCNS      = "http://purl.org/dc/elements/1.1/"
+	StatsNS   = "https://textonly.islandwind.me/ns/stats"
+)
+
+// ContentType is the media type feeds are served with.
+const ContentType = "application/rss+xml; charset=utf-8"
+
+// Feed is the rss element of a feed. New fills in the version and the
+// namespaces.
+type Feed struct {
+	XMLName   xml.Name `xml:"rss"`
+	Version   string   `xml:"version,attr"`
+	ContentNS string   `xml:"xmlns:content,attr"`
+	AtomNS    string   `xml:"xmlns:atom,attr"`
+	DCNS      string   `xml:"xmlns:dc,attr"`
+	StatsNS   string   `xml:"xmlns:stats,attr"`
+	Channel   Channel  `xml:"channel"`
+}
+
+type Channel struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Language    string `xml:"language,omitempty"`
+	// LastBuildDate is when the content of the channel last changed.
+	LastBuildDate Date     `xml:"lastBuildDate,omitempty"`
+	Generator     string   `xml:"generator,omitempty"`
+	Self          AtomLink `xml:"atom:link"`
+	Items         []Item   `xml:"item"`
+}
+
+// AtomLink points to the feed itself, as RSS 2.0 has no element of its own
+// for it.
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type Item struct {
+	// Title is optional, as notes have none.
+	Title       string `xml:"title,omitempty"`
+	Link        string `xml:"link"`
+	Comments    string `xml:"comments,omitempty"`
+	Description string `xml:"description,omitempty"`
+	// Content is the full HTML of the item.
+	Content *CDATA `xml:"content:encoded,omitempty"`
+	// Creator names the author. The author element of RSS 2.0 needs an
+	// email address, which is not known.
+	Creator    string   `xml:"dc:creator,omitempty"`
+	Categories []string `xml:"category"`
+	GUID       GUID     `xml:"guid"`
+	PubDate    Date     `xml:"pubDate,omitempty"`
+	*Stats
+}
+
+// CDATA is text written in a CDATA section, which keeps HTML readable.
+type CDATA struct {
+	Text string `xml:",cdata"`
+}
+
+// GUID identifies an item for good. Permalinks are URLs readers can open.
+type GUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// Stats are the statistics of a post, in the stats namespace.
+type Stats struct {
+	WordCount     int `xml:"stats:wordCount"`
+	ReadingTime   int `xml:"stats:readingTime"`
+	CodeBlocks    int `xml:"stats:codeBlocks"`
+	OutboundLinks int `xml:"stats:outboundLinks"`
+	Images        int `xml:"stats:images"`
+}
+
+// Date is a time written in the format of RFC 822 that RSS uses. The zero
+// time is left out.
+type Date time.Time
+
+func (d Date) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	t := time.Time(d)
+	if t.IsZero() {
+		return nil
+	}
+	return e.EncodeElement(t.UTC().Format(time.RFC1123Z), start)
+}
+
+// New returns a feed of the channel.
+func New(channel Channel) *Feed {
+	return &Feed{
+		Version:   "2.0",
+		ContentNS: ContentNS,
+		AtomNS:    AtomNS,
+		DCNS:      DCNS,
+		StatsNS:   StatsNS,
+		Channel:   channel,
+	}
+}
+
+// Self returns the atom:link of a feed at the URL.
+func Self(href string) AtomLink {
+	return AtomLink{Href: href, Rel: "self", Type: "application/rss+xml"}
+}
+
+// Marshal returns the feed as an XML document.
+func (f *Feed) Marshal() ([]byte, error) {
+	b, err := xml.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), append(b, '\n')...), nil
+}
@@ -0,0 +1,81 @@
+package rss
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"textonly.islandwind.me/internal/assert"
+)
+
+func TestMarshal(t *testing.T) {
+	published := time.Date(2024, 3, 17, 10, 15, 0, 0, time.UTC)
+
+	feed := New(Channel{
+		Title:         "Textonly",
+		Link:          "https://example.com/",
+		Description:   "A blog",
+		Language:      "en",
+		LastBuildDate: Date(published),
+		Self:          Self("https://example.com/feed.rss"),
+		Items: []Item{
+			{
+				Title:      "Hello & welcome",
+				Link:       "https://example.com/post/read/1",
+				Content:    &CDATA{Text: "<p>The text of the post.</p>"},
+				Creator:    "Jane",
+				Categories: []string{"article"},
+				GUID:       GUID{Value: "https://example.com/post/read/1", IsPermaLink: true},
+				PubDate:    Date(published),
+				Stats:      &Stats{WordCount: 5, ReadingTime: 1},
+			},
+			{
+				Link: "https://example.com/post/read/2",
+				GUID: GUID{Value: "https://example.com/post/read/2", IsPermaLink: true},
+			},
+		},
+	})
+
+	b, err := feed.Marshal()
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+	out := string(b)
+
+	for _, want := range []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		`<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/"`,
+		`<atom:link href="https://example.com/feed.rss" rel="self" type="application/rss+xml"></atom:link>`,
+		`<lastBuildDate>Sun, 17 Mar 2024 10:15:00 +0000</lastBuildDate>`,
+		`<title>Hello &amp; welcome</title>`,
+		`<content:encoded><![CDATA[<p>The text of the post.</p>]]></content:encoded>`,
+		`<dc:creator>Jane</dc:creator>`,
+		`<guid isPermaLink="true">https://example.com/post/read/1</guid>`,
+		`<pubDate>Sun, 17 Mar 2024 10:15:00 +0000</pubDate>`,
+		`<stats:wordCount>5</stats:wordCount>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected '%s' in '%s'", want, out)
+		}
+	}
+
+	// the item without a title, content or date leaves them out
+	second := out[strings.LastIndex(out, "<item>"):]
+	for _, unwanted := range []string{"<title>", "<content:encoded>", "<pubDate>", "<stats:"} {
+		if strings.Contains(second, unwanted) {
+			t.Errorf("Expected no '%s' in '%s'", unwanted, second)
+		}
+	}
+
+	// the document is well-formed
+	var parsed struct {
+		Items []struct {
+			Link string `xml:"link"`
+		} `xml:"channel>item"`
+	}
+	if err = xml.Unmarshal(b, &parsed); err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+	assert.Equal(t, len(parsed.Items), 2)
+}
@@ -0,0 +1,187 @@
+// Package sanitize cleans the HTML rendered from markdown using allowlist
+// based policies, chosen by the type of content being rendered.
+package sanitize
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// Content types, each of which is sanitized with its own policy.
+const (
+	// ContentPost is a blog post written by an author.
+	ContentPost = "post"
+	// ContentPage is a static page or other site content written by an author.
+	ContentPage = "page"
+	// ContentProfile is the profile of the user shown on the about page.
+	ContentProfile = "profile"
+	// ContentUntrusted is content from anyone else, such as comments or
+	// imported content.
+	ContentUntrusted = "untrusted"
+)
+
+// Policy names.
+const (
+	// PolicyAuthor allows the markup produced by the markdown renderer and its
+	// extensions, including classes and IDs.
+	PolicyAuthor = "author"
+	// PolicyUGC allows basic formatting, links and images, but no classes or
+	// styling.
+	PolicyUGC = "ugc"
+	// PolicyStrict removes all markup, leaving only text.
+	PolicyStrict = "strict"
+)
+
+// DefaultPolicies maps the content types to the policies used unless
+// configured otherwise.
+var DefaultPolicies = map[string]string{
+	ContentPost:      PolicyAuthor,
+	ContentPage:      PolicyAuthor,
+	ContentProfile:   PolicyAuthor,
+	ContentUntrusted: PolicyUGC,
+}
+
+// Sanitizer holds a policy for each content type.
+type Sanitizer struct {
+	policies map[string]*bluemonday.Policy
+}
+
+// New creates a sanitizer from a map of content types to policy names. Content
+// types missing from the map use the default policies.
+func New(policies map[string]string) (*Sanitizer, error) {
+	s := &Sanitizer{policies: map[string]*bluemonday.Policy{}}
+
+	names := map[string]string{}
+	for contentType, name := range DefaultPolicies {
+		names[contentType] = name
+	}
+	for contentType, name := range policies {
+		names[contentType] = name
+	}
+
+	for contentType, name := range names {
+		p, err := NewPolicy(name)
+		if err != nil {
+			return nil, fmt.Errorf("content type %s: %w", contentType, err)
+		}
+		s.policies[contentType] = p
+	}
+
+	return s, nil
+}
+
+// Sanitize cleans the HTML using the policy of the content type. Unknown
+// content types are treated as untrusted.
+func (s *Sanitizer) Sanitize(contentType string, html []byte) []byte {
+	p, ok := s.policies[contentType]
+	if !ok {
+		p = s.policies[ContentUntrusted]
+	}
+
+	return p.SanitizeBytes(html)
+}
+
+// NewPolicy returns the named policy.
+func NewPolicy(name string) (*bluemonday.Policy, error) {
+	switch name {
+	case PolicyAuthor:
+		return authorPolicy(), nil
+	case PolicyUGC:
+		return ugcPolicy(), nil
+	case PolicyStrict:
+		return bluemonday.StrictPolicy(), nil
+	default:
+		return nil, fmt.Errorf("unknown sanitizer policy %q", name)
+	}
+}
+
+var (
+	classRX    = regexp.MustCompile(`^[a-zA-Z0-9_\- ]+$`)
+	languageRX = regexp.MustCompile(`^language-[a-zA-Z0-9_+\-#.]+$`)
+)
+
+// ugcPolicy allows the formatting of regular markdown. Links to other sites
+// are marked with rel="nofollow noopener" and opened in a new tab.
+func ugcPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.RequireNoFollowOnLinks(false)
+	p.RequireNoFollowOnFullyQualifiedLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	p.AllowAttrs("class").Matching(languageRX).OnElements("code")
+
+	return p
+}
+
+// authorPolicy extends the UGC policy with the markup used by the rendering
+// extensions, such as heading IDs, footnotes and task lists.
+func authorPolicy() *bluemonday.Policy {
+	p := ugcPolicy()
+	p.AllowAttrs("id").Matching(bluemonday.SpaceSeparatedTokens).Globally()
+	p.AllowAttrs("class").Matching(classRX).Globally()
+	p.AllowAttrs("title").Globally()
+	p.AllowElements("figure", "figcaption", "aside", "details", "summary", "mark", "section")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^(|checked|disabled)$`)).
+		OnElements("input")
+	p.AllowAttrs("open").Matching(regexp.MustCompile(`^(|open)$`)).OnElements("details")
+	p.AllowAttrs("role").Matching(regexp.MustCompile(`^(doc-[a-z]+|note)$`)).Globally()
+	allowMathML(p)
+	allowDiagrams(p)
+
+	return p
+}
+
+// allowMathML allows the MathML the TeX converter produces.
+func allowMathML(p *bluemonday.Policy) {
+	p.AllowNoAttrs().OnElements(
+		"math", "semantics", "annotation", "mrow", "mi", "mn", "mo", "mtext", "mspace",
+		"mfrac", "msqrt", "mroot", "msub", "msup", "msubsup", "munder", "mover",
+		"munderover", "mtable", "mtr", "mtd",
+	)
+	p.AllowAttrs("xmlns").
+		Matching(regexp.MustCompile(`^http://www\.w3\.org/1998/Math/MathML$`)).
+		OnElements("math")
+	p.AllowAttrs("display").Matching(regexp.MustCompile(`^(block|inline)$`)).OnElements("math")
+	p.AllowAttrs("encoding").
+		Matching(regexp.MustCompile(`^application/x-tex$`)).
+		OnElements("annotation")
+	p.AllowAttrs("mathvariant").Matching(regexp.MustCompile(`^[a-z\-]+$`)).OnElements("mi")
+	p.AllowAttrs("fence", "stretchy", "largeop", "accent").
+		Matching(regexp.MustCompile(`^(true|false)$`)).
+		OnElements("mo", "mover")
+	p.AllowAttrs("width").Matching(regexp.MustCompile(`^-?[0-9.]+em$`)).OnElements("mspace")
+	p.AllowAttrs("linethickness").Matching(regexp.MustCompile(`^0$`)).OnElements("mfrac")
+	p.AllowAttrs("columnalign").
+		Matching(regexp.MustCompile(`^(left|center|right)$`)).
+		OnElements("mtable")
+}
+
+// allowDiagrams allows the SVG the diagram converter produces. Only the
+// presentation attributes it sets are kept, with the values it gives them,
+// so that diagrams are drawn without the stylesheet; style attributes are
+// not kept.
+func allowDiagrams(p *bluemonday.Policy) {
+	number := regexp.MustCompile(`^-?[0-9]+$`)
+
+	p.AllowElements("svg", "line", "circle", "text", "polygon", "path")
+	p.AllowAttrs("xmlns").Matching(regexp.MustCompile(`^http://www\.w3\.org/2000/svg$`)).
+		OnElements("svg")
+	p.AllowAttrs("viewbox").Matching(regexp.MustCompile(`^0 0 [0-9]+ [0-9]+$`)).OnElements("svg")
+	p.AllowAttrs("width", "height").Matching(number).OnElements("svg")
+	p.AllowAttrs("role").Matching(regexp.MustCompile(`^img$`)).OnElements("svg")
+	p.AllowAttrs("aria-label").Matching(regexp.MustCompile(`^[\p{L}\p{N} ]+$`)).OnElements("svg")
+	p.AllowAttrs("fill").Matching(regexp.MustCompile(`^(none|currentColor)$`)).
+		OnElements("svg", "polygon", "circle", "text")
+	p.AllowAttrs("stroke").Matching(regexp.MustCompile(`^(none|currentColor)$`)).
+		OnElements("svg", "text")
+	p.AllowAttrs("stroke-width").Matching(regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)).OnElements("svg")
+	p.AllowAttrs("font-family").Matching(regexp.MustCompile(`^monospace$`)).OnElements("text")
+	p.AllowAttrs("font-size").Matching(number).OnElements("text")
+	p.AllowAttrs("x1", "y1", "x2", "y2").Matching(number).OnElements("line")
+	p.AllowAttrs("cx", "cy", "r").Matching(number).OnElements("circle")
+	p.AllowAttrs("x", "y").Matching(number).OnElements("text")
+	p.AllowAttrs("points").Matching(regexp.MustCompile(`^[0-9, -]+$`)).OnElements("polygon")
+	p.AllowAttrs("d").Matching(regexp.MustCompile(`^[MQL0-9 -]+$`)).OnElements("path")
+}
@@ -0,0 +1,96 @@
+package sanitize
+
+import (
+	"testing"
+
+	"textonly.islandwind.me/internal/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	s, err := New(nil)
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		html        string
+		want        string
+	}{
+		{
+			name:        "script removed",
+			contentType: ContentPost,
+			html:        `<p>hello</p><script>alert(1)</script>`,
+			want:        `<p>hello</p>`,
+		},
+		{
+			name:        "javascript link removed",
+			contentType: ContentPost,
+			html:        `<a href="javascript:alert(1)">click</a>`,
+			want:        `click`,
+		},
+		{
+			name:        "external link",
+			contentType: ContentUntrusted,
+			html:        `<a href="https://example.com">example</a>`,
+			want: `<a href="https://example.com" rel="nofollow noopener" ` +
+				`target="_blank">example</a>`,
+		},
+		{
+			name:        "internal link",
+			contentType: ContentUntrusted,
+			html:        `<a href="/post/read/1">post</a>`,
+			want:        `<a href="/post/read/1">post</a>`,
+		},
+		{
+			name:        "author keeps heading IDs",
+			contentType: ContentPost,
+			html:        `<h2 id="intro">Intro</h2>`,
+			want:        `<h2 id="intro">Intro</h2>`,
+		},
+		{
+			name:        "author keeps MathML",
+			contentType: ContentPost,
+			html:        `<math display="block"><mfrac><mi>a</mi><mn>2</mn></mfrac></math>`,
+			want:        `<math display="block"><mfrac><mi>a</mi><mn>2</mn></mfrac></math>`,
+		},
+		{
+			name:        "author keeps diagrams",
+			contentType: ContentPost,
+			html: `<svg viewBox="0 0 16 16" stroke="currentColor" onload="alert(1)"><line x1="0" y1="8" ` +
+				`x2="16" y2="8" style="stroke: red"></line><text x="0" y="12" fill="url(#a)" ` +
+				`font-size="13">a</text></svg>`,
+			want: `<svg viewbox="0 0 16 16" stroke="currentColor"><line x1="0" y1="8" x2="16" y2="8">` +
+				`</line><text x="0" y="12" font-size="13">a</text></svg>`,
+		},
+		{
+			name:        "author keeps diagram sources",
+			contentType: ContentPost,
+			html: `<figure class="diagram"><svg></svg><details><pre class="diagram-source">` +
+				`<code class="language-diagram">+--+</code></pre></details></figure>`,
+			want: `<figure class="diagram"><svg></svg><details><pre class="diagram-source">` +
+				`<code class="language-diagram">+--+</code></pre></details></figure>`,
+		},
+		{
+			name:        "unknown content type is untrusted",
+			contentType: "comment",
+			html:        `<p class="lead">hi</p>`,
+			want:        `<p>hi</p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.Sanitize(tt.contentType, []byte(tt.html))
+			assert.Equal(t, string(got), tt.want)
+		})
+	}
+}
+
+func TestNewUnknownPolicy(t *testing.T) {
+	_, err := New(map[string]string{ContentPost: "lenient"})
+	if err == nil {
+		t.Error("Expected error for unknown policy")
+	}
+}
@@ -0,0 +1,83 @@
+// Package share signs and verifies the tokens of share links, which grant
+// access to a post until they expire. A token holds the ID of the link, the
+// ID of the post and the expiry time, signed with HMAC-SHA256, as in
+// 12.34.1735689600.<signature>.
+package share
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalid is returned for tokens that are malformed or not signed
+	// with the secret.
+	ErrInvalid = errors.New("invalid share token")
+	// ErrExpired is returned for tokens that are signed with the secret but
+	// have expired.
+	ErrExpired = errors.New("expired share token")
+)
+
+// Claims are the signed contents of a token.
+type Claims struct {
+	LinkID  int
+	PostID  int
+	Expires time.Time
+}
+
+// Sign returns the token for the claims, signed with the secret. The same
+// claims always give the same token.
+func Sign(secret []byte, c Claims) string {
+	payload := fmt.Sprintf("%d.%d.%d", c.LinkID, c.PostID, c.Expires.Unix())
+	return payload + "." + base64.RawURLEncoding.EncodeToString(signature(secret, payload))
+}
+
+// Verify checks the signature and expiry of the token, and returns its
+// claims.
+func Verify(secret []byte, token string, now time.Time) (Claims, error) {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return Claims{}, ErrInvalid
+	}
+	payload, sig := token[:i], token[i+1:]
+
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, signature(secret, payload)) {
+		return Claims{}, ErrInvalid
+	}
+
+	parts := strings.Split(payload, ".")
+	if len(parts) != 3 {
+		return Claims{}, ErrInvalid
+	}
+	var numbers [3]int64
+	for i, part := range parts {
+		numbers[i], err = strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return Claims{}, ErrInvalid
+		}
+	}
+
+	c := Claims{
+		LinkID:  int(numbers[0]),
+		PostID:  int(numbers[1]),
+		Expires: time.Unix(numbers[2], 0).UTC(),
+	}
+	if !now.Before(c.Expires) {
+		return c, ErrExpired
+	}
+
+	return c, nil
+}
+
+func signature(secret []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,57 @@
+package share
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"textonly.islandwind.me/internal/assert"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Date(2024, 3, 17, 10, 15, 0, 0, time.UTC)
+	claims := Claims{LinkID: 12, PostID: 34, Expires: now.Add(48 * time.Hour)}
+
+	token := Sign(secret, claims)
+	assert.Equal(t, strings.HasPrefix(token, "12.34."), true)
+	assert.Equal(t, Sign(secret, claims), token)
+
+	got, err := Verify(secret, token, now)
+	if err != nil {
+		t.Fatalf("Expected nil but got '%v'", err)
+	}
+	assert.Equal(t, got.LinkID, 12)
+	assert.Equal(t, got.PostID, 34)
+	assert.Equal(t, got.Expires.Equal(claims.Expires), true)
+}
+
+func TestVerifyErrors(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Date(2024, 3, 17, 10, 15, 0, 0, time.UTC)
+	claims := Claims{LinkID: 1, PostID: 2, Expires: now.Add(time.Hour)}
+	token := Sign(secret, claims)
+
+	tests := []struct {
+		name  string
+		token string
+		now   time.Time
+		want  error
+	}{
+		{"Expired", token, now.Add(time.Hour), ErrExpired},
+		{"Other secret", Sign([]byte("other"), claims), now, ErrInvalid},
+		{"Other post", strings.Replace(token, "1.2.", "1.3.", 1), now, ErrInvalid},
+		{"Empty", "", now, ErrInvalid},
+		{"Malformed", "1.2.x.abc", now, ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Verify(secret, tt.token, tt.now)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected '%v' but got '%v'", tt.want, err)
+			}
+		})
+	}
+}
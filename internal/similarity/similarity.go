@@ -0,0 +1,150 @@
+// Package similarity finds similar documents by the cosine similarity of the
+// TF-IDF weighted words of their text.
+package similarity
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// stopWords are too common to tell documents apart.
+var stopWords = map[string]bool{
+	"about": true, "after": true, "also": true, "and": true, "are": true, "because": true,
+	"been": true, "but": true, "can": true, "could": true, "did": true, "does": true,
+	"for": true, "from": true, "had": true, "has": true, "have": true, "her": true,
+	"his": true, "how": true, "into": true, "its": true, "just": true, "more": true,
+	"not": true, "now": true, "one": true, "only": true, "other": true, "our": true,
+	"out": true, "over": true, "she": true, "should": true, "some": true, "than": true,
+	"that": true, "the": true, "their": true, "them": true, "then": true, "there": true,
+	"these": true, "they": true, "this": true, "those": true, "use": true, "very": true,
+	"was": true, "were": true, "what": true, "when": true, "which": true, "while": true,
+	"who": true, "will": true, "with": true, "would": true, "you": true, "your": true,
+}
+
+// Field is a part of a document, such as its title, whose words count Weight
+// times.
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+// Document is a text to compare, made up of fields.
+type Document struct {
+	ID     int
+	Fields []Field
+}
+
+// Match is a document similar to another one. Score is between 0 and 1.
+type Match struct {
+	ID    int
+	Score float64
+}
+
+// vector maps the words of a document to their weights.
+type vector map[string]float64
+
+// Index holds the TF-IDF vectors of a set of documents.
+type Index struct {
+	vectors map[int]vector
+	ids     []int
+}
+
+// New creates an index of the documents.
+func New(docs []Document) *Index {
+	idx := &Index{vectors: make(map[int]vector, len(docs))}
+
+	// term frequencies, weighted by field
+	df := map[string]int{}
+	for _, doc := range docs {
+		tf := vector{}
+		for _, f := range doc.Fields {
+			for _, word := range Words(f.Text) {
+				tf[word] += f.Weight
+			}
+		}
+		for word := range tf {
+			df[word]++
+		}
+		idx.vectors[doc.ID] = tf
+		idx.ids = append(idx.ids, doc.ID)
+	}
+
+	// Frequent words get a sublinear weight, and words found in fewer
+	// documents weigh more. The vectors are normalized so the dot product of
+	// two of them is their cosine similarity.
+	n := float64(len(docs))
+	for _, v := range idx.vectors {
+		var norm float64
+		for word, tf := range v {
+			idf := math.Log((1+n)/(1+float64(df[word]))) + 1
+			w := math.Log1p(tf) * idf
+			v[word] = w
+			norm += w * w
+		}
+		norm = math.Sqrt(norm)
+		for word := range v {
+			v[word] /= norm
+		}
+	}
+
+	return idx
+}
+
+// Similar returns at most n documents that are most similar to the document
+// with the ID, most similar first. Documents without any word in common are
+// left out.
+func (idx *Index) Similar(id, n int) []Match {
+	v, ok := idx.vectors[id]
+	if !ok {
+		return nil
+	}
+
+	var matches []Match
+	for _, other := range idx.ids {
+		if other == id {
+			continue
+		}
+		if score := v.dot(idx.vectors[other]); score > 0 {
+			matches = append(matches, Match{ID: other, Score: score})
+		}
+	}
+	slices.SortFunc(matches, func(a, b Match) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return matches[:min(n, len(matches))]
+}
+
+func (v vector) dot(other vector) float64 {
+	if len(other) < len(v) {
+		v, other = other, v
+	}
+
+	var sum float64
+	for word, w := range v {
+		sum += w * other[word]
+	}
+	return sum
+}
+
+// Words splits the text into lowercase words, leaving out stop words and
+// words shorter than three letters.
+func Words(text string) []string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(word)) < 3 || stopWords[word] {
+			continue
+		}
+		words = append(words, word)
+	}
+
+	return words
+}
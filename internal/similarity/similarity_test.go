@@ -0,0 +1,49 @@
+package similarity
+
+import (
+	"strings"
+	"testing"
+
+	"textonly.islandwind.me/internal/assert"
+)
+
+func TestWords(t *testing.T) {
+	got := Words("The Go compiler, and its GC: a tour of go1.22!")
+	assert.Equal(t, strings.Join(got, " "), "compiler tour go1")
+}
+
+func TestSimilar(t *testing.T) {
+	idx := New([]Document{
+		{ID: 1, Fields: []Field{
+			{Text: "Profiling Go services", Weight: 3},
+			{Text: "Using pprof to find slow handlers in Go services.", Weight: 1},
+		}},
+		{ID: 2, Fields: []Field{
+			{Text: "Tracing Go services", Weight: 3},
+			{Text: "Finding slow handlers with traces.", Weight: 1},
+		}},
+		{ID: 3, Fields: []Field{
+			{Text: "Baking sourdough bread", Weight: 3},
+			{Text: "Flour, water and salt.", Weight: 1},
+		}},
+		{ID: 4, Fields: []Field{
+			{Text: "Go services in production", Weight: 3},
+		}},
+	})
+
+	matches := idx.Similar(1, 5)
+	ids := make([]int, 0, len(matches))
+	for _, m := range matches {
+		ids = append(ids, m.ID)
+		if m.Score <= 0 || m.Score > 1.000001 {
+			t.Errorf("Expected score between 0 and 1 but got %f", m.Score)
+		}
+	}
+	assert.Equal(t, len(ids), 2)
+	assert.Equal(t, ids[0], 2)
+	assert.Equal(t, ids[1], 4)
+
+	assert.Equal(t, len(idx.Similar(3, 5)), 0)
+	assert.Equal(t, len(idx.Similar(1, 1)), 1)
+	assert.Equal(t, len(idx.Similar(42, 5)), 0)
+}
@@ -1,5 +1,11 @@
 package validator
 
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
 type Validator struct {
 	Errors map[string]string
 }
@@ -40,3 +46,15 @@ func PermittedValues(values []string, permitted []string) (string, bool) {
 
 	return "", true
 }
+
+func NotBlank(value string) bool {
+	return strings.TrimSpace(value) != ""
+}
+
+func MaxChars(value string, n int) bool {
+	return utf8.RuneCountInString(value) <= n
+}
+
+func Matches(value string, rx *regexp.Regexp) bool {
+	return rx.MatchString(value)
+}
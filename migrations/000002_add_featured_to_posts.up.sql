@@ -0,0 +1 @@
+ALTER TABLE public.posts ADD COLUMN featured boolean NOT NULL DEFAULT false;
@@ -0,0 +1,13 @@
+CREATE TABLE public.pages (
+	id bigserial NOT NULL,
+	slug varchar(100) NOT NULL,
+	title varchar(255) NOT NULL,
+	"content" text NOT NULL,
+	nav_position int NOT NULL DEFAULT 0,
+	show_in_nav boolean NOT NULL DEFAULT false,
+	visible boolean NOT NULL DEFAULT true,
+	last_update timestamp NOT NULL DEFAULT NOW(),
+	created timestamp NOT NULL DEFAULT NOW(),
+	CONSTRAINT pages_pkey PRIMARY KEY (id),
+	CONSTRAINT pages_slug_key UNIQUE (slug)
+);
@@ -0,0 +1 @@
+DROP TABLE public.site_settings;
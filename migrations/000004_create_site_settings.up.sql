@@ -0,0 +1,22 @@
+CREATE TABLE public.site_settings (
+	id int NOT NULL DEFAULT 1,
+	title varchar(255) NOT NULL,
+	tagline varchar(255) NOT NULL DEFAULT '',
+	base_url varchar(1000) NOT NULL,
+	default_author varchar(100) NOT NULL DEFAULT '',
+	"language" varchar(35) NOT NULL DEFAULT 'en',
+	footer_text text NOT NULL DEFAULT '',
+	last_update timestamp NOT NULL DEFAULT NOW(),
+	CONSTRAINT site_settings_pkey PRIMARY KEY (id),
+	CONSTRAINT site_settings_single_row CHECK (id = 1)
+);
+
+INSERT INTO public.site_settings
+(title, tagline, base_url, default_author, "language")
+VALUES(
+	'Islandwind',
+	'Personal Blog of Øyvind Kristiansen',
+	'https://www.islandwind.me',
+	'Øyvind Kristiansen',
+	'en'
+);
@@ -0,0 +1 @@
+ALTER TABLE public.posts DROP COLUMN renderer;
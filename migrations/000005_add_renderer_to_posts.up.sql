@@ -0,0 +1 @@
+ALTER TABLE public.posts ADD COLUMN renderer text NOT NULL DEFAULT 'legacy';
@@ -0,0 +1,2 @@
+DROP TABLE public.post_links;
+ALTER TABLE public.posts DROP COLUMN slug;
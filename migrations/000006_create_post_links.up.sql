@@ -0,0 +1,20 @@
+ALTER TABLE public.posts ADD COLUMN slug varchar(100);
+
+UPDATE public.posts
+SET slug = COALESCE(
+	NULLIF(left(trim(BOTH '-' FROM lower(regexp_replace(title, '[^a-zA-Z0-9]+', '-', 'g'))), 90), ''),
+	'post'
+);
+UPDATE public.posts p
+SET slug = p.slug || '-' || p.id
+WHERE EXISTS (SELECT 1 FROM public.posts q WHERE q.slug = p.slug AND q.id < p.id);
+
+ALTER TABLE public.posts ALTER COLUMN slug SET NOT NULL;
+ALTER TABLE public.posts ADD CONSTRAINT posts_slug_key UNIQUE (slug);
+
+CREATE TABLE public.post_links (
+	source_id int8 NOT NULL,
+	target varchar(255) NOT NULL,
+	CONSTRAINT post_links_pkey PRIMARY KEY (source_id, target),
+	CONSTRAINT fk_posts FOREIGN KEY (source_id) REFERENCES public.posts(id) ON DELETE CASCADE
+);
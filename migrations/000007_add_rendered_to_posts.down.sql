@@ -0,0 +1,4 @@
+ALTER TABLE public.posts DROP COLUMN outline;
+ALTER TABLE public.posts DROP COLUMN word_count;
+ALTER TABLE public.posts DROP COLUMN excerpt;
+ALTER TABLE public.posts DROP COLUMN html;
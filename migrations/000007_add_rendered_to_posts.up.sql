@@ -0,0 +1,4 @@
+ALTER TABLE public.posts ADD COLUMN html text NOT NULL DEFAULT '';
+ALTER TABLE public.posts ADD COLUMN excerpt text NOT NULL DEFAULT '';
+ALTER TABLE public.posts ADD COLUMN word_count int4 NOT NULL DEFAULT 0;
+ALTER TABLE public.posts ADD COLUMN outline jsonb NOT NULL DEFAULT '[]';
@@ -0,0 +1,4 @@
+ALTER TABLE public.posts DROP COLUMN images;
+ALTER TABLE public.posts DROP COLUMN outbound_links;
+ALTER TABLE public.posts DROP COLUMN code_blocks;
+ALTER TABLE public.posts DROP COLUMN reading_time;
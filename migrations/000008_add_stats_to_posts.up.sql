@@ -0,0 +1,4 @@
+ALTER TABLE public.posts ADD COLUMN reading_time int4 NOT NULL DEFAULT 0;
+ALTER TABLE public.posts ADD COLUMN code_blocks int4 NOT NULL DEFAULT 0;
+ALTER TABLE public.posts ADD COLUMN outbound_links int4 NOT NULL DEFAULT 0;
+ALTER TABLE public.posts ADD COLUMN images int4 NOT NULL DEFAULT 0;
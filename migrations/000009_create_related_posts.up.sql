@@ -0,0 +1,8 @@
+CREATE TABLE public.related_posts (
+	post_id int8 NOT NULL,
+	related_id int8 NOT NULL,
+	score float8 NOT NULL,
+	CONSTRAINT related_posts_pkey PRIMARY KEY (post_id, related_id),
+	CONSTRAINT fk_posts FOREIGN KEY (post_id) REFERENCES public.posts(id) ON DELETE CASCADE,
+	CONSTRAINT fk_related_posts FOREIGN KEY (related_id) REFERENCES public.posts(id) ON DELETE CASCADE
+);
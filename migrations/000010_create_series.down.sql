@@ -0,0 +1,2 @@
+DROP TABLE public.series_posts;
+DROP TABLE public.series;
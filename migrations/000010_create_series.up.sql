@@ -0,0 +1,20 @@
+CREATE TABLE public.series (
+	id bigserial NOT NULL,
+	slug varchar(100) NOT NULL,
+	title varchar(255) NOT NULL,
+	description text NOT NULL DEFAULT '',
+	last_update timestamp NOT NULL DEFAULT NOW(),
+	created timestamp NOT NULL DEFAULT NOW(),
+	CONSTRAINT series_pkey PRIMARY KEY (id),
+	CONSTRAINT series_slug_key UNIQUE (slug)
+);
+
+CREATE TABLE public.series_posts (
+	series_id int8 NOT NULL,
+	post_id int8 NOT NULL,
+	position int NOT NULL,
+	CONSTRAINT series_posts_pkey PRIMARY KEY (series_id, post_id),
+	CONSTRAINT series_posts_post_key UNIQUE (post_id),
+	CONSTRAINT fk_series FOREIGN KEY (series_id) REFERENCES public.series(id) ON DELETE CASCADE,
+	CONSTRAINT fk_posts FOREIGN KEY (post_id) REFERENCES public.posts(id) ON DELETE CASCADE
+);
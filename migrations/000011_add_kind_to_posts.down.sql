@@ -0,0 +1,4 @@
+ALTER TABLE public.posts DROP COLUMN quote;
+ALTER TABLE public.posts DROP COLUMN link_url;
+ALTER TABLE public.posts DROP CONSTRAINT posts_kind_check;
+ALTER TABLE public.posts DROP COLUMN kind;
@@ -0,0 +1,4 @@
+ALTER TABLE public.posts ADD COLUMN kind varchar(10) NOT NULL DEFAULT 'article';
+ALTER TABLE public.posts ADD CONSTRAINT posts_kind_check CHECK (kind IN ('article', 'note', 'link'));
+ALTER TABLE public.posts ADD COLUMN link_url varchar(2000) NOT NULL DEFAULT '';
+ALTER TABLE public.posts ADD COLUMN quote text NOT NULL DEFAULT '';
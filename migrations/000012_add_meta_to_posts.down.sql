@@ -0,0 +1,2 @@
+DROP INDEX public.posts_meta_idx;
+ALTER TABLE public.posts DROP COLUMN meta;
@@ -0,0 +1,2 @@
+ALTER TABLE public.posts ADD COLUMN meta jsonb NOT NULL DEFAULT '{}';
+CREATE INDEX posts_meta_idx ON public.posts USING GIN (meta jsonb_path_ops);
@@ -0,0 +1,4 @@
+DROP INDEX public.posts_translation_of_idx;
+ALTER TABLE public.posts DROP CONSTRAINT fk_posts_translation_of;
+ALTER TABLE public.posts DROP COLUMN translation_of;
+ALTER TABLE public.posts DROP COLUMN language;
@@ -0,0 +1,5 @@
+ALTER TABLE public.posts ADD COLUMN language varchar(35) NOT NULL DEFAULT '';
+UPDATE public.posts SET language = COALESCE((SELECT language FROM public.site_settings WHERE id = 1), '');
+ALTER TABLE public.posts ADD COLUMN translation_of int8 NULL;
+ALTER TABLE public.posts ADD CONSTRAINT fk_posts_translation_of FOREIGN KEY (translation_of) REFERENCES public.posts(id) ON DELETE SET NULL;
+CREATE INDEX posts_translation_of_idx ON public.posts (translation_of);
@@ -0,0 +1,3 @@
+DROP TABLE public.share_links;
+ALTER TABLE public.posts DROP CONSTRAINT posts_visibility_check;
+ALTER TABLE public.posts DROP COLUMN visibility;
@@ -0,0 +1,13 @@
+ALTER TABLE public.posts ADD COLUMN visibility varchar(10) NOT NULL DEFAULT 'public';
+ALTER TABLE public.posts ADD CONSTRAINT posts_visibility_check CHECK (visibility IN ('public', 'unlisted', 'private'));
+
+CREATE TABLE public.share_links (
+	id bigserial NOT NULL,
+	post_id int8 NOT NULL,
+	expires timestamp NOT NULL,
+	revoked timestamp NULL,
+	created timestamp NOT NULL DEFAULT NOW(),
+	CONSTRAINT share_links_pkey PRIMARY KEY (id),
+	CONSTRAINT fk_posts FOREIGN KEY (post_id) REFERENCES public.posts(id) ON DELETE CASCADE
+);
+CREATE INDEX share_links_post_id_idx ON public.share_links (post_id);
@@ -0,0 +1,3 @@
+DROP TABLE public.reviews;
+ALTER TABLE public.posts DROP CONSTRAINT posts_status_check;
+ALTER TABLE public.posts DROP COLUMN status;
@@ -0,0 +1,15 @@
+ALTER TABLE public.posts ADD COLUMN status varchar(12) NOT NULL DEFAULT 'published';
+ALTER TABLE public.posts ADD CONSTRAINT posts_status_check CHECK (status IN ('draft', 'in_review', 'approved', 'published'));
+
+CREATE TABLE public.reviews (
+	id bigserial NOT NULL,
+	post_id int8 NOT NULL,
+	"action" varchar(10) NOT NULL,
+	reviewer varchar(100) NOT NULL,
+	"comment" text NOT NULL DEFAULT '',
+	created timestamp NOT NULL DEFAULT NOW(),
+	CONSTRAINT reviews_pkey PRIMARY KEY (id),
+	CONSTRAINT reviews_action_check CHECK ("action" IN ('request', 'approve', 'reject', 'publish')),
+	CONSTRAINT fk_posts FOREIGN KEY (post_id) REFERENCES public.posts(id) ON DELETE CASCADE
+);
+CREATE INDEX reviews_post_id_idx ON public.reviews (post_id);
@@ -0,0 +1 @@
+ALTER TABLE public.posts ADD COLUMN dynamic boolean NOT NULL DEFAULT false;
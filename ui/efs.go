@@ -4,5 +4,5 @@ import (
 	"embed"
 )
 
-//go:embed "html" "xml" "static"
+//go:embed "html" "static" "i18n"
 var Files embed.FS
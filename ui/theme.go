@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Theme returns a file system where the files in the theme directory at path
+// take precedence over the embedded files. Only the html, static and i18n
+// directories of the theme are used, and missing files fall back to the
+// embedded ones.
+func Theme(path string) fs.FS {
+	return &overlayFS{theme: os.DirFS(path), base: Files}
+}
+
+type overlayFS struct {
+	theme fs.FS
+	base  fs.FS
+}
+
+func (o *overlayFS) Open(name string) (fs.File, error) {
+	if isThemeable(name) {
+		f, err := o.theme.Open(name)
+		if err == nil {
+			stat, err := f.Stat()
+			if err == nil && !stat.IsDir() {
+				return f, nil
+			}
+			f.Close()
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	return o.base.Open(name)
+}
+
+// ReadDir merges the directory entries of the theme and the embedded files,
+// which makes fs.Glob pick up templates that only exist in the theme.
+func (o *overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, baseErr := fs.ReadDir(o.base, name)
+	if !isThemeable(name) {
+		return entries, baseErr
+	}
+
+	themeEntries, err := fs.ReadDir(o.theme, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return entries, baseErr
+		}
+		return nil, err
+	}
+	if baseErr != nil && !errors.Is(baseErr, fs.ErrNotExist) {
+		return nil, baseErr
+	}
+
+	for _, entry := range themeEntries {
+		i := slices.IndexFunc(entries, func(e fs.DirEntry) bool { return e.Name() == entry.Name() })
+		if i < 0 {
+			entries = append(entries, entry)
+		}
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+
+	return entries, nil
+}
+
+func isThemeable(name string) bool {
+	for _, dir := range []string{"html", "static", "i18n"} {
+		if name == dir || strings.HasPrefix(name, dir+"/") {
+			return true
+		}
+	}
+	return false
+}